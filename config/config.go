@@ -1,8 +1,32 @@
 package config
 
+import "time"
+
 var (
 	DebugMode = false
 
 	OrderBookMaxSupportedDepth        = 100
 	OrderBookOutOfSequeceErrThreshold = 10
+
+	// Alternative codes of the assets mapped to the canonical codes, e.g. xbt_usdt is served as btc_usdt.
+	AssetAliases = map[string]string{
+		"xbt":   "btc",
+		"bchsv": "bsv",
+		"xdg":   "doge",
+	}
+	// Native codes of the assets which differ from the canonical codes, keyed by the provider name.
+	NativeAssets = map[string]map[string]string{
+		"kucoin":         {"bsv": "bchsv"},
+		"kucoin-futures": {"btc": "xbt"},
+	}
+
+	// Interval of the refresh of the cached market lists of the providers.
+	SymbolsRefreshInterval = time.Hour
+
+	BinanceStreamsPerConnection = 200
+	BinanceHotMarkets           = []string{}
+	// The spot combined-stream endpoints in the order of preference. The built-in list is used if empty.
+	BinanceStreamEndpoints = []string{}
+	// Time to wait for the binance connection if the subscription is made while it is down.
+	BinanceSubscribeTimeout = 10 * time.Second
 )
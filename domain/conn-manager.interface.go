@@ -1,6 +1,14 @@
 package domain
 
+import "errors"
+
+var ErrUnknownProvider = errors.New("unknown provider")
+
 type ConnManager interface {
-	StreamAPI(provider string) ProviderStreamAPI
-	SyncAPI(provider string) ProviderSyncAPI
+	StreamAPI(provider string) (ProviderStreamAPI, error)
+	SyncAPI(provider string) (ProviderSyncAPI, error)
+	FuturesAPI(provider string) (ProviderFuturesAPI, error)
+	// ValidateSymbol returns ErrUnknownMarket if the market is not listed by the provider.
+	ValidateSymbol(provider string, symbol *MarketSymbol) error
+	SymbolMapper(provider string) (*SymbolMapper, error)
 }
@@ -0,0 +1,26 @@
+package domain
+
+// ContinuityDepthUpdateValidator validates the updates of the feeds without the sequence ids.
+// The stream API numbers the updates locally and marks the first update after the continuity of the stream is lost,
+// e.g. the connection is dropped or the heartbeats are missed.
+type ContinuityDepthUpdateValidator struct{}
+
+func (v *ContinuityDepthUpdateValidator) IsValidUpd(update *OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.Interrupted {
+		return ErrOrderBookStreamInterrupted
+	}
+
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return ErrOrderBookUpdateIsOutdated
+	}
+
+	return nil
+}
+
+func (v *ContinuityDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *ContinuityDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == ErrOrderBookUpdateIsOutdated
+}
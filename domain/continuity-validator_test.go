@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContinuityDepthUpdateValidator_IsValidUpd(t *testing.T) {
+	v := &ContinuityDepthUpdateValidator{}
+
+	err := v.IsValidUpd(&OrderBookUpdate{SequenceStart: 11, SequenceEnd: 11}, 10)
+	assert.Nil(t, err, "Error should be nil")
+
+	err = v.IsValidUpd(&OrderBookUpdate{SequenceStart: 10, SequenceEnd: 10}, 10)
+	assert.Equal(t, ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	err = v.IsValidUpd(&OrderBookUpdate{SequenceStart: 11, SequenceEnd: 11, Interrupted: true}, 10)
+	assert.Equal(t, ErrOrderBookStreamInterrupted, err, "Error should match")
+}
@@ -5,6 +5,8 @@ import "errors"
 var (
 	ErrOrderBookUpdateIsOutOfSequece = errors.New("order book update is out of sequece")
 	ErrOrderBookUpdateIsOutdated     = errors.New("order book update is outdated")
+	ErrOrderBookChecksumMismatch     = errors.New("order book checksum mismatch")
+	ErrOrderBookStreamInterrupted    = errors.New("order book stream is interrupted")
 )
 
 type DepthUpdateValidator struct {
@@ -17,3 +19,16 @@ type IDepthUpdateValidator interface {
 	IsErrOutOfSequece(err error) bool
 	IsErrOutdated(err error) bool
 }
+
+// IOrderBookStateValidator is implemented by the validators of the providers that send the checksum of the order book.
+type IOrderBookStateValidator interface {
+	// if return nil, the order book state after the applied update is valid
+	IsValidState(update *OrderBookUpdate, orderBook *OrderBook) error
+}
+
+// IFirstDepthUpdateValidator is implemented by the validators of the providers whose first update after the snapshot
+// is validated by a different rule than the chain of the following updates.
+type IFirstDepthUpdateValidator interface {
+	// if return nil, the first update after the snapshot is valid
+	IsValidFirstUpd(update *OrderBookUpdate, orderBookLastUpdId int64) error
+}
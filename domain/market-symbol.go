@@ -3,6 +3,8 @@ package domain
 import (
 	"fmt"
 	"strings"
+
+	"github.com/spooky-finn/cryptobridge/config"
 )
 
 type MarketSymbol struct {
@@ -10,15 +12,16 @@ type MarketSymbol struct {
 	QuoteAsset string
 }
 
+// NewMarketSymbol creates the market of the canonical codes of the assets, the alternative codes are replaced.
 func NewMarketSymbol(base string, quote string) (*MarketSymbol, error) {
+	base = canonicalAsset(base)
+	quote = canonicalAsset(quote)
 	if base == quote {
 		return nil, fmt.Errorf("base and quote must be different")
 	}
 	if base == "" || quote == "" {
 		return nil, fmt.Errorf("base and quote must not be empty")
 	}
-	base = strings.ToLower(base)
-	quote = strings.ToLower(quote)
 	return &MarketSymbol{
 		BaseAsset:  base,
 		QuoteAsset: quote,
@@ -48,3 +51,13 @@ func (ms *MarketSymbol) String() string {
 func (ms *MarketSymbol) Equal(other *MarketSymbol) bool {
 	return ms.BaseAsset == other.BaseAsset && ms.QuoteAsset == other.QuoteAsset
 }
+
+// canonicalAsset returns the canonical code of the asset, e.g. btc for XBT.
+func canonicalAsset(asset string) string {
+	asset = strings.ToLower(asset)
+	if canonical, ok := config.AssetAliases[asset]; ok {
+		return canonical
+	}
+
+	return asset
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -11,6 +12,11 @@ import (
 	"github.com/spooky-finn/cryptobridge/helpers"
 )
 
+// Time to wait for the snapshot from the depth stream.
+const streamSnapshotTimeout = 10 * time.Second
+
+var ErrStreamSnapshotTimeout = errors.New("snapshot is not received from the depth stream")
+
 // A manager class that is responsible for maintaining (i.e. updating) the orderbook of a market.
 type OrderbookMaintainer struct {
 	syncAPI              ProviderSyncAPI
@@ -20,10 +26,17 @@ type OrderbookMaintainer struct {
 	orderBook        *OrderBook
 	depthUpdateQueue deque.Deque[*OrderBookUpdate]
 	mu               sync.Mutex
+	// updates are dropped until the snapshot is received from the depth stream
+	awaitingSnapshot bool
+	onSnapshot       chan struct{}
+	// the first update after the snapshot is validated by IFirstDepthUpdateValidator
+	firstUpdateApplied bool
 
 	OutOfSequeceErrCount int
+	subscription         *Subscription[*OrderBookUpdate]
 	wg                   sync.WaitGroup
 	done                 chan struct{}
+	stopOnce             sync.Once
 }
 
 func NewOrderBookMaintainer(
@@ -40,12 +53,18 @@ func NewOrderBookMaintainer(
 		OutOfSequeceErrCount: 0,
 		mu:                   sync.Mutex{},
 
-		done: make(chan struct{}),
+		onSnapshot: make(chan struct{}, 1),
+		done:       make(chan struct{}),
 	}
 }
 
 func (m *OrderbookMaintainer) CreareOrderBook(provider string, symbol *MarketSymbol) *CreareOrderBookResult {
-	firstUpd := m.runStreamSubscriber(symbol)
+	firstUpd, err := m.runStreamSubscriber(symbol)
+	if err != nil {
+		return &CreareOrderBookResult{
+			Err: err,
+		}
+	}
 	<-firstUpd
 
 	if config.DebugMode {
@@ -55,6 +74,7 @@ func (m *OrderbookMaintainer) CreareOrderBook(provider string, symbol *MarketSym
 
 	snapshot, err := m.syncAPI.OrderBookSnapshot(symbol, config.OrderBookMaxSupportedDepth)
 	if err != nil {
+		m.Stop()
 		return &CreareOrderBookResult{
 			Err: err,
 		}
@@ -62,6 +82,7 @@ func (m *OrderbookMaintainer) CreareOrderBook(provider string, symbol *MarketSym
 
 	orderbook := NewOrderBook(provider, symbol, snapshot)
 	m.orderBook = orderbook
+	_, m.awaitingSnapshot = m.streamAPI.(IStreamSnapshotProvider)
 
 	m.wg.Add(1)
 	go m.queueReader()
@@ -73,63 +94,221 @@ func (m *OrderbookMaintainer) CreareOrderBook(provider string, symbol *MarketSym
 	}
 }
 
+// CreateOrderBookFromStream builds the order book from the snapshot pushed in the depth stream.
+// It is used for the providers that send the snapshots themselves, the sync API is not called.
+func (m *OrderbookMaintainer) CreateOrderBookFromStream(provider string, symbol *MarketSymbol) *CreareOrderBookResult {
+	m.orderBook = NewOrderBook(provider, symbol, &OrderBookSnapshot{})
+	m.awaitingSnapshot = true
+
+	if _, err := m.runStreamSubscriber(symbol); err != nil {
+		return &CreareOrderBookResult{
+			Err: err,
+		}
+	}
+	m.wg.Add(1)
+	go m.queueReader()
+
+	if config.DebugMode {
+		log.Printf("subscribed to depth update stream, waiting for the snapshot: Symbol=%s on Provider=%s", symbol.String(), provider)
+	}
+
+	select {
+	case <-m.onSnapshot:
+	case <-time.After(streamSnapshotTimeout):
+		m.Stop()
+		return &CreareOrderBookResult{
+			Err: ErrStreamSnapshotTimeout,
+		}
+	}
+
+	return &CreareOrderBookResult{
+		OrderBook: m.orderBook,
+		Snapshot:  m.orderBook.TakeSnapshot(config.OrderBookMaxSupportedDepth),
+		Err:       nil,
+	}
+}
+
+// Stop stops processing the updates and unsubscribes from the depth stream.
 func (m *OrderbookMaintainer) Stop() {
-	close(m.done)
+	m.stopOnce.Do(func() {
+		close(m.done)
+		m.wg.Wait()
+
+		if m.subscription == nil || m.subscription.Unsubscribe == nil {
+			return
+		}
+		m.subscription.Unsubscribe()
+
+		// the stream API may be blocked on sending the update, it closes the stream when the upstream is closed
+		go func(stream <-chan *OrderBookUpdate) {
+			for range stream {
+			}
+		}(m.subscription.Stream)
+	})
 	m.wg.Wait()
 }
 
 func (m *OrderbookMaintainer) queueReader() {
+	defer m.wg.Done()
+
 	for {
-		m.mu.Lock()
-		if m.depthUpdateQueue.Len() > 0 {
-			update := m.depthUpdateQueue.PopFront()
-			err := m.depthUpdateValidator.IsValidUpd(update, m.orderBook.LastUpdateID)
-			if err != nil {
-				// TODO: process what to do when update is invalid.
-				m.checkOutOfSequeceErr(err)
-				m.mu.Unlock()
-				continue
-			}
+		select {
+		case <-m.done:
+			return
+		default:
+		}
 
-			m.orderBook.ApplyUpdate(update)
-			m.mu.Unlock()
-		} else {
+		m.mu.Lock()
+		if m.depthUpdateQueue.Len() == 0 {
 			m.mu.Unlock()
 			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		update := m.depthUpdateQueue.PopFront()
+		m.mu.Unlock()
+
+		m.processUpdate(update)
+	}
+}
+
+func (m *OrderbookMaintainer) processUpdate(update *OrderBookUpdate) {
+	if update.IsSnapshot {
+		m.orderBook.Reset(&OrderBookSnapshot{
+			LastUpdateId: update.SequenceEnd,
+			Bids:         update.Bids,
+			Asks:         update.Asks,
+		})
+		m.awaitingSnapshot = false
+		m.firstUpdateApplied = false
+
+		select {
+		case m.onSnapshot <- struct{}{}:
+		default:
+		}
+		return
+	}
+
+	if m.awaitingSnapshot {
+		return
+	}
+
+	if update.Interrupted {
+		logger.Printf("orderbook stream is interrupted. Provider=%s, Symbol=%s", m.orderBook.Provider, m.orderBook.Symbol.String())
+		m.resync()
+		return
+	}
+
+	err := m.validateUpdate(update)
+	if err != nil {
+		// the outdated updates are dropped, the gap in the sequence is recovered by a new snapshot
+		if m.depthUpdateValidator.IsErrOutOfSequece(err) {
+			m.onOutOfSequeceErr()
+		}
+		return
+	}
+
+	m.orderBook.ApplyUpdate(update)
+	m.firstUpdateApplied = true
+	m.OutOfSequeceErrCount = 0
+
+	if stateValidator, ok := m.depthUpdateValidator.(IOrderBookStateValidator); ok {
+		if err := stateValidator.IsValidState(update, m.orderBook); err != nil {
+			logger.Printf("invalid orderbook state: %s. Provider=%s, Symbol=%s", err, m.orderBook.Provider, m.orderBook.Symbol.String())
+			m.resync()
 		}
 	}
 }
 
-func (m *OrderbookMaintainer) checkOutOfSequeceErr(err error) {
-	if m.depthUpdateValidator.IsErrOutOfSequece(err) {
-		m.OutOfSequeceErrCount++
+func (m *OrderbookMaintainer) validateUpdate(update *OrderBookUpdate) error {
+	if firstValidator, ok := m.depthUpdateValidator.(IFirstDepthUpdateValidator); ok && !m.firstUpdateApplied {
+		return firstValidator.IsValidFirstUpd(update, m.orderBook.LastUpdateID)
 	}
 
-	if m.OutOfSequeceErrCount > config.OrderBookOutOfSequeceErrThreshold {
-		fmt.Printf("orderbook outdated and stopped. Provider=%s, Symbol=%s", m.orderBook.Provider, m.orderBook.Symbol.String())
-		m.orderBook.StatusOutdated()
+	return m.depthUpdateValidator.IsValidUpd(update, m.orderBook.LastUpdateID)
+}
 
-		m.Stop()
+// resync rebuilds the order book from a new snapshot.
+// The updates received in the meantime stay in the queue and are validated against the new snapshot.
+func (m *OrderbookMaintainer) resync() {
+	if m.rebuild() {
+		m.OutOfSequeceErrCount = 0
 	}
 }
 
-func (m *OrderbookMaintainer) runStreamSubscriber(symbol *MarketSymbol) <-chan struct{} {
+// rebuild requests a new snapshot. It returns false if the order book is outdated and the maintainer is stopped.
+func (m *OrderbookMaintainer) rebuild() bool {
+	symbol := m.orderBook.Symbol
+
+	if snapshotProvider, ok := m.streamAPI.(IStreamSnapshotProvider); ok {
+		// the updates are dropped until the snapshot is received, it never comes if the request fails
+		if err := snapshotProvider.RequestSnapshot(symbol); err != nil {
+			logger.Printf("failed to request snapshot: %s. Provider=%s, Symbol=%s", err, m.orderBook.Provider, symbol.String())
+			m.outdate()
+			return false
+		}
+		m.awaitingSnapshot = true
+		return true
+	}
+
+	snapshot, err := m.syncAPI.OrderBookSnapshot(symbol, config.OrderBookMaxSupportedDepth)
+	if err != nil {
+		logger.Printf("failed to resync orderbook: %s. Provider=%s, Symbol=%s", err, m.orderBook.Provider, symbol.String())
+		m.outdate()
+		return false
+	}
+
+	m.orderBook.Reset(snapshot)
+	m.firstUpdateApplied = false
+	return true
+}
+
+// onOutOfSequeceErr rebuilds the order book after the gap in the sequence. The order book is outdated
+// if the gaps keep coming after the new snapshots, e.g. the snapshots lag behind the stream.
+func (m *OrderbookMaintainer) onOutOfSequeceErr() {
+	m.OutOfSequeceErrCount++
+
+	if m.OutOfSequeceErrCount > config.OrderBookOutOfSequeceErrThreshold {
+		logger.Printf("orderbook update is out of sequence %d times in a row. Provider=%s, Symbol=%s", m.OutOfSequeceErrCount, m.orderBook.Provider, m.orderBook.Symbol.String())
+		m.outdate()
+		return
+	}
+
+	logger.Printf("orderbook update is out of sequence, resyncing. Provider=%s, Symbol=%s", m.orderBook.Provider, m.orderBook.Symbol.String())
+	m.rebuild()
+}
+
+// outdate marks the order book outdated and stops the maintainer. The outdated order book is not served,
+// it is removed from the storage and created again on the next request.
+func (m *OrderbookMaintainer) outdate() {
+	logger.Printf("orderbook outdated and stopped. Provider=%s, Symbol=%s", m.orderBook.Provider, m.orderBook.Symbol.String())
+	m.orderBook.StatusOutdated()
+
+	// Stop waits for the queue reader, so it can't be called from it.
+	go m.Stop()
+}
+
+func (m *OrderbookMaintainer) runStreamSubscriber(symbol *MarketSymbol) (<-chan struct{}, error) {
 	fistUpdteProcessed := false
 	onFirstUpdateCh := make(chan struct{}, 1)
 
 	subscription, err := m.streamAPI.DepthDiffStream(symbol)
 	if err != nil {
-		logger.Fatalf("error while subscribing to depth update stream  " + err.Error())
+		return nil, fmt.Errorf("error while subscribing to depth update stream: %w", err)
 	}
+	m.subscription = subscription
 
 	m.wg.Add(1)
 	go func() {
+		defer m.wg.Done()
+
 		for {
 			select {
 			case <-m.done:
-				m.wg.Done()
 				return
-			case update := <-subscription.Stream:
+			case update, ok := <-subscription.Stream:
+				if !ok {
+					return
+				}
 				m.mu.Lock()
 				m.depthUpdateQueue.PushBack(update)
 				m.mu.Unlock()
@@ -143,7 +322,7 @@ func (m *OrderbookMaintainer) runStreamSubscriber(symbol *MarketSymbol) <-chan s
 		}
 	}()
 
-	return helpers.WithLatestFrom(onFirstUpdateCh, TimeToEmtyChan(time.After(1*time.Second)))
+	return helpers.WithLatestFrom(onFirstUpdateCh, TimeToEmtyChan(time.After(1*time.Second))), nil
 }
 
 func TimeToEmtyChan(in <-chan time.Time) chan struct{} {
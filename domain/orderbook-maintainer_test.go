@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSyncAPI struct {
+	snapshot *OrderBookSnapshot
+	err      error
+	calls    int
+}
+
+func (api *fakeSyncAPI) OrderBookSnapshot(symbol *MarketSymbol, limit int) (*OrderBookSnapshot, error) {
+	api.calls++
+	return api.snapshot, api.err
+}
+
+type fakeValidator struct {
+	stateErr error
+}
+
+func (v *fakeValidator) IsValidUpd(update *OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return ErrOrderBookUpdateIsOutdated
+	}
+	if update.SequenceStart > orderBookLastUpdId+1 {
+		return ErrOrderBookUpdateIsOutOfSequece
+	}
+	return nil
+}
+
+func (v *fakeValidator) IsValidState(update *OrderBookUpdate, orderBook *OrderBook) error {
+	return v.stateErr
+}
+
+func (v *fakeValidator) IsErrOutOfSequece(err error) bool {
+	return err == ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *fakeValidator) IsErrOutdated(err error) bool {
+	return err == ErrOrderBookUpdateIsOutdated
+}
+
+func newTestMaintainer(syncAPI ProviderSyncAPI, validator IDepthUpdateValidator) *OrderbookMaintainer {
+	symbol, _ := NewMarketSymbol("BTC", "USDT")
+	m := NewOrderBookMaintainer(nil, syncAPI, validator)
+	m.orderBook = NewOrderBook("MockProvider", symbol, &OrderBookSnapshot{
+		LastUpdateId: 100,
+		Bids:         [][]string{{"10000", "1"}},
+		Asks:         [][]string{{"10100", "1"}},
+	})
+
+	return m
+}
+
+func TestOrderbookMaintainer_ProcessSnapshotUpdate(t *testing.T) {
+	m := newTestMaintainer(&fakeSyncAPI{}, &fakeValidator{})
+	m.awaitingSnapshot = true
+
+	// updates are dropped until the snapshot is received
+	m.processUpdate(&OrderBookUpdate{SequenceEnd: 101, Bids: [][]string{{"9000", "1"}}})
+	assert.Equal(t, int64(100), m.orderBook.LastUpdateID, "LastUpdateID should not change")
+
+	m.processUpdate(&OrderBookUpdate{
+		IsSnapshot:  true,
+		SequenceEnd: 50,
+		Bids:        [][]string{{"9900", "2"}},
+		Asks:        [][]string{{"10000", "3"}},
+	})
+
+	assert.False(t, m.awaitingSnapshot, "Snapshot should be received")
+	assert.Equal(t, int64(50), m.orderBook.LastUpdateID, "LastUpdateID should match")
+	assert.Equal(t, [][]float64{{9900, 2}}, m.orderBook.Bids, "Bids should match")
+	assert.Equal(t, [][]float64{{10000, 3}}, m.orderBook.Asks, "Asks should match")
+}
+
+func TestOrderbookMaintainer_ResyncOnInvalidState(t *testing.T) {
+	syncAPI := &fakeSyncAPI{
+		snapshot: &OrderBookSnapshot{
+			LastUpdateId: 200,
+			Bids:         [][]string{{"9800", "5"}},
+			Asks:         [][]string{{"9900", "5"}},
+		},
+	}
+	m := newTestMaintainer(syncAPI, &fakeValidator{stateErr: ErrOrderBookChecksumMismatch})
+
+	m.processUpdate(&OrderBookUpdate{SequenceEnd: 101, Bids: [][]string{{"10000", "2"}}})
+
+	assert.Equal(t, 1, syncAPI.calls, "Snapshot should be requested")
+	assert.Equal(t, int64(200), m.orderBook.LastUpdateID, "LastUpdateID should match")
+	assert.Equal(t, [][]float64{{9800, 5}}, m.orderBook.Bids, "Bids should match")
+}
+
+func TestOrderbookMaintainer_ResyncOnInterruptedStream(t *testing.T) {
+	syncAPI := &fakeSyncAPI{
+		snapshot: &OrderBookSnapshot{
+			LastUpdateId: 300,
+			Bids:         [][]string{{"9700", "1"}},
+			Asks:         [][]string{{"9800", "1"}},
+		},
+	}
+	m := newTestMaintainer(syncAPI, &fakeValidator{})
+	m.OutOfSequeceErrCount = 5
+
+	m.processUpdate(&OrderBookUpdate{Interrupted: true})
+
+	assert.Equal(t, 1, syncAPI.calls, "Snapshot should be requested")
+	assert.Equal(t, int64(300), m.orderBook.LastUpdateID, "LastUpdateID should match")
+	assert.Equal(t, 0, m.OutOfSequeceErrCount, "OutOfSequeceErrCount should be reset")
+}
+
+func TestOrderbookMaintainer_ResyncOnGap(t *testing.T) {
+	syncAPI := &fakeSyncAPI{
+		snapshot: &OrderBookSnapshot{
+			LastUpdateId: 110,
+			Bids:         [][]string{{"9700", "1"}},
+			Asks:         [][]string{{"9800", "1"}},
+		},
+	}
+	m := newTestMaintainer(syncAPI, &fakeValidator{})
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 105, SequenceEnd: 106, Bids: [][]string{{"10000", "2"}}})
+
+	assert.Equal(t, 1, syncAPI.calls, "Snapshot should be requested")
+	assert.Equal(t, int64(110), m.orderBook.LastUpdateID, "LastUpdateID should match")
+	assert.Equal(t, 1, m.OutOfSequeceErrCount, "OutOfSequeceErrCount should be counted")
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 111, SequenceEnd: 111, Bids: [][]string{{"9700", "2"}}})
+	assert.Equal(t, int64(111), m.orderBook.LastUpdateID, "Update should be applied after the resync")
+	assert.Equal(t, 0, m.OutOfSequeceErrCount, "OutOfSequeceErrCount should be reset")
+}
+
+func TestOrderbookMaintainer_OutdatedOnFailedResync(t *testing.T) {
+	syncAPI := &fakeSyncAPI{err: errors.New("rate limited")}
+	m := newTestMaintainer(syncAPI, &fakeValidator{})
+
+	m.processUpdate(&OrderBookUpdate{Interrupted: true})
+
+	assert.True(t, m.orderBook.IsOutdated(), "Order book should be outdated")
+	select {
+	case <-m.done:
+	case <-time.After(time.Second):
+		t.Fatal("maintainer should be stopped")
+	}
+}
+
+// fakeFirstUpdateValidator accepts the first update if it overlaps the snapshot, the following ones must be chained.
+type fakeFirstUpdateValidator struct {
+	fakeValidator
+}
+
+func (v *fakeFirstUpdateValidator) IsValidUpd(update *OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.PrevSequenceEnd != orderBookLastUpdId {
+		return ErrOrderBookUpdateIsOutOfSequece
+	}
+	return nil
+}
+
+func (v *fakeFirstUpdateValidator) IsValidFirstUpd(update *OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return ErrOrderBookUpdateIsOutdated
+	}
+	if update.SequenceStart > orderBookLastUpdId {
+		return ErrOrderBookUpdateIsOutOfSequece
+	}
+	return nil
+}
+
+func TestOrderbookMaintainer_FirstUpdateAfterSnapshot(t *testing.T) {
+	syncAPI := &fakeSyncAPI{snapshot: &OrderBookSnapshot{LastUpdateId: 200}}
+	m := newTestMaintainer(syncAPI, &fakeFirstUpdateValidator{})
+
+	// the update ending at the snapshot is already included into it
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 95, SequenceEnd: 100, PrevSequenceEnd: 94})
+	assert.Equal(t, int64(100), m.orderBook.LastUpdateID, "Update ending at the snapshot should be dropped")
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 95, SequenceEnd: 110, PrevSequenceEnd: 94})
+	assert.Equal(t, int64(110), m.orderBook.LastUpdateID, "First update should overlap the snapshot")
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 105, SequenceEnd: 120, PrevSequenceEnd: 115})
+	assert.Equal(t, 1, syncAPI.calls, "Update out of the chain should resync the order book")
+	assert.Equal(t, int64(200), m.orderBook.LastUpdateID, "LastUpdateID should match")
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 195, SequenceEnd: 200, PrevSequenceEnd: 194})
+	assert.Equal(t, int64(200), m.orderBook.LastUpdateID, "Update ending at the new snapshot should be dropped")
+
+	m.processUpdate(&OrderBookUpdate{SequenceStart: 195, SequenceEnd: 210, PrevSequenceEnd: 194})
+	assert.Equal(t, int64(210), m.orderBook.LastUpdateID, "First update should overlap the new snapshot")
+}
+
+type fakeStreamAPI struct {
+	stream       chan *OrderBookUpdate
+	unsubscribed int
+}
+
+func (api *fakeStreamAPI) GetOrderBook(symbol *MarketSymbol) *CreareOrderBookResult {
+	return nil
+}
+
+func (api *fakeStreamAPI) DepthDiffStream(symbol *MarketSymbol) (*Subscription[*OrderBookUpdate], error) {
+	return &Subscription[*OrderBookUpdate]{
+		Stream: api.stream,
+		Unsubscribe: func() {
+			api.unsubscribed++
+			close(api.stream)
+		},
+	}, nil
+}
+
+func TestOrderbookMaintainer_CreateOrderBookFromStream(t *testing.T) {
+	streamAPI := &fakeStreamAPI{stream: make(chan *OrderBookUpdate, 3)}
+	syncAPI := &fakeSyncAPI{}
+	symbol, _ := NewMarketSymbol("BTC", "USDT")
+
+	// the delta received before the snapshot is dropped
+	streamAPI.stream <- &OrderBookUpdate{SequenceStart: 9, SequenceEnd: 9, Bids: [][]string{{"1", "1"}}}
+	streamAPI.stream <- &OrderBookUpdate{IsSnapshot: true, SequenceEnd: 10, Bids: [][]string{{"100", "1"}}, Asks: [][]string{{"101", "1"}}}
+
+	m := NewOrderBookMaintainer(streamAPI, syncAPI, &fakeValidator{})
+	result := m.CreateOrderBookFromStream("MockProvider", symbol)
+	defer m.Stop()
+
+	assert.NoError(t, result.Err, "Unexpected error")
+	assert.Equal(t, 0, syncAPI.calls, "Sync API should not be called")
+	assert.Equal(t, int64(10), result.Snapshot.LastUpdateId, "LastUpdateId should be taken from the snapshot")
+	assert.Equal(t, [][]string{{"100", "1"}}, result.Snapshot.Bids, "Bids should match")
+	assert.Equal(t, [][]string{{"101", "1"}}, result.Snapshot.Asks, "Asks should match")
+
+	streamAPI.stream <- &OrderBookUpdate{SequenceStart: 11, SequenceEnd: 11, Bids: [][]string{{"100", "2"}}}
+	assert.Eventually(t, func() bool {
+		return result.OrderBook.TakeSnapshot(1).LastUpdateId == 11
+	}, time.Second, 10*time.Millisecond, "Delta should be applied")
+}
+
+func TestOrderbookMaintainer_StopUnsubscribes(t *testing.T) {
+	streamAPI := &fakeStreamAPI{stream: make(chan *OrderBookUpdate, 1)}
+	streamAPI.stream <- &OrderBookUpdate{SequenceStart: 1, SequenceEnd: 1}
+	symbol, _ := NewMarketSymbol("BTC", "USDT")
+
+	// the maintainer is stopped if the snapshot is not received
+	m := NewOrderBookMaintainer(streamAPI, &fakeSyncAPI{err: errors.New("rate limited")}, &fakeValidator{})
+	result := m.CreareOrderBook("MockProvider", symbol)
+	assert.Error(t, result.Err)
+	assert.Equal(t, 1, streamAPI.unsubscribed, "Stream should be unsubscribed")
+
+	m.Stop()
+	assert.Equal(t, 1, streamAPI.unsubscribed, "Stream should be unsubscribed once")
+}
+
+type fakeSnapshotStreamAPI struct {
+	fakeStreamAPI
+	err error
+}
+
+func (api *fakeSnapshotStreamAPI) RequestSnapshot(symbol *MarketSymbol) error {
+	return api.err
+}
+
+func TestOrderbookMaintainer_OutdatedOnFailedSnapshotRequest(t *testing.T) {
+	m := newTestMaintainer(&fakeSyncAPI{}, &fakeValidator{})
+	m.streamAPI = &fakeSnapshotStreamAPI{err: errors.New("connection is not established")}
+
+	m.processUpdate(&OrderBookUpdate{Interrupted: true})
+
+	assert.False(t, m.awaitingSnapshot, "Updates should not wait for the snapshot that was not requested")
+	assert.True(t, m.orderBook.IsOutdated(), "Order book should be outdated")
+}
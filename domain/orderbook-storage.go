@@ -38,8 +38,7 @@ func (o *OrderBookStorage) Add(provider string, symbol *MarketSymbol, orderBook
 	o.storage[provider][symbol.String()] = orderBook
 	o.mu.Unlock()
 
-	promclient.BinanceOpenOrderBookGauge.Set(float64(o.OrderBookCount("binance")))
-	promclient.KucoinOpenOrderBookGauge.Set(float64(o.OrderBookCount("kucoin")))
+	promclient.SetOpenOrderBooks(provider, o.OrderBookCount(provider))
 }
 
 func (o *OrderBookStorage) Get(provider string, symbol *MarketSymbol) (*OrderBook, error) {
@@ -71,40 +70,47 @@ func (o *OrderBookStorage) OrderBookCount(provider string) int {
 func (o *OrderBookStorage) Remove(provider string, symbol *MarketSymbol) error {
 	o.mu.Lock()
 	if _, ok := o.storage[provider]; !ok {
-		return errors.New("provider not found")
+		o.mu.Unlock()
+		return ErrProviderNotFound
 	}
 
 	delete(o.storage[provider], symbol.String())
 	o.mu.Unlock()
 
-	promclient.BinanceOpenOrderBookGauge.Set(float64(o.OrderBookCount("binance")))
-	promclient.KucoinOpenOrderBookGauge.Set(float64(o.OrderBookCount("kucoin")))
+	promclient.SetOpenOrderBooks(provider, o.OrderBookCount(provider))
 	return nil
 }
 
 func (o *OrderBookStorage) runGC() {
 	for {
-		o.mu.RLock()
-		for provider, symbols := range o.storage {
-			for symbol, orderBook := range symbols {
-				if orderBook.status == OrderBookStatus_Oudated {
-					logger.Printf("cleaning outdated order book: %s %s\n", provider, symbol)
-					err := o.Remove(provider, orderBook.Symbol)
-
-					if err != nil {
-						logger.Printf("failed to remove outdated order book: %s %s, %s\n", provider, symbol, err)
-					}
-				}
+		for _, orderBook := range o.outdated() {
+			logger.Printf("cleaning outdated order book: %s %s\n", orderBook.Provider, orderBook.Symbol.String())
+			if err := o.Remove(orderBook.Provider, orderBook.Symbol); err != nil {
+				logger.Printf("failed to remove outdated order book: %s %s, %s\n", orderBook.Provider, orderBook.Symbol.String(), err)
 			}
-
 		}
 
 		o.logStat()
-		o.mu.RUnlock()
 		<-time.After(10 * time.Second)
 	}
 }
 
+// outdated returns the order books that are no longer maintained.
+func (o *OrderBookStorage) outdated() []*OrderBook {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var result []*OrderBook
+	for _, symbols := range o.storage {
+		for _, orderBook := range symbols {
+			if orderBook.IsOutdated() {
+				result = append(result, orderBook)
+			}
+		}
+	}
+	return result
+}
+
 // logStat the information about the order book count in the memeory
 func (o *OrderBookStorage) logStat() {
 	o.mu.RLock()
@@ -1,12 +1,16 @@
 package domain
 
 import (
+	"errors"
 	"sort"
 	"strconv"
 	"sync"
 	"time"
 )
 
+// ErrDepthExceedsOrderBook is returned for the depth beyond the levels kept by the order book, see SetMaxDepth.
+var ErrDepthExceedsOrderBook = errors.New("the depth exceeds the levels kept by the order book")
+
 type OrderBookSource string
 type OrderBookStatus string
 
@@ -24,6 +28,8 @@ type OrderBookSnapshot struct {
 	LastUpdateTime int64           `json:"lastUpdateTime"`
 	Bids           [][]string      `json:"bids"`
 	Asks           [][]string      `json:"asks"`
+	// NativeSymbol is the symbol of the market on the provider, e.g. BTCUSDT.
+	NativeSymbol string `json:"nativeSymbol,omitempty"`
 }
 
 type OrderBookUpdate struct {
@@ -31,7 +37,15 @@ type OrderBookUpdate struct {
 	Asks          [][]string
 	SequenceStart int64
 	SequenceEnd   int64
-	Symbol        *MarketSymbol
+	// PrevSequenceEnd is the SequenceEnd of the previous update. Set only by providers that send it explicitly.
+	PrevSequenceEnd int64
+	// IsSnapshot is set when the update replaces the whole order book.
+	IsSnapshot bool
+	// Checksum of the order book after the update. Set only by providers that send it.
+	Checksum uint32
+	// Interrupted is set by the providers without the sequence ids when the continuity of the stream is lost before the update.
+	Interrupted bool
+	Symbol      *MarketSymbol
 }
 
 func NewOrderBookUpdate(bids, asks [][]string, start, end int64, symbol *MarketSymbol) *OrderBookUpdate {
@@ -44,6 +58,11 @@ func NewOrderBookUpdate(bids, asks [][]string, start, end int64, symbol *MarketS
 	}
 }
 
+// NewInterruptedUpdate makes the maintainer rebuild the order book, the updates were lost before it.
+func NewInterruptedUpdate(symbol *MarketSymbol) *OrderBookUpdate {
+	return &OrderBookUpdate{Interrupted: true, Symbol: symbol}
+}
+
 type OrderBook struct {
 	Provider       string
 	Symbol         *MarketSymbol
@@ -52,6 +71,13 @@ type OrderBook struct {
 	LastUpdateID   int64
 	LastUpdateTime int64
 
+	// The original price and quantity strings sent by the provider, keyed by the price.
+	// Some providers calculate the checksum from the exact decimal strings.
+	askStrings map[float64][]string
+	bidStrings map[float64][]string
+	// The book is truncated to the max depth after each update if it is set.
+	maxDepth int
+
 	status OrderBookStatus
 	// MessageBus chan interface{}
 	OnSnapshotRecieved chan *OrderBookSnapshot
@@ -59,14 +85,20 @@ type OrderBook struct {
 }
 
 func NewOrderBook(provider string, symbol *MarketSymbol, snapshot *OrderBookSnapshot) *OrderBook {
+	asks := parsePriceLevel(snapshot.Asks)
+	bids := parsePriceLevel(snapshot.Bids)
+
 	return &OrderBook{
 		Provider:       provider,
 		Symbol:         symbol,
-		Asks:           parsePriceLevel(snapshot.Asks),
-		Bids:           parsePriceLevel(snapshot.Bids),
+		Asks:           asks,
+		Bids:           bids,
 		LastUpdateID:   snapshot.LastUpdateId,
 		LastUpdateTime: time.Now().Unix(),
 
+		askStrings: levelStrings(snapshot.Asks, asks),
+		bidStrings: levelStrings(snapshot.Bids, bids),
+
 		status: OrderBookStatus_Ok,
 
 		updateMx: &sync.Mutex{},
@@ -91,34 +123,105 @@ func (ob *OrderBook) ApplyUpdate(update *OrderBookUpdate) {
 	ob.LastUpdateID = update.SequenceEnd
 	ob.LastUpdateTime = time.Now().Unix()
 
-	ob.updateDepth(updateAsks, true)
-	ob.updateDepth(updateBids, false)
+	ob.updateDepth(update.Asks, updateAsks, true)
+	ob.updateDepth(update.Bids, updateBids, false)
+}
+
+// Reset replaces the whole order book with the snapshot.
+func (ob *OrderBook) Reset(snapshot *OrderBookSnapshot) {
+	asks := parsePriceLevel(snapshot.Asks)
+	bids := parsePriceLevel(snapshot.Bids)
+
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
+	ob.Asks = asks
+	ob.Bids = bids
+	ob.askStrings = levelStrings(snapshot.Asks, asks)
+	ob.bidStrings = levelStrings(snapshot.Bids, bids)
+	ob.LastUpdateID = snapshot.LastUpdateId
+	ob.LastUpdateTime = time.Now().Unix()
+
+	ob.truncate()
+}
+
+// SetMaxDepth makes the order book keep only the best levels of each side.
+// It is required by the providers that don't send the removal of the levels that fall out of the subscribed depth.
+func (ob *OrderBook) SetMaxDepth(depth int) {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
+	ob.maxDepth = depth
+	ob.truncate()
+}
+
+// MaxDepth returns the number of the levels kept of each side, zero if the order book is not limited.
+func (ob *OrderBook) MaxDepth() int {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
+	return ob.maxDepth
 }
 
 func (ob *OrderBook) StatusOutdated() {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
 	ob.status = OrderBookStatus_Oudated
 }
 
-func (ob *OrderBook) TakeSnapshot(limit int) *OrderBookSnapshot {
+// IsOutdated reports whether the order book is no longer maintained.
+func (ob *OrderBook) IsOutdated() bool {
 	ob.updateMx.Lock()
 	defer ob.updateMx.Unlock()
 
-	bids := make([][]float64, len(ob.Bids))
-	asks := make([][]float64, len(ob.Asks))
-
-	copy(bids, ob.Bids)
-	copy(asks, ob.Asks)
+	return ob.status == OrderBookStatus_Oudated
+}
 
-	bids = ob.limitDepth(bids, limit)
-	asks = ob.limitDepth(asks, limit)
+func (ob *OrderBook) TakeSnapshot(limit int) *OrderBookSnapshot {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
 
 	return &OrderBookSnapshot{
 		Source:         OrderBookSource_LocalOrderBook,
 		LastUpdateId:   ob.LastUpdateID,
 		LastUpdateTime: ob.LastUpdateTime,
-		Bids:           serializePriceLevel(bids),
-		Asks:           serializePriceLevel(asks),
+		Bids:           ob.rawDepth(ob.Bids, ob.bidStrings, limit),
+		Asks:           ob.rawDepth(ob.Asks, ob.askStrings, limit),
+	}
+}
+
+// RawDepth returns the best price levels of the order book as the original strings sent by the provider.
+func (ob *OrderBook) RawDepth(limit int) (bids, asks [][]string) {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
+	return ob.rawDepth(ob.Bids, ob.bidStrings, limit), ob.rawDepth(ob.Asks, ob.askStrings, limit)
+}
+
+func (ob *OrderBook) rawDepth(depth [][]float64, rawLevels map[float64][]string, limit int) [][]string {
+	depth = ob.limitDepth(depth, limit)
+	result := serializePriceLevel(depth)
+
+	for i, level := range depth {
+		if raw, ok := rawLevels[level[0]]; ok {
+			result[i] = []string{raw[0], raw[1]}
+		}
 	}
+
+	return result
+}
+
+// Depth returns a copy of the best price levels of the order book.
+func (ob *OrderBook) Depth(limit int) (bids, asks [][]float64) {
+	ob.updateMx.Lock()
+	defer ob.updateMx.Unlock()
+
+	return ob.depth(limit)
+}
+
+func (ob *OrderBook) depth(limit int) (bids, asks [][]float64) {
+	return copyDepth(ob.limitDepth(ob.Bids, limit)), copyDepth(ob.limitDepth(ob.Asks, limit))
 }
 
 func (ob *OrderBook) limitDepth(depth [][]float64, limit int) [][]float64 {
@@ -129,21 +232,25 @@ func (ob *OrderBook) limitDepth(depth [][]float64, limit int) [][]float64 {
 	return depth
 }
 
-func (ob *OrderBook) updateDepth(updateDepth [][]float64, isAsks bool) {
+func (ob *OrderBook) updateDepth(rawDepth [][]string, updateDepth [][]float64, isAsks bool) {
 	var depth [][]float64
+	var rawLevels map[float64][]string
 
 	if isAsks {
 		depth = ob.Asks
+		rawLevels = ob.askStrings
 	} else {
 		depth = ob.Bids
+		rawLevels = ob.bidStrings
 	}
 
-	for _, level := range updateDepth {
+	for n, level := range updateDepth {
 		price := level[0]
 		quantity := level[1]
 
 		if quantity == 0 {
 			// remove price level
+			delete(rawLevels, price)
 			for i, level := range depth {
 				if level[0] == price {
 					depth[i] = depth[len(depth)-1]
@@ -154,6 +261,7 @@ func (ob *OrderBook) updateDepth(updateDepth [][]float64, isAsks bool) {
 		} else {
 			// if price level exists, update quantity
 			// otherwise, add price level
+			rawLevels[price] = rawDepth[n][:2]
 			updated := false
 			for i, level := range depth {
 				if level[0] == price {
@@ -184,6 +292,51 @@ func (ob *OrderBook) updateDepth(updateDepth [][]float64, isAsks bool) {
 	} else {
 		ob.Bids = depth
 	}
+
+	ob.truncate()
+}
+
+func (ob *OrderBook) truncate() {
+	if ob.maxDepth <= 0 {
+		return
+	}
+
+	for _, level := range ob.limitTail(ob.Asks) {
+		delete(ob.askStrings, level[0])
+	}
+	for _, level := range ob.limitTail(ob.Bids) {
+		delete(ob.bidStrings, level[0])
+	}
+
+	ob.Asks = ob.limitDepth(ob.Asks, ob.maxDepth)
+	ob.Bids = ob.limitDepth(ob.Bids, ob.maxDepth)
+}
+
+// limitTail returns the levels beyond the max depth.
+func (ob *OrderBook) limitTail(depth [][]float64) [][]float64 {
+	if len(depth) > ob.maxDepth {
+		return depth[ob.maxDepth:]
+	}
+
+	return nil
+}
+
+func levelStrings(rawDepth [][]string, depth [][]float64) map[float64][]string {
+	result := make(map[float64][]string, len(depth))
+	for i, level := range depth {
+		result[level[0]] = rawDepth[i][:2]
+	}
+
+	return result
+}
+
+func copyDepth(depth [][]float64) [][]float64 {
+	result := make([][]float64, len(depth))
+	for i, level := range depth {
+		result[i] = []float64{level[0], level[1]}
+	}
+
+	return result
 }
 
 func parsePriceLevel(depth [][]string) [][]float64 {
@@ -177,3 +177,61 @@ func TestLimitDepth(t *testing.T) {
 	assert.Equal(t, len(bids), 1, "Bids should be limited to 1")
 	assert.Equal(t, len(asks), 1, "Asks should be limited to 1")
 }
+
+func TestOrderBook_RawDepth(t *testing.T) {
+	symbol, err := NewMarketSymbol("BTC", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := &OrderBookSnapshot{
+		LastUpdateId: 123,
+		Bids:         [][]string{{"45283.5", "0.10000000"}, {"45283.4", "1.54582015"}},
+		Asks:         [][]string{{"45285.2", "0.00100000"}, {"45286.4", "1.54571953"}},
+	}
+	update := &OrderBookUpdate{
+		SequenceEnd: 124,
+		Bids:        [][]string{{"45283.5", "0.20000000"}, {"45283.4", "0.00000000"}},
+		Asks:        [][]string{{"45285.0", "1.00000000"}},
+	}
+
+	ob := NewOrderBook("MockProvider", symbol, snapshot)
+	ob.ApplyUpdate(update)
+
+	bids, asks := ob.RawDepth(10)
+
+	assert.Equal(t, [][]string{{"45283.5", "0.20000000"}}, bids, "Bids should keep the original strings")
+	assert.Equal(t, [][]string{{"45285.0", "1.00000000"}, {"45285.2", "0.00100000"}, {"45286.4", "1.54571953"}}, asks, "Asks should keep the original strings")
+	assert.Equal(t, asks[:2], ob.TakeSnapshot(2).Asks, "Snapshot should keep the original strings")
+}
+
+func TestOrderBook_SetMaxDepth(t *testing.T) {
+	symbol, err := NewMarketSymbol("BTC", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := &OrderBookSnapshot{
+		LastUpdateId: 123,
+		Bids:         [][]string{{"10000", "1"}, {"9900", "2"}},
+		Asks:         [][]string{{"10100", "1.5"}, {"10200", "2.5"}},
+	}
+
+	ob := NewOrderBook("MockProvider", symbol, snapshot)
+	ob.SetMaxDepth(2)
+	ob.ApplyUpdate(&OrderBookUpdate{
+		SequenceEnd: 124,
+		Asks:        [][]string{{"10050", "1"}},
+	})
+
+	assert.Equal(t, [][]float64{{10050, 1}, {10100, 1.5}}, ob.Asks, "Asks should be truncated to the max depth")
+
+	// the level that fell out of the depth must not be restored with the old quantity
+	ob.ApplyUpdate(&OrderBookUpdate{
+		SequenceEnd: 125,
+		Asks:        [][]string{{"10050", "0"}},
+	})
+
+	_, asks := ob.RawDepth(10)
+	assert.Equal(t, [][]string{{"10100", "1.5"}}, asks, "Asks should match")
+}
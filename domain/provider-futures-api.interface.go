@@ -0,0 +1,27 @@
+package domain
+
+import "errors"
+
+var ErrFuturesNotSupported = errors.New("provider doesn't support futures market data")
+
+type MarkPrice struct {
+	MarkPrice  string
+	IndexPrice string
+	Time       int64
+	// NativeSymbol is the symbol of the contract on the provider, e.g. BTCUSDT.
+	NativeSymbol string
+}
+
+type FundingRate struct {
+	FundingRate     string
+	NextFundingTime int64
+	Time            int64
+	// NativeSymbol is the symbol of the contract on the provider, e.g. BTCUSDT.
+	NativeSymbol string
+}
+
+// ProviderFuturesAPI serves the market data specific to the perpetual futures.
+type ProviderFuturesAPI interface {
+	MarkPrice(symbol *MarketSymbol) (*MarkPrice, error)
+	FundingRate(symbol *MarketSymbol) (*FundingRate, error)
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultRequestTimeout   = 10 * time.Second
+	defaultHandshakeTimeout = 5 * time.Second
+)
+
+// ProviderOptions configures the endpoints and the transports of a provider.
+// The zero values select the production endpoints and the default transports.
+type ProviderOptions struct {
+	// RestURL is the base url of the REST api.
+	RestURL string
+	// StreamURLs are the websocket endpoints of the streams in the order of preference.
+	StreamURLs []string
+	// SyncURL is the websocket endpoint of the request-response api, e.g. the binance websocket api.
+	SyncURL string
+
+	// HTTPClient is used for the REST requests. It is built of the Proxy and the Timeout if not set.
+	HTTPClient *http.Client
+	// Dialer is used for the websocket connections. It is built of the Proxy and the HandshakeTimeout if not set.
+	Dialer *websocket.Dialer
+	// Proxy returns the proxy of the request, the proxy of the environment is used if not set.
+	Proxy func(*http.Request) (*url.URL, error)
+	// Timeout of the REST requests.
+	Timeout time.Duration
+	// HandshakeTimeout of the websocket connections.
+	HandshakeTimeout time.Duration
+
+	// Recorder captures the raw frames and the snapshots of the provider. Nothing is captured if it is not set.
+	Recorder Recorder
+	// ReplayFiles are the capture files the provider replays instead of connecting to the venue.
+	ReplayFiles []string
+	// ReplaySpeed paces the replay, e.g. 2 replays twice as fast as the capture. The zero speed replays without the delays.
+	ReplaySpeed float64
+}
+
+// RestURLOr returns the base url of the REST api or the default one if it is not set.
+func (o ProviderOptions) RestURLOr(def string) string {
+	if o.RestURL != "" {
+		return o.RestURL
+	}
+	return def
+}
+
+// StreamURLOr returns the most preferred stream endpoint or the default one if none is set.
+func (o ProviderOptions) StreamURLOr(def string) string {
+	if len(o.StreamURLs) > 0 {
+		return o.StreamURLs[0]
+	}
+	return def
+}
+
+// StreamURLsOr returns the stream endpoints or the default ones if none is set.
+func (o ProviderOptions) StreamURLsOr(def []string) []string {
+	if len(o.StreamURLs) > 0 {
+		return o.StreamURLs
+	}
+	return def
+}
+
+// SyncURLOr returns the endpoint of the request-response api or the default one if it is not set.
+func (o ProviderOptions) SyncURLOr(def string) string {
+	if o.SyncURL != "" {
+		return o.SyncURL
+	}
+	return def
+}
+
+// NewHTTPClient returns the client of the options or builds one of the proxy and the timeout.
+func (o ProviderOptions) NewHTTPClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{Proxy: o.proxy()},
+		Timeout:   timeout,
+	}
+}
+
+// NewDialer returns the dialer of the options or builds one of the proxy and the handshake timeout.
+func (o ProviderOptions) NewDialer() *websocket.Dialer {
+	if o.Dialer != nil {
+		return o.Dialer
+	}
+
+	timeout := o.HandshakeTimeout
+	if timeout == 0 {
+		timeout = defaultHandshakeTimeout
+	}
+
+	return &websocket.Dialer{
+		Proxy:            o.proxy(),
+		HandshakeTimeout: timeout,
+	}
+}
+
+// NewRecorder returns the recorder of the options or the one that records nothing.
+func (o ProviderOptions) NewRecorder() Recorder {
+	if o.Recorder != nil {
+		return o.Recorder
+	}
+	return NopRecorder{}
+}
+
+func (o ProviderOptions) proxy() func(*http.Request) (*url.URL, error) {
+	if o.Proxy != nil {
+		return o.Proxy
+	}
+	return http.ProxyFromEnvironment
+}
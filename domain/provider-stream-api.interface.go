@@ -14,3 +14,9 @@ type ProviderStreamAPI interface {
 	GetOrderBook(marketSymbol *MarketSymbol) *CreareOrderBookResult
 	DepthDiffStream(marketSymbol *MarketSymbol) (*Subscription[*OrderBookUpdate], error)
 }
+
+// IStreamSnapshotProvider is implemented by the stream APIs that send the order book snapshot in the depth stream.
+type IStreamSnapshotProvider interface {
+	// RequestSnapshot makes the depth stream of the symbol send a new snapshot.
+	RequestSnapshot(marketSymbol *MarketSymbol) error
+}
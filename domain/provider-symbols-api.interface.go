@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+var ErrUnknownMarket = errors.New("market is not listed by the provider")
+
+// ProviderSymbolsAPI lists the markets traded on the venue.
+type ProviderSymbolsAPI interface {
+	Symbols() ([]*MarketSymbol, error)
+}
@@ -0,0 +1,25 @@
+package domain
+
+// StreamClient is a long-living connection to the provider streaming endpoint.
+type StreamClient interface {
+	Connect() error
+	Close() error
+}
+
+// Provider is a set of components required to serve the order books of a single venue.
+type Provider struct {
+	Name                 string
+	StreamClient         StreamClient
+	StreamAPI            ProviderStreamAPI
+	SyncAPI              ProviderSyncAPI
+	DepthUpdateValidator IDepthUpdateValidator
+	// FuturesAPI is set only by the providers of the futures markets.
+	FuturesAPI ProviderFuturesAPI
+	// SymbolsAPI lists the markets of the provider. The markets are not validated if it is not set.
+	SymbolsAPI ProviderSymbolsAPI
+	// SymbolMapper translates the market symbols to the native symbols of the provider.
+	SymbolMapper *SymbolMapper
+}
+
+// ProviderFactory instantiates all the components of a provider with the endpoints and the transports of the options.
+type ProviderFactory func(opts ProviderOptions) (*Provider, error)
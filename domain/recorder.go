@@ -0,0 +1,52 @@
+package domain
+
+// The sources of the recorded snapshots.
+const (
+	// SnapshotSourceOrderBook marks the snapshots the maintainers build the order books of.
+	SnapshotSourceOrderBook = "orderbook"
+	// SnapshotSourceSync marks the snapshots served by the sync API of the provider.
+	SnapshotSourceSync = "sync"
+)
+
+// A Recorder captures the raw traffic of a provider, so the order books can be replayed.
+// The methods are called concurrently by the connections and the sync APIs.
+type Recorder interface {
+	// Frame records the raw message received over the stream connection.
+	Frame(conn string, frame []byte)
+	// Disconnect records the loss of the stream connection, the messages in between are lost.
+	Disconnect(conn string)
+	// Snapshot records the snapshot or the error returned by the sync API.
+	Snapshot(source string, symbol *MarketSymbol, limit int, snapshot *OrderBookSnapshot, err error)
+}
+
+// NopRecorder records nothing, it is used if the capture is disabled.
+type NopRecorder struct{}
+
+func (NopRecorder) Frame(conn string, frame []byte) {}
+
+func (NopRecorder) Disconnect(conn string) {}
+
+func (NopRecorder) Snapshot(source string, symbol *MarketSymbol, limit int, snapshot *OrderBookSnapshot, err error) {
+}
+
+type recordedSyncAPI struct {
+	api      ProviderSyncAPI
+	recorder Recorder
+	source   string
+}
+
+// NewRecordedSyncAPI records the snapshots served by the api under the source.
+func NewRecordedSyncAPI(api ProviderSyncAPI, recorder Recorder, source string) ProviderSyncAPI {
+	if _, ok := recorder.(NopRecorder); ok {
+		return api
+	}
+
+	return &recordedSyncAPI{api: api, recorder: recorder, source: source}
+}
+
+func (r *recordedSyncAPI) OrderBookSnapshot(symbol *MarketSymbol, limit int) (*OrderBookSnapshot, error) {
+	snapshot, err := r.api.OrderBookSnapshot(symbol, limit)
+	r.recorder.Snapshot(r.source, symbol, limit, snapshot, err)
+
+	return snapshot, err
+}
@@ -0,0 +1,87 @@
+package domain
+
+import "sync"
+
+// A StreamConsumer is the channel of a subscriber of the upstream stream shared by several subscribers.
+// The messages are sent without blocking, so a slow subscriber doesn't stall the connection. If the buffer is full
+// the message is dropped and the consumer is interrupted: the marker message is delivered before the next one,
+// the subscriber rebuilds its state from it.
+type StreamConsumer[T any] struct {
+	ch          chan T
+	marker      T
+	mu          sync.Mutex
+	interrupted bool
+	closed      bool
+}
+
+// NewStreamConsumer creates the consumer with the buffer of the size. The marker is the message of the interruption,
+// e.g. the empty message.
+func NewStreamConsumer[T any](size int, marker T) *StreamConsumer[T] {
+	return &StreamConsumer[T]{
+		ch:     make(chan T, size),
+		marker: marker,
+	}
+}
+
+// Stream returns the channel of the consumer.
+func (c *StreamConsumer[T]) Stream() chan T {
+	return c.ch
+}
+
+// Send delivers the message to the consumer. It never blocks.
+func (c *StreamConsumer[T]) Send(msg T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || !c.flush() {
+		return
+	}
+
+	select {
+	case c.ch <- msg:
+	default:
+		c.interrupted = true
+	}
+}
+
+// Interrupt marks the messages lost, the marker is delivered before the next message.
+// The mark is kept until the marker fits in the buffer.
+func (c *StreamConsumer[T]) Interrupt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.interrupted = true
+	c.flush()
+}
+
+// Close closes the channel of the consumer, the messages sent after it are dropped.
+func (c *StreamConsumer[T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+	close(c.ch)
+}
+
+// flush delivers the pending marker. It returns false if it doesn't fit in the buffer.
+func (c *StreamConsumer[T]) flush() bool {
+	if !c.interrupted {
+		return true
+	}
+
+	select {
+	case c.ch <- c.marker:
+		c.interrupted = false
+		return true
+	default:
+		return false
+	}
+}
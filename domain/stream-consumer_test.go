@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamConsumer_Overflow(t *testing.T) {
+	c := NewStreamConsumer[[]byte](2, nil)
+
+	c.Send([]byte("1"))
+	c.Send([]byte("2"))
+	// the buffer is full, the message is dropped
+	c.Send([]byte("3"))
+
+	assert.Equal(t, "1", string(<-c.Stream()))
+	assert.Equal(t, "2", string(<-c.Stream()))
+
+	c.Send([]byte("4"))
+	assert.Nil(t, <-c.Stream(), "the consumer should be interrupted")
+	assert.Equal(t, "4", string(<-c.Stream()))
+}
+
+func TestStreamConsumer_Interrupt(t *testing.T) {
+	c := NewStreamConsumer[[]byte](2, nil)
+
+	c.Send([]byte("1"))
+	c.Send([]byte("2"))
+	// the buffer is full, the empty message is pending
+	c.Interrupt()
+
+	assert.Equal(t, "1", string(<-c.Stream()))
+	assert.Equal(t, "2", string(<-c.Stream()))
+
+	c.Send([]byte("3"))
+	assert.Nil(t, <-c.Stream(), "the interruption should not be lost on the full buffer")
+	assert.Equal(t, "3", string(<-c.Stream()))
+}
+
+func TestStreamConsumer_Close(t *testing.T) {
+	c := NewStreamConsumer[[]byte](1, nil)
+	c.Close()
+	c.Close()
+
+	// the messages after the close are dropped
+	c.Send([]byte("1"))
+	c.Interrupt()
+
+	_, ok := <-c.Stream()
+	assert.False(t, ok, "the stream should be closed")
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Minimal time between the attempts to load the list on the validation while it is not loaded.
+const symbolsRetryInterval = 30 * time.Second
+
+// SymbolCache keeps the list of the markets of a provider. The list is refreshed periodically
+// to pick up the new listings and the delistings.
+type SymbolCache struct {
+	provider        string
+	api             ProviderSymbolsAPI
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	symbols     map[string]bool
+	lastAttempt time.Time
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewSymbolCache(provider string, api ProviderSymbolsAPI, refreshInterval time.Duration) *SymbolCache {
+	return &SymbolCache{
+		provider:        provider,
+		api:             api,
+		refreshInterval: refreshInterval,
+		done:            make(chan struct{}),
+	}
+}
+
+// Validate returns ErrUnknownMarket if the market is not listed by the provider.
+// The markets are not rejected while the list is not loaded, e.g. when the provider api is unavailable.
+// The list is loaded at most once in symbolsRetryInterval, Run retries it in the background.
+func (c *SymbolCache) Validate(symbol *MarketSymbol) error {
+	c.mu.RLock()
+	symbols := c.symbols
+	c.mu.RUnlock()
+
+	if symbols == nil {
+		if !c.claimAttempt() {
+			return nil
+		}
+		if err := c.Refresh(); err != nil {
+			logger.Printf("failed to load the markets of %s, %s is not validated: %s", c.provider, symbol, err)
+			return nil
+		}
+
+		c.mu.RLock()
+		symbols = c.symbols
+		c.mu.RUnlock()
+	}
+
+	if !symbols[symbol.String()] {
+		return fmt.Errorf("%w: Provider=%s, Symbol=%s", ErrUnknownMarket, c.provider, symbol.String())
+	}
+
+	return nil
+}
+
+// claimAttempt reports whether the list can be loaded now. Only one of the concurrent callers gets true.
+func (c *SymbolCache) claimAttempt() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.lastAttempt) < symbolsRetryInterval {
+		return false
+	}
+	c.lastAttempt = time.Now()
+	return true
+}
+
+// Refresh replaces the list with the current markets of the provider. The list is kept if the request fails.
+func (c *SymbolCache) Refresh() error {
+	c.mu.Lock()
+	c.lastAttempt = time.Now()
+	c.mu.Unlock()
+
+	list, err := c.api.Symbols()
+	if err != nil {
+		return err
+	}
+
+	symbols := make(map[string]bool, len(list))
+	for _, symbol := range list {
+		symbols[symbol.String()] = true
+	}
+
+	c.mu.Lock()
+	c.symbols = symbols
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes the list until Stop is called.
+func (c *SymbolCache) Run() {
+	if err := c.Refresh(); err != nil {
+		logger.Printf("failed to load the markets of %s: %s", c.provider, err)
+	}
+
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.Refresh(); err != nil {
+				logger.Printf("failed to refresh the markets of %s: %s", c.provider, err)
+			}
+		}
+	}
+}
+
+func (c *SymbolCache) Stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSymbolsAPI struct {
+	symbols []*MarketSymbol
+	err     error
+	calls   int
+}
+
+func (f *fakeSymbolsAPI) Symbols() ([]*MarketSymbol, error) {
+	f.calls++
+	return f.symbols, f.err
+}
+
+func TestSymbolCache_Validate(t *testing.T) {
+	btc, _ := NewMarketSymbol("btc", "usdt")
+	eth, _ := NewMarketSymbol("eth", "usdt")
+	foo, _ := NewMarketSymbol("foo", "bar")
+
+	api := &fakeSymbolsAPI{symbols: []*MarketSymbol{btc}}
+	cache := NewSymbolCache("binance", api, time.Hour)
+
+	assert.NoError(t, cache.Validate(btc))
+	assert.ErrorIs(t, cache.Validate(foo), ErrUnknownMarket)
+	// the list is requested once
+	assert.Equal(t, 1, api.calls)
+
+	// the new listing is picked up by the refresh
+	assert.ErrorIs(t, cache.Validate(eth), ErrUnknownMarket)
+	api.symbols = []*MarketSymbol{btc, eth}
+	assert.NoError(t, cache.Refresh())
+	assert.NoError(t, cache.Validate(eth))
+
+	// the list is kept if the refresh fails
+	api.err = errors.New("unavailable")
+	assert.Error(t, cache.Refresh())
+	assert.NoError(t, cache.Validate(eth))
+}
+
+func TestSymbolCache_NotLoaded(t *testing.T) {
+	foo, _ := NewMarketSymbol("foo", "bar")
+
+	api := &fakeSymbolsAPI{err: errors.New("unavailable")}
+	cache := NewSymbolCache("binance", api, time.Hour)
+	assert.NoError(t, cache.Validate(foo), "markets should not be rejected while the list is not loaded")
+
+	// the failed load is not repeated on every validation
+	assert.NoError(t, cache.Validate(foo))
+	assert.Equal(t, 1, api.calls)
+
+	cache.lastAttempt = time.Now().Add(-symbolsRetryInterval)
+	api.err = nil
+	assert.ErrorIs(t, cache.Validate(foo), ErrUnknownMarket, "list should be loaded after the retry interval")
+	assert.Equal(t, 2, api.calls)
+}
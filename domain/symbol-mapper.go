@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/config"
+)
+
+// SymbolFormat describes how a venue writes the native symbols.
+type SymbolFormat struct {
+	Separator string
+	// Suffix is appended to the symbol, e.g. M of the kucoin perpetual contracts.
+	Suffix    string
+	Lowercase bool
+}
+
+// SymbolMapper translates between the canonical market symbols and the native symbols of a venue.
+type SymbolMapper struct {
+	format SymbolFormat
+	// The native codes of the assets keyed by the canonical codes, and the reverse.
+	native    map[string]string
+	canonical map[string]string
+}
+
+// NewSymbolMapper creates the mapper of the format. The aliases are the native codes of the assets
+// keyed by the canonical codes, only the codes which differ are listed.
+func NewSymbolMapper(format SymbolFormat, aliases map[string]string) *SymbolMapper {
+	m := &SymbolMapper{
+		format:    format,
+		native:    make(map[string]string, len(aliases)),
+		canonical: make(map[string]string, len(aliases)),
+	}
+
+	for canonical, native := range aliases {
+		canonical, native = strings.ToLower(canonical), strings.ToLower(native)
+		m.native[canonical] = native
+		m.canonical[native] = canonical
+	}
+
+	return m
+}
+
+// NewProviderSymbolMapper creates the mapper with the configured aliases of the provider.
+func NewProviderSymbolMapper(provider string, format SymbolFormat) *SymbolMapper {
+	return NewSymbolMapper(format, config.NativeAssets[provider])
+}
+
+// Native returns the native symbol of the market, e.g. BTC-USDT for btc_usdt.
+func (m *SymbolMapper) Native(symbol *MarketSymbol) string {
+	return m.NativeAsset(symbol.BaseAsset) + m.format.Separator + m.NativeAsset(symbol.QuoteAsset) + m.format.Suffix
+}
+
+// NativeAsset returns the native code of the asset in the case of the format.
+func (m *SymbolMapper) NativeAsset(asset string) string {
+	asset = strings.ToLower(asset)
+	if native, ok := m.native[asset]; ok {
+		asset = native
+	}
+
+	if m.format.Lowercase {
+		return asset
+	}
+	return strings.ToUpper(asset)
+}
+
+// Canonical returns the market of the native codes of the assets.
+func (m *SymbolMapper) Canonical(base, quote string) (*MarketSymbol, error) {
+	return NewMarketSymbol(m.canonicalAsset(base), m.canonicalAsset(quote))
+}
+
+func (m *SymbolMapper) canonicalAsset(asset string) string {
+	asset = strings.ToLower(asset)
+	if canonical, ok := m.canonical[asset]; ok {
+		return canonical
+	}
+
+	return asset
+}
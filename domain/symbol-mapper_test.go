@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolMapper_Native(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	tests := []struct {
+		name     string
+		format   domain.SymbolFormat
+		aliases  map[string]string
+		expected string
+	}{
+		{"Concatenated", domain.SymbolFormat{}, nil, "BTCUSDT"},
+		{"Separator", domain.SymbolFormat{Separator: "-"}, nil, "BTC-USDT"},
+		{"Lowercase", domain.SymbolFormat{Lowercase: true}, nil, "btcusdt"},
+		{"AliasAndSuffix", domain.SymbolFormat{Suffix: "M"}, map[string]string{"BTC": "XBT"}, "XBTUSDTM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := domain.NewSymbolMapper(tt.format, tt.aliases)
+			assert.Equal(t, tt.expected, mapper.Native(symbol), "Native() result should be equal to expected")
+		})
+	}
+}
+
+func TestSymbolMapper_Canonical(t *testing.T) {
+	mapper := domain.NewSymbolMapper(domain.SymbolFormat{Separator: "-"}, map[string]string{"bsv": "bchsv"})
+
+	symbol, err := mapper.Canonical("BCHSV", "USDT")
+
+	assert.NoError(t, err, "Canonical() should not return an error")
+	assert.Equal(t, "bsv_usdt", symbol.String(), "The native code should be translated back")
+}
+
+func TestNewMarketSymbol_Alias(t *testing.T) {
+	symbol, err := domain.NewMarketSymbolFromString("XBT_USDT")
+
+	assert.NoError(t, err, "NewMarketSymbolFromString() should not return an error")
+	assert.Equal(t, "btc_usdt", symbol.String(), "The alternative code should be replaced by the canonical one")
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.31.0
 // 	protoc        v4.25.2
 // source: cryptobridge.proto
 
@@ -137,10 +137,11 @@ type GetOrderBookSnapshotResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Source    OrderBookSource   `protobuf:"varint,1,opt,name=source,proto3,enum=CryptoBridge.OrderBookSource" json:"source,omitempty"`
-	LastUpdTs int64             `protobuf:"varint,2,opt,name=lastUpdTs,proto3" json:"lastUpdTs,omitempty"`
-	Bids      []*OrderBookLevel `protobuf:"bytes,3,rep,name=bids,proto3" json:"bids,omitempty"`
-	Asks      []*OrderBookLevel `protobuf:"bytes,4,rep,name=asks,proto3" json:"asks,omitempty"`
+	Source       OrderBookSource   `protobuf:"varint,1,opt,name=source,proto3,enum=CryptoBridge.OrderBookSource" json:"source,omitempty"`
+	LastUpdTs    int64             `protobuf:"varint,2,opt,name=lastUpdTs,proto3" json:"lastUpdTs,omitempty"`
+	Bids         []*OrderBookLevel `protobuf:"bytes,3,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks         []*OrderBookLevel `protobuf:"bytes,4,rep,name=asks,proto3" json:"asks,omitempty"`
+	NativeSymbol string            `protobuf:"bytes,5,opt,name=nativeSymbol,proto3" json:"nativeSymbol,omitempty"`
 }
 
 func (x *GetOrderBookSnapshotResponse) Reset() {
@@ -203,6 +204,13 @@ func (x *GetOrderBookSnapshotResponse) GetAsks() []*OrderBookLevel {
 	return nil
 }
 
+func (x *GetOrderBookSnapshotResponse) GetNativeSymbol() string {
+	if x != nil {
+		return x.NativeSymbol
+	}
+	return ""
+}
+
 type OrderBookLevel struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -258,6 +266,258 @@ func (x *OrderBookLevel) GetQty() string {
 	return ""
 }
 
+type GetMarkPriceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Market   string `protobuf:"bytes,2,opt,name=market,proto3" json:"market,omitempty"`
+}
+
+func (x *GetMarkPriceRequest) Reset() {
+	*x = GetMarkPriceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cryptobridge_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetMarkPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMarkPriceRequest) ProtoMessage() {}
+
+func (x *GetMarkPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cryptobridge_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMarkPriceRequest.ProtoReflect.Descriptor instead.
+func (*GetMarkPriceRequest) Descriptor() ([]byte, []int) {
+	return file_cryptobridge_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetMarkPriceRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GetMarkPriceRequest) GetMarket() string {
+	if x != nil {
+		return x.Market
+	}
+	return ""
+}
+
+type GetMarkPriceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MarkPrice    string `protobuf:"bytes,1,opt,name=markPrice,proto3" json:"markPrice,omitempty"`
+	IndexPrice   string `protobuf:"bytes,2,opt,name=indexPrice,proto3" json:"indexPrice,omitempty"`
+	Ts           int64  `protobuf:"varint,3,opt,name=ts,proto3" json:"ts,omitempty"`
+	NativeSymbol string `protobuf:"bytes,4,opt,name=nativeSymbol,proto3" json:"nativeSymbol,omitempty"`
+}
+
+func (x *GetMarkPriceResponse) Reset() {
+	*x = GetMarkPriceResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cryptobridge_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetMarkPriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMarkPriceResponse) ProtoMessage() {}
+
+func (x *GetMarkPriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cryptobridge_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMarkPriceResponse.ProtoReflect.Descriptor instead.
+func (*GetMarkPriceResponse) Descriptor() ([]byte, []int) {
+	return file_cryptobridge_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMarkPriceResponse) GetMarkPrice() string {
+	if x != nil {
+		return x.MarkPrice
+	}
+	return ""
+}
+
+func (x *GetMarkPriceResponse) GetIndexPrice() string {
+	if x != nil {
+		return x.IndexPrice
+	}
+	return ""
+}
+
+func (x *GetMarkPriceResponse) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *GetMarkPriceResponse) GetNativeSymbol() string {
+	if x != nil {
+		return x.NativeSymbol
+	}
+	return ""
+}
+
+type GetFundingRateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Market   string `protobuf:"bytes,2,opt,name=market,proto3" json:"market,omitempty"`
+}
+
+func (x *GetFundingRateRequest) Reset() {
+	*x = GetFundingRateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cryptobridge_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetFundingRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFundingRateRequest) ProtoMessage() {}
+
+func (x *GetFundingRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cryptobridge_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFundingRateRequest.ProtoReflect.Descriptor instead.
+func (*GetFundingRateRequest) Descriptor() ([]byte, []int) {
+	return file_cryptobridge_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetFundingRateRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GetFundingRateRequest) GetMarket() string {
+	if x != nil {
+		return x.Market
+	}
+	return ""
+}
+
+type GetFundingRateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FundingRate   string `protobuf:"bytes,1,opt,name=fundingRate,proto3" json:"fundingRate,omitempty"`
+	NextFundingTs int64  `protobuf:"varint,2,opt,name=nextFundingTs,proto3" json:"nextFundingTs,omitempty"`
+	Ts            int64  `protobuf:"varint,3,opt,name=ts,proto3" json:"ts,omitempty"`
+	NativeSymbol  string `protobuf:"bytes,4,opt,name=nativeSymbol,proto3" json:"nativeSymbol,omitempty"`
+}
+
+func (x *GetFundingRateResponse) Reset() {
+	*x = GetFundingRateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cryptobridge_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetFundingRateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFundingRateResponse) ProtoMessage() {}
+
+func (x *GetFundingRateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cryptobridge_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFundingRateResponse.ProtoReflect.Descriptor instead.
+func (*GetFundingRateResponse) Descriptor() ([]byte, []int) {
+	return file_cryptobridge_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetFundingRateResponse) GetFundingRate() string {
+	if x != nil {
+		return x.FundingRate
+	}
+	return ""
+}
+
+func (x *GetFundingRateResponse) GetNextFundingTs() int64 {
+	if x != nil {
+		return x.NextFundingTs
+	}
+	return 0
+}
+
+func (x *GetFundingRateResponse) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *GetFundingRateResponse) GetNativeSymbol() string {
+	if x != nil {
+		return x.NativeSymbol
+	}
+	return ""
+}
+
 var File_cryptobridge_proto protoreflect.FileDescriptor
 
 var file_cryptobridge_proto_rawDesc = []byte{
@@ -270,7 +530,7 @@ var file_cryptobridge_proto_rawDesc = []byte{
 	0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d,
 	0x61, 0x72, 0x6b, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74,
 	0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74,
-	0x68, 0x22, 0xd7, 0x01, 0x0a, 0x1c, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f,
+	0x68, 0x22, 0xfb, 0x01, 0x0a, 0x1c, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f,
 	0x6f, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
 	0x73, 0x65, 0x12, 0x35, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67,
@@ -283,25 +543,66 @@ var file_cryptobridge_proto_rawDesc = []byte{
 	0x76, 0x65, 0x6c, 0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12, 0x30, 0x0a, 0x04, 0x61, 0x73, 0x6b,
 	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f,
 	0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b,
-	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x22, 0x38, 0x0a, 0x0e, 0x4f,
-	0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x14, 0x0a,
-	0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72,
-	0x69, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x71, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x71, 0x74, 0x79, 0x2a, 0x40, 0x0a, 0x0f, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f,
-	0x6f, 0x6b, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e, 0x6b, 0x6e,
-	0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65,
-	0x72, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x4f, 0x72, 0x64, 0x65,
-	0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x10, 0x02, 0x32, 0x84, 0x01, 0x0a, 0x11, 0x4d, 0x61, 0x72, 0x6b,
-	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6f, 0x0a,
-	0x14, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x53, 0x6e, 0x61,
-	0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x29, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72,
-	0x69, 0x64, 0x67, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f,
-	0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x2a, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e,
-	0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x53, 0x6e, 0x61, 0x70,
-	0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x10,
-	0x5a, 0x0e, 0x2e, 0x2f, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6e,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x22,
+	0x38, 0x0a, 0x0e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x71, 0x74, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x71, 0x74, 0x79, 0x22, 0x49, 0x0a, 0x13, 0x47, 0x65, 0x74,
+	0x4d, 0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x61,
+	0x72, 0x6b, 0x65, 0x74, 0x22, 0x88, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x72, 0x6b,
+	0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x6d, 0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6d, 0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6e,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x22,
+	0x4b, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x46, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76,
+	0x69, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76,
+	0x69, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x22, 0x94, 0x01, 0x0a,
+	0x16, 0x47, 0x65, 0x74, 0x46, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x75, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x75,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x46, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x46, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x73, 0x12,
+	0x0e, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73, 0x12,
+	0x22, 0x0a, 0x0c, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x53, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x2a, 0x40, 0x0a, 0x0f, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x10,
+	0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42,
+	0x6f, 0x6f, 0x6b, 0x10, 0x02, 0x32, 0xbc, 0x02, 0x0a, 0x11, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6f, 0x0a, 0x14, 0x47,
+	0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x29, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a,
+	0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x47, 0x65,
+	0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x0c,
+	0x47, 0x65, 0x74, 0x4d, 0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x21, 0x2e, 0x43,
+	0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x4d,
+	0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x22, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x47,
+	0x65, 0x74, 0x4d, 0x61, 0x72, 0x6b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5d, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x46, 0x75, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x12, 0x23, 0x2e, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f,
+	0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x52, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x43,
+	0x72, 0x79, 0x70, 0x74, 0x6f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x46,
+	0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x42, 0x10, 0x5a, 0x0e, 0x2e, 0x2f, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f,
+	0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -317,21 +618,29 @@ func file_cryptobridge_proto_rawDescGZIP() []byte {
 }
 
 var file_cryptobridge_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_cryptobridge_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_cryptobridge_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_cryptobridge_proto_goTypes = []interface{}{
 	(OrderBookSource)(0),                 // 0: CryptoBridge.OrderBookSource
 	(*GetOrderBookSnapshotRequest)(nil),  // 1: CryptoBridge.GetOrderBookSnapshotRequest
 	(*GetOrderBookSnapshotResponse)(nil), // 2: CryptoBridge.GetOrderBookSnapshotResponse
 	(*OrderBookLevel)(nil),               // 3: CryptoBridge.OrderBookLevel
+	(*GetMarkPriceRequest)(nil),          // 4: CryptoBridge.GetMarkPriceRequest
+	(*GetMarkPriceResponse)(nil),         // 5: CryptoBridge.GetMarkPriceResponse
+	(*GetFundingRateRequest)(nil),        // 6: CryptoBridge.GetFundingRateRequest
+	(*GetFundingRateResponse)(nil),       // 7: CryptoBridge.GetFundingRateResponse
 }
 var file_cryptobridge_proto_depIdxs = []int32{
 	0, // 0: CryptoBridge.GetOrderBookSnapshotResponse.source:type_name -> CryptoBridge.OrderBookSource
 	3, // 1: CryptoBridge.GetOrderBookSnapshotResponse.bids:type_name -> CryptoBridge.OrderBookLevel
 	3, // 2: CryptoBridge.GetOrderBookSnapshotResponse.asks:type_name -> CryptoBridge.OrderBookLevel
 	1, // 3: CryptoBridge.MarketDataService.GetOrderBookSnapshot:input_type -> CryptoBridge.GetOrderBookSnapshotRequest
-	2, // 4: CryptoBridge.MarketDataService.GetOrderBookSnapshot:output_type -> CryptoBridge.GetOrderBookSnapshotResponse
-	4, // [4:5] is the sub-list for method output_type
-	3, // [3:4] is the sub-list for method input_type
+	4, // 4: CryptoBridge.MarketDataService.GetMarkPrice:input_type -> CryptoBridge.GetMarkPriceRequest
+	6, // 5: CryptoBridge.MarketDataService.GetFundingRate:input_type -> CryptoBridge.GetFundingRateRequest
+	2, // 6: CryptoBridge.MarketDataService.GetOrderBookSnapshot:output_type -> CryptoBridge.GetOrderBookSnapshotResponse
+	5, // 7: CryptoBridge.MarketDataService.GetMarkPrice:output_type -> CryptoBridge.GetMarkPriceResponse
+	7, // 8: CryptoBridge.MarketDataService.GetFundingRate:output_type -> CryptoBridge.GetFundingRateResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
 	3, // [3:3] is the sub-list for extension type_name
 	3, // [3:3] is the sub-list for extension extendee
 	0, // [0:3] is the sub-list for field type_name
@@ -379,6 +688,54 @@ func file_cryptobridge_proto_init() {
 				return nil
 			}
 		}
+		file_cryptobridge_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMarkPriceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cryptobridge_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMarkPriceResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cryptobridge_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetFundingRateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cryptobridge_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetFundingRateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -386,7 +743,7 @@ func file_cryptobridge_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_cryptobridge_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   3,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MarketDataServiceClient interface {
 	GetOrderBookSnapshot(ctx context.Context, in *GetOrderBookSnapshotRequest, opts ...grpc.CallOption) (*GetOrderBookSnapshotResponse, error)
+	GetMarkPrice(ctx context.Context, in *GetMarkPriceRequest, opts ...grpc.CallOption) (*GetMarkPriceResponse, error)
+	GetFundingRate(ctx context.Context, in *GetFundingRateRequest, opts ...grpc.CallOption) (*GetFundingRateResponse, error)
 }
 
 type marketDataServiceClient struct {
@@ -42,11 +44,31 @@ func (c *marketDataServiceClient) GetOrderBookSnapshot(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *marketDataServiceClient) GetMarkPrice(ctx context.Context, in *GetMarkPriceRequest, opts ...grpc.CallOption) (*GetMarkPriceResponse, error) {
+	out := new(GetMarkPriceResponse)
+	err := c.cc.Invoke(ctx, "/CryptoBridge.MarketDataService/GetMarkPrice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) GetFundingRate(ctx context.Context, in *GetFundingRateRequest, opts ...grpc.CallOption) (*GetFundingRateResponse, error) {
+	out := new(GetFundingRateResponse)
+	err := c.cc.Invoke(ctx, "/CryptoBridge.MarketDataService/GetFundingRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MarketDataServiceServer is the server API for MarketDataService service.
 // All implementations must embed UnimplementedMarketDataServiceServer
 // for forward compatibility
 type MarketDataServiceServer interface {
 	GetOrderBookSnapshot(context.Context, *GetOrderBookSnapshotRequest) (*GetOrderBookSnapshotResponse, error)
+	GetMarkPrice(context.Context, *GetMarkPriceRequest) (*GetMarkPriceResponse, error)
+	GetFundingRate(context.Context, *GetFundingRateRequest) (*GetFundingRateResponse, error)
 	mustEmbedUnimplementedMarketDataServiceServer()
 }
 
@@ -57,6 +79,12 @@ type UnimplementedMarketDataServiceServer struct {
 func (UnimplementedMarketDataServiceServer) GetOrderBookSnapshot(context.Context, *GetOrderBookSnapshotRequest) (*GetOrderBookSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOrderBookSnapshot not implemented")
 }
+func (UnimplementedMarketDataServiceServer) GetMarkPrice(context.Context, *GetMarkPriceRequest) (*GetMarkPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMarkPrice not implemented")
+}
+func (UnimplementedMarketDataServiceServer) GetFundingRate(context.Context, *GetFundingRateRequest) (*GetFundingRateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFundingRate not implemented")
+}
 func (UnimplementedMarketDataServiceServer) mustEmbedUnimplementedMarketDataServiceServer() {}
 
 // UnsafeMarketDataServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +116,42 @@ func _MarketDataService_GetOrderBookSnapshot_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MarketDataService_GetMarkPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMarkPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetMarkPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CryptoBridge.MarketDataService/GetMarkPrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetMarkPrice(ctx, req.(*GetMarkPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_GetFundingRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFundingRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetFundingRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CryptoBridge.MarketDataService/GetFundingRate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetFundingRate(ctx, req.(*GetFundingRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MarketDataService_ServiceDesc is the grpc.ServiceDesc for MarketDataService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +163,14 @@ var MarketDataService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOrderBookSnapshot",
 			Handler:    _MarketDataService_GetOrderBookSnapshot_Handler,
 		},
+		{
+			MethodName: "GetMarkPrice",
+			Handler:    _MarketDataService_GetMarkPrice_Handler,
+		},
+		{
+			MethodName: "GetFundingRate",
+			Handler:    _MarketDataService_GetFundingRate_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cryptobridge.proto",
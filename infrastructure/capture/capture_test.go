@@ -0,0 +1,242 @@
+package capture
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRecorder(t *testing.T) *Recorder {
+	recorder, err := NewRecorder(t.TempDir(), "binance")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return recorder
+}
+
+func TestRecorder_ReadAll(t *testing.T) {
+	recorder := newTestRecorder(t)
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	recorder.Frame("1", []byte(`{"stream":"btcusdt@depth","data":{"U":1,"u":2}}`))
+	recorder.Snapshot(domain.SnapshotSourceOrderBook, btc, 100, &domain.OrderBookSnapshot{LastUpdateId: 2, Bids: [][]string{{"1.0", "2"}}}, nil)
+	recorder.Snapshot(domain.SnapshotSourceSync, btc, 10, nil, errors.New("rate limited"))
+	recorder.Disconnect("1")
+	assert.NoError(t, recorder.Close())
+
+	// the records after the close are dropped
+	recorder.Frame("1", []byte(`{}`))
+
+	records, err := ReadAll(recorder.Path())
+	assert.NoError(t, err)
+	if !assert.Len(t, records, 4) {
+		return
+	}
+
+	assert.Equal(t, KindFrame, records[0].Kind)
+	assert.Equal(t, "1", records[0].Conn)
+	assert.Equal(t, `{"stream":"btcusdt@depth","data":{"U":1,"u":2}}`, records[0].Frame)
+
+	assert.Equal(t, KindSnapshot, records[1].Kind)
+	assert.Equal(t, "btc_usdt", records[1].Symbol)
+	assert.Equal(t, 100, records[1].Limit)
+	assert.Equal(t, int64(2), records[1].Snapshot.LastUpdateId)
+
+	assert.Equal(t, "rate limited", records[2].Error)
+	assert.Nil(t, records[2].Snapshot)
+
+	assert.Equal(t, KindDisconnect, records[3].Kind)
+
+	for i := 1; i < len(records); i++ {
+		assert.LessOrEqual(t, records[i-1].Time, records[i].Time)
+	}
+}
+
+func TestReader_TruncatedFile(t *testing.T) {
+	recorder := newTestRecorder(t)
+	recorder.Frame("1", []byte(`{"a":1}`))
+	recorder.Frame("1", []byte(`{"a":2}`))
+
+	recorder.mu.Lock()
+	assert.NoError(t, recorder.flush())
+	recorder.mu.Unlock()
+
+	// the process is killed before the close, the file has no gzip footer
+	records, err := ReadAll(recorder.Path())
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	recorder.Close()
+}
+
+func TestReader_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binance-1.ndjson.gz")
+	file, _ := os.Create(path)
+	gz := gzip.NewWriter(file)
+	gz.Write([]byte("not a record\n"))
+	gz.Close()
+	file.Close()
+
+	_, err := ReadAll(path)
+	assert.Error(t, err)
+
+	_, err = ReadAll(filepath.Join(t.TempDir(), "missing.ndjson.gz"))
+	assert.Error(t, err)
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"binance-20240102T000000.ndjson.gz", "binance-20240101T000000.ndjson.gz", "binance-futures-20240101T000000.ndjson.gz", "kucoin-20240101T000000.ndjson.gz"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+	}
+
+	files, err := Files(dir, "binance")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "binance-20240101T000000.ndjson.gz"),
+		filepath.Join(dir, "binance-20240102T000000.ndjson.gz"),
+	}, files)
+}
+
+// routeTopic routes the frames of the form topic:payload.
+func routeTopic(frame []byte) (string, []byte, bool) {
+	for i, c := range frame {
+		if c == ':' {
+			return string(frame[:i]), frame[i+1:], true
+		}
+	}
+	return "", nil, false
+}
+
+func receive(t *testing.T, sub *domain.Subscription[[]byte]) string {
+	select {
+	case msg := <-sub.Stream:
+		return string(msg)
+	case <-time.After(time.Second):
+		t.Fatal("no message is replayed")
+		return ""
+	}
+}
+
+func TestPlayer_Subscribe(t *testing.T) {
+	player := NewPlayer([]*Record{
+		{Time: 1, Kind: KindFrame, Conn: "1", Frame: "a:1"},
+		{Time: 2, Kind: KindFrame, Conn: "1", Frame: "ack"},
+		{Time: 3, Kind: KindFrame, Conn: "2", Frame: "b:1"},
+		{Time: 4, Kind: KindDisconnect, Conn: "1"},
+		{Time: 5, Kind: KindFrame, Conn: "3", Frame: "a:2"},
+	}, 0, routeTopic)
+	defer player.Close()
+
+	a := player.Subscribe("a")
+	assert.Equal(t, "1", receive(t, a))
+	assert.Equal(t, "", receive(t, a), "the disconnect of the connection of the topic is the empty message")
+	assert.Equal(t, "2", receive(t, a))
+
+	// the topic is replayed from the start regardless of the time of the subscription
+	b := player.Subscribe("b")
+	assert.Equal(t, "1", receive(t, b))
+
+	player.Wait()
+	select {
+	case msg := <-b.Stream:
+		t.Fatalf("unexpected message %q", msg)
+	default:
+	}
+
+	a.Unsubscribe()
+	_, ok := <-a.Stream
+	assert.False(t, ok, "the stream should be closed on unsubscribe")
+}
+
+func TestPlayer_Speed(t *testing.T) {
+	start := time.Now().UnixNano()
+	player := NewPlayer([]*Record{
+		{Time: start, Kind: KindFrame, Frame: "a:1"},
+		{Time: start + int64(400*time.Millisecond), Kind: KindFrame, Frame: "a:2"},
+	}, 4, routeTopic)
+	defer player.Close()
+
+	began := time.Now()
+	sub := player.Subscribe("a")
+	receive(t, sub)
+	receive(t, sub)
+
+	elapsed := time.Since(began)
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+	assert.Less(t, elapsed, 400*time.Millisecond)
+}
+
+func TestPlayer_Close(t *testing.T) {
+	player := NewPlayer([]*Record{
+		{Time: 0, Kind: KindFrame, Frame: "a:1"},
+		{Time: int64(time.Hour), Kind: KindFrame, Frame: "a:2"},
+	}, 1, routeTopic)
+
+	sub := player.Subscribe("a")
+	receive(t, sub)
+
+	closed := make(chan struct{})
+	go func() {
+		player.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("close should stop the replay")
+	}
+
+	_, ok := <-sub.Stream
+	assert.False(t, ok, "the stream should be closed on close")
+	sub.Unsubscribe()
+
+	// the subscription to the closed player gets the closed stream
+	_, ok = <-player.Subscribe("a").Stream
+	assert.False(t, ok, "the stream should be closed")
+}
+
+func TestPlayer_SyncAPI(t *testing.T) {
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+	player := NewPlayer([]*Record{
+		{Kind: KindSnapshot, Source: domain.SnapshotSourceOrderBook, Symbol: "btc_usdt", Snapshot: &domain.OrderBookSnapshot{LastUpdateId: 1}},
+		{Kind: KindSnapshot, Source: domain.SnapshotSourceSync, Symbol: "btc_usdt", Snapshot: &domain.OrderBookSnapshot{LastUpdateId: 2}},
+		{Kind: KindSnapshot, Source: domain.SnapshotSourceOrderBook, Symbol: "btc_usdt", Error: "timeout"},
+		{Kind: KindSnapshot, Source: domain.SnapshotSourceOrderBook, Symbol: "btc_usdt", Snapshot: &domain.OrderBookSnapshot{LastUpdateId: 3}},
+	}, 0, routeTopic)
+	defer player.Close()
+
+	api := player.SyncAPI(domain.SnapshotSourceOrderBook)
+
+	snapshot, err := api.OrderBookSnapshot(btc, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), snapshot.LastUpdateId)
+
+	_, err = api.OrderBookSnapshot(btc, 100)
+	assert.EqualError(t, err, "timeout")
+
+	snapshot, err = api.OrderBookSnapshot(btc, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), snapshot.LastUpdateId)
+
+	_, err = api.OrderBookSnapshot(btc, 100)
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+
+	snapshot, err = player.SyncAPI(domain.SnapshotSourceSync).OrderBookSnapshot(btc, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), snapshot.LastUpdateId)
+}
+
+func TestReader_EOF(t *testing.T) {
+	reader := NewReader()
+	_, err := reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,270 @@
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+var ErrNoSnapshot = errors.New("no more snapshots in the capture")
+
+// RouteFunc returns the topic of the frame and the payload the stream client sends to the subscribers of the topic.
+// The frames the client doesn't send to the subscribers, e.g. the acks and the pongs, are skipped.
+type RouteFunc func(frame []byte) (topic string, payload []byte, ok bool)
+
+type event struct {
+	time    int64
+	conn    string
+	topic   string
+	payload []byte
+	// the disconnects have no topic
+	disconnect bool
+}
+
+// Player replays a capture in place of the stream client and the sync API of a provider.
+//
+// Every subscription replays the frames of its topic from the start of the capture, so the order books don't depend
+// on the time of the subscription. The frames are paced by the receive times divided by the speed, the zero speed
+// replays them without the delays. Nothing is dropped: the player waits for the subscriber if it falls behind.
+// The disconnect of the connection that carried the topic is replayed as the empty message, the way the stream clients
+// notify the subscribers of the lost messages.
+type Player struct {
+	events []event
+	// the time of the first record, the pacing starts from it
+	start int64
+	speed float64
+
+	mu sync.Mutex
+	// the snapshots which are not served yet, keyed by the source and the symbol
+	snapshots map[string][]*Record
+	// the streams which are not unsubscribed yet, they are closed by Close
+	streams map[*replayStream]struct{}
+	closed  bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+	playing   sync.WaitGroup
+}
+
+// replayStream is the stream of a subscription, it is closed either by Unsubscribe or by Close.
+type replayStream struct {
+	ch        chan []byte
+	closeOnce sync.Once
+}
+
+func (s *replayStream) close() {
+	s.closeOnce.Do(func() { close(s.ch) })
+}
+
+func NewPlayer(records []*Record, speed float64, route RouteFunc) *Player {
+	p := &Player{
+		speed:     speed,
+		snapshots: make(map[string][]*Record),
+		streams:   make(map[*replayStream]struct{}),
+		done:      make(chan struct{}),
+	}
+	if len(records) > 0 {
+		p.start = records[0].Time
+	}
+
+	for _, record := range records {
+		switch record.Kind {
+		case KindFrame:
+			topic, payload, ok := route([]byte(record.Frame))
+			if !ok {
+				continue
+			}
+			p.events = append(p.events, event{time: record.Time, conn: record.Conn, topic: topic, payload: payload})
+		case KindDisconnect:
+			p.events = append(p.events, event{time: record.Time, conn: record.Conn, disconnect: true})
+		case KindSnapshot:
+			key := snapshotKey(record.Source, record.Symbol)
+			p.snapshots[key] = append(p.snapshots[key], record)
+		}
+	}
+
+	return p
+}
+
+// Connect does nothing, the frames are replayed on the subscription.
+func (p *Player) Connect() error {
+	return nil
+}
+
+// Close stops the replay of all the subscriptions and closes their streams.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.closeOnce.Do(func() { close(p.done) })
+	p.playing.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for stream := range p.streams {
+		stream.close()
+	}
+	p.streams = make(map[*replayStream]struct{})
+
+	return nil
+}
+
+// Wait waits for all the subscriptions to replay the capture to the end.
+func (p *Player) Wait() {
+	p.playing.Wait()
+}
+
+// Subscribe replays the frames of the topic. Unsubscribe stops the replay and closes the stream.
+// The stream of the closed player is closed.
+func (p *Player) Subscribe(topic string) *domain.Subscription[[]byte] {
+	stream := &replayStream{ch: make(chan []byte, 2048)}
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		stream.close()
+		close(finished)
+	} else {
+		p.streams[stream] = struct{}{}
+		p.playing.Add(1)
+		p.mu.Unlock()
+
+		go func() {
+			defer p.playing.Done()
+			defer close(finished)
+
+			p.play(topic, stream.ch, stop)
+		}()
+	}
+
+	var unsubscribeOnce sync.Once
+	return &domain.Subscription[[]byte]{
+		Stream: stream.ch,
+		Topic:  topic,
+		Unsubscribe: func() {
+			unsubscribeOnce.Do(func() {
+				close(stop)
+				<-finished
+				stream.close()
+
+				p.mu.Lock()
+				delete(p.streams, stream)
+				p.mu.Unlock()
+			})
+		},
+	}
+}
+
+// SyncAPI serves the snapshots of the source in the order of the capture.
+// The recorded errors are returned as is, ErrNoSnapshot is returned after the last snapshot of the symbol.
+func (p *Player) SyncAPI(source string) domain.ProviderSyncAPI {
+	return &replaySyncAPI{player: p, source: source}
+}
+
+func (p *Player) play(topic string, ch chan<- []byte, stop <-chan struct{}) {
+	started := time.Now()
+	// the connection of the last frame of the topic
+	conn := ""
+
+	for _, e := range p.events {
+		var msg []byte
+		switch {
+		case e.disconnect:
+			if e.conn != conn {
+				continue
+			}
+			conn = ""
+			msg = []byte{}
+		case e.topic == topic:
+			conn = e.conn
+			msg = e.payload
+		default:
+			continue
+		}
+
+		if !p.wait(started, e.time, stop) {
+			return
+		}
+
+		select {
+		case ch <- msg:
+		case <-stop:
+			return
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// wait sleeps until the time of the event comes in the replay. It returns false if the replay is stopped.
+func (p *Player) wait(started time.Time, at int64, stop <-chan struct{}) bool {
+	var delay time.Duration
+	if p.speed > 0 {
+		delay = time.Until(started.Add(time.Duration(float64(at-p.start) / p.speed)))
+	}
+
+	if delay <= 0 {
+		select {
+		case <-stop:
+			return false
+		case <-p.done:
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	case <-p.done:
+		return false
+	}
+}
+
+func (p *Player) nextSnapshot(source string, symbol *domain.MarketSymbol) (*Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := snapshotKey(source, symbol.String())
+	records := p.snapshots[key]
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: source=%s, symbol=%s", ErrNoSnapshot, source, symbol)
+	}
+	p.snapshots[key] = records[1:]
+
+	return records[0], nil
+}
+
+type replaySyncAPI struct {
+	player *Player
+	source string
+}
+
+func (api *replaySyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	record, err := api.player.nextSnapshot(api.source, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if record.Error != "" {
+		return nil, errors.New(record.Error)
+	}
+
+	return record.Snapshot, nil
+}
+
+func snapshotKey(source, symbol string) string {
+	return source + "|" + symbol
+}
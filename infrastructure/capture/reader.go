@@ -0,0 +1,120 @@
+package capture
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Reader reads the records of the capture files one after another.
+// The files of the killed processes end with a partial block or line, the reader stops at it.
+type Reader struct {
+	paths []string
+	file  *os.File
+	lines *bufio.Reader
+}
+
+func NewReader(paths ...string) *Reader {
+	return &Reader{paths: paths}
+}
+
+// Next returns the next record or io.EOF after the last one.
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.lines == nil {
+			if len(r.paths) == 0 {
+				return nil, io.EOF
+			}
+			if err := r.open(r.paths[0]); err != nil {
+				return nil, err
+			}
+			r.paths = r.paths[1:]
+		}
+
+		line, err := r.lines.ReadBytes('\n')
+		if err == nil {
+			record := &Record{}
+			if err := json.Unmarshal(line, record); err != nil {
+				return nil, fmt.Errorf("invalid record in %s: %w", r.file.Name(), err)
+			}
+			return record, nil
+		}
+
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("failed to read %s: %w", r.file.Name(), err)
+		}
+		if len(line) > 0 || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.Printf("%s is truncated, the rest of the file is skipped", r.file.Name())
+		}
+		r.closeFile()
+	}
+}
+
+func (r *Reader) Close() error {
+	r.paths = nil
+	return r.closeFile()
+}
+
+func (r *Reader) open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the capture file: %w", err)
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open the capture file %s: %w", path, err)
+	}
+
+	r.file = file
+	r.lines = bufio.NewReader(gz)
+	return nil
+}
+
+func (r *Reader) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	r.lines = nil
+	return err
+}
+
+// Files returns the capture files of the provider in the dir in the order of the start times.
+func Files(dir, provider string) ([]string, error) {
+	// the start time separates the files of binance from the ones of binance-futures
+	paths, err := filepath.Glob(filepath.Join(dir, provider+"-[0-9]*.ndjson.gz"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// ReadAll reads all the records of the files.
+func ReadAll(paths ...string) ([]*Record, error) {
+	reader := NewReader(paths...)
+	defer reader.Close()
+
+	var records []*Record
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,189 @@
+package capture
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+var logger = log.New(os.Stdout, "[capture] ", log.LstdFlags)
+
+// Interval of the flushes of the compressed stream, a capture of a killed process loses the last interval at most.
+const flushInterval = time.Second
+
+type Kind string
+
+const (
+	KindFrame      Kind = "frame"
+	KindDisconnect Kind = "disconnect"
+	KindSnapshot   Kind = "snapshot"
+)
+
+// A Record is a line of the capture file.
+type Record struct {
+	// Time is the receive time in unix nanoseconds. The times of the records of a file never decrease.
+	Time int64 `json:"time"`
+	Kind Kind  `json:"kind"`
+	// Conn identifies the stream connection of the frames and the disconnects.
+	Conn string `json:"conn,omitempty"`
+	// Frame is the raw message of the stream.
+	Frame string `json:"frame,omitempty"`
+
+	// Source, Symbol and Limit are the arguments of the snapshot request, see domain.SnapshotSourceOrderBook.
+	Source   string                    `json:"source,omitempty"`
+	Symbol   string                    `json:"symbol,omitempty"`
+	Limit    int                       `json:"limit,omitempty"`
+	Snapshot *domain.OrderBookSnapshot `json:"snapshot,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+}
+
+// Recorder writes the traffic of a provider to a gzip compressed NDJSON file, a record per line.
+type Recorder struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	enc  *json.Encoder
+
+	mu       sync.Mutex
+	lastTime int64
+	closed   bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewRecorder creates the capture file of the provider in the dir, the name is made of the provider and the start time,
+// e.g. binance-20240102T150405.ndjson.gz.
+func NewRecorder(dir, provider string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the capture dir: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.ndjson.gz", provider, time.Now().UTC().Format("20060102T150405.000000000")))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the capture file: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	buf := bufio.NewWriter(gz)
+	r := &Recorder{
+		path: path,
+		file: file,
+		gz:   gz,
+		buf:  buf,
+		enc:  json.NewEncoder(buf),
+		done: make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.flushLoop()
+
+	logger.Printf("capturing %s to %s", provider, path)
+	return r, nil
+}
+
+// Path returns the path of the capture file.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+func (r *Recorder) Frame(conn string, frame []byte) {
+	r.write(&Record{Kind: KindFrame, Conn: conn, Frame: string(frame)})
+}
+
+func (r *Recorder) Disconnect(conn string) {
+	r.write(&Record{Kind: KindDisconnect, Conn: conn})
+}
+
+func (r *Recorder) Snapshot(source string, symbol *domain.MarketSymbol, limit int, snapshot *domain.OrderBookSnapshot, err error) {
+	record := &Record{Kind: KindSnapshot, Source: source, Symbol: symbol.String(), Limit: limit, Snapshot: snapshot}
+	if err != nil {
+		record.Error = err.Error()
+		record.Snapshot = nil
+	}
+
+	r.write(record)
+}
+
+// Close flushes the records and closes the file. The records written after are dropped.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+
+	err := r.flush()
+	if closeErr := r.gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return err
+}
+
+// write stamps the record with the time of the write, so the records of the concurrent connections
+// are in the order of the times.
+func (r *Recorder) write(record *Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	record.Time = time.Now().UnixNano()
+	if record.Time < r.lastTime {
+		record.Time = r.lastTime
+	}
+	r.lastTime = record.Time
+
+	if err := r.enc.Encode(record); err != nil {
+		logger.Printf("failed to write the record to %s: %s", r.path, err)
+	}
+}
+
+func (r *Recorder) flushLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if !r.closed {
+				if err := r.flush(); err != nil {
+					logger.Printf("failed to flush %s: %s", r.path, err)
+				}
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// flush writes the buffered records out as a complete gzip block. The caller must hold the mutex.
+func (r *Recorder) flush() error {
+	if err := r.buf.Flush(); err != nil {
+		return err
+	}
+	return r.gz.Flush()
+}
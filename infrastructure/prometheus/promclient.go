@@ -9,6 +9,15 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+var OpenOrderBookGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "open_order_book",
+		Help: "open order book per provider",
+	},
+	[]string{"provider"},
+)
+
+// The per-provider gauges of binance and kucoin predate open_order_book, they are kept for the existing dashboards.
 var BinanceOpenOrderBookGauge = prometheus.NewGauge(
 	prometheus.GaugeOpts{
 		Name: "binance_open_order_book",
@@ -23,12 +32,43 @@ var KucoinOpenOrderBookGauge = prometheus.NewGauge(
 	},
 )
 
+var StreamConnectionTopicsGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "stream_connection_topics",
+		Help: "subscribed topics per stream connection",
+	},
+	[]string{"provider", "connection"},
+)
+
+var SyncAPIRequestsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "sync_api_requests_total",
+		Help: "snapshot requests per provider and transport",
+	},
+	[]string{"provider", "transport"},
+)
+
+// SetOpenOrderBooks sets the number of the open order books of the provider.
+func SetOpenOrderBooks(provider string, count int) {
+	OpenOrderBookGauge.WithLabelValues(provider).Set(float64(count))
+
+	switch provider {
+	case "binance":
+		BinanceOpenOrderBookGauge.Set(float64(count))
+	case "kucoin":
+		KucoinOpenOrderBookGauge.Set(float64(count))
+	}
+}
+
 func StartPromClientServer() {
 	reg := prometheus.NewRegistry()
 	promHnadler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
 
+	reg.MustRegister(OpenOrderBookGauge)
 	reg.MustRegister(BinanceOpenOrderBookGauge)
 	reg.MustRegister(KucoinOpenOrderBookGauge)
+	reg.MustRegister(StreamConnectionTopicsGauge)
+	reg.MustRegister(SyncAPIRequestsCounter)
 	reg.MustRegister(collectors.NewGoCollector())
 
 	http.Handle("/metrics", promHnadler)
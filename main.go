@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spooky-finn/cryptobridge/config"
@@ -17,9 +21,18 @@ import (
 
 var (
 	port                       = flag.Int("port", 50051, "The server port")
-	availableProviders         = flag.String("providers", "binance,kucoin", "The available providers")
+	availableProviders         = flag.String("providers", "binance,kucoin", "Comma separated list of the providers to instantiate")
 	debugMode                  = flag.Bool("v", false, "Enable debug mode")
 	orderBookMaxSupportedDepth = flag.Int("max-orderbook-depth", 1000, "The maximum rows in the orderbook to guaranelly be served")
+	binanceStreamsPerConn      = flag.Int("binance-streams-per-conn", 200, "The maximum streams subscribed over a single binance connection")
+	binanceHotMarkets          = flag.String("binance-hot-markets", "", "Comma separated list of the markets with dedicated binance connections, e.g. btc_usdt")
+	binanceSubscribeTimeout    = flag.Duration("binance-subscribe-timeout", 10*time.Second, "The time to wait for the binance connection to subscribe while it is down")
+	binanceStreamEndpoints     = flag.String("binance-stream-endpoints", "", "Comma separated list of the binance combined-stream endpoints in the order of preference")
+	assetAliases               = flag.String("asset-aliases", "", "Comma separated list of the alternative asset codes added to the built-in ones, e.g. xbt=btc")
+	nativeAssets               = flag.String("native-assets", "", "Comma separated list of the native asset codes of the providers added to the built-in ones, e.g. kucoin:bsv=bchsv")
+	captureDir                 = flag.String("capture-dir", "", "The dir to capture the raw frames and the snapshots of the binance and kucoin providers to")
+	replayDir                  = flag.String("replay-dir", "", "The dir of the capture files the providers replay instead of connecting to the venues")
+	replaySpeed                = flag.Float64("replay-speed", 1, "The speed of the replay relative to the capture, 0 replays without the delays")
 )
 
 func main() {
@@ -29,6 +42,18 @@ func main() {
 
 	config.DebugMode = *debugMode
 	config.OrderBookMaxSupportedDepth = *orderBookMaxSupportedDepth
+	config.BinanceStreamsPerConnection = *binanceStreamsPerConn
+	if *binanceHotMarkets != "" {
+		config.BinanceHotMarkets = strings.Split(*binanceHotMarkets, ",")
+	}
+	config.BinanceSubscribeTimeout = *binanceSubscribeTimeout
+	if *binanceStreamEndpoints != "" {
+		config.BinanceStreamEndpoints = strings.Split(*binanceStreamEndpoints, ",")
+	}
+
+	if err := parseAssetAliases(*assetAliases, *nativeAssets); err != nil {
+		log.Fatalf("failed to parse asset aliases: %v", err)
+	}
 
 	if config.DebugMode {
 		log.Println("Debug mode enabled")
@@ -42,11 +67,67 @@ func main() {
 	s := grpc.NewServer()
 	conf := &rpc.ValidationServiceConfig{
 		AvailableProviders: strings.Split(*availableProviders, ","),
+		CaptureDir:         *captureDir,
+		ReplayDir:          *replayDir,
+		ReplaySpeed:        *replaySpeed,
+	}
+	srv, err := rpc.NewServer(conf)
+	if err != nil {
+		log.Fatalf("failed to create server: %v", err)
 	}
-	gen.RegisterMarketDataServiceServer(s, rpc.NewServer(conf))
+	gen.RegisterMarketDataServiceServer(s, srv)
+
+	// the providers are closed on shutdown, so the captures are flushed
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+
+		log.Println("shutting down")
+		s.GracefulStop()
+	}()
 
 	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
+	err = s.Serve(lis)
+	srv.Close()
+	if err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
+
+// parseAssetAliases adds the aliases of the flags to the built-in ones.
+// The alternative codes are in the form of alias=canonical, the native codes in the form of provider:canonical=native.
+func parseAssetAliases(aliases, native string) error {
+	for _, pair := range splitList(aliases) {
+		alias, canonical, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid asset alias %q", pair)
+		}
+		config.AssetAliases[strings.ToLower(alias)] = strings.ToLower(canonical)
+	}
+
+	for _, entry := range splitList(native) {
+		provider, pair, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("invalid native asset %q", entry)
+		}
+		canonical, code, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid native asset %q", entry)
+		}
+
+		if config.NativeAssets[provider] == nil {
+			config.NativeAssets[provider] = make(map[string]string)
+		}
+		config.NativeAssets[provider][strings.ToLower(canonical)] = strings.ToLower(code)
+	}
+
+	return nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
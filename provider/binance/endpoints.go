@@ -0,0 +1,115 @@
+package binance
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The interchangeable combined-stream endpoints of the spot markets in the order of preference.
+var baseEndpoints = []string{
+	"wss://stream.binance.com:9443/stream",
+	"wss://stream.binance.com:443/stream",
+	"wss://data-stream.binance.vision/stream",
+}
+
+// Time an endpoint is deprioritized after a failed dial.
+const endpointCooldown = time.Minute
+
+// EndpointSelector orders the interchangeable endpoints by the health and the measured connect latency.
+type EndpointSelector struct {
+	endpoints []string
+
+	mu       sync.Mutex
+	latency  map[string]time.Duration
+	failedAt map[string]time.Time
+}
+
+func NewEndpointSelector(endpoints []string) *EndpointSelector {
+	return &EndpointSelector{
+		endpoints: endpoints,
+		latency:   make(map[string]time.Duration),
+		failedAt:  make(map[string]time.Time),
+	}
+}
+
+// Endpoints returns the endpoints in the order of preference: the healthy ones by the measured latency,
+// then the healthy ones not measured yet in the configured order, then the failed ones by the time of the failure.
+func (s *EndpointSelector) Endpoints() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := append([]string{}, s.endpoints...)
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+
+		aHealthy, bHealthy := s.isHealthy(a), s.isHealthy(b)
+		if aHealthy != bHealthy {
+			return aHealthy
+		}
+		if !aHealthy {
+			return s.failedAt[a].Before(s.failedAt[b])
+		}
+
+		aLatency, aMeasured := s.latency[a]
+		bLatency, bMeasured := s.latency[b]
+		if aMeasured != bMeasured {
+			return aMeasured
+		}
+		return aLatency < bLatency
+	})
+
+	return result
+}
+
+// Best returns the most preferred endpoint.
+func (s *EndpointSelector) Best() string {
+	return s.Endpoints()[0]
+}
+
+// ReportSuccess records the connect latency of the endpoint and marks it healthy.
+func (s *EndpointSelector) ReportSuccess(endpoint string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency[endpoint] = latency
+	delete(s.failedAt, endpoint)
+}
+
+// ReportFailure deprioritizes the endpoint for the cooldown.
+func (s *EndpointSelector) ReportFailure(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failedAt[endpoint] = time.Now()
+}
+
+// Probe measures the connect latency of all the endpoints concurrently.
+func (s *EndpointSelector) Probe(dialer *websocket.Dialer) {
+	wg := sync.WaitGroup{}
+	for _, endpoint := range s.endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+
+			start := time.Now()
+			conn, _, err := dialer.Dial(endpoint, nil)
+			if err != nil {
+				logger.Printf("endpoint %s is unavailable: %s", endpoint, err)
+				s.ReportFailure(endpoint)
+				return
+			}
+			conn.Close()
+
+			s.ReportSuccess(endpoint, time.Since(start))
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+func (s *EndpointSelector) isHealthy(endpoint string) bool {
+	failedAt, ok := s.failedAt[endpoint]
+	return !ok || time.Since(failedAt) > endpointCooldown
+}
@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointSelector(t *testing.T) {
+	selector := NewEndpointSelector([]string{"a", "b", "c", "d"})
+
+	// the configured order is kept until the latency is measured
+	assert.Equal(t, []string{"a", "b", "c", "d"}, selector.Endpoints())
+
+	selector.ReportSuccess("c", 20*time.Millisecond)
+	selector.ReportSuccess("d", 10*time.Millisecond)
+	selector.ReportFailure("a")
+	assert.Equal(t, []string{"d", "c", "b", "a"}, selector.Endpoints())
+
+	// the failed endpoint is healthy again after the successful dial
+	selector.ReportFailure("d")
+	assert.Equal(t, "c", selector.Best())
+	selector.ReportSuccess("d", 15*time.Millisecond)
+	assert.Equal(t, "d", selector.Best())
+}
+
+func TestBinanceStreamClient_Failover(t *testing.T) {
+	primary := newFakeStreamServer(t)
+	backup := newFakeStreamServer(t)
+	defer backup.Close()
+
+	primaryURL := "ws" + strings.TrimPrefix(primary.URL, "http")
+	backupURL := "ws" + strings.TrimPrefix(backup.URL, "http")
+
+	client := NewBinanceStreamClient(NewEndpointSelector([]string{primaryURL, backupURL}), testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+	assert.Equal(t, primaryURL, client.Endpoint())
+
+	sub, err := client.Subscribe("btcusdt@depth")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return primary.connCount() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the primary endpoint goes down, the streams are moved to the backup
+	primary.Listener.Close()
+	primary.dropConnections()
+	primary.Close()
+
+	select {
+	case msg := <-sub.Stream:
+		assert.Empty(t, msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnect event is not received")
+	}
+
+	assert.Equal(t, backupURL, client.Endpoint())
+	assert.Eventually(t, func() bool {
+		requests := backup.allRequests()
+		return len(requests) == 1 && requests[0].Params[0] == "btcusdt@depth"
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,43 @@
+package binance
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+// BinanceFuturesDepthUpdateValidator validates the depth updates of the futures markets.
+// Unlike spot, the futures events are chained by the pu field, which must be equal to the u of the previous event.
+type BinanceFuturesDepthUpdateValidator struct{}
+
+func (v *BinanceFuturesDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	// Each new event's pu should be equal to the previous event's u
+	if update.PrevSequenceEnd != orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+func (v *BinanceFuturesDepthUpdateValidator) IsValidFirstUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	// Drop any event where u is < lastUpdateId in the snapshot. The event with u == lastUpdateId is already
+	// included into the snapshot and has nothing to apply.
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	// The first processed event should have U <= lastUpdateId AND u >= lastUpdateId
+	if update.SequenceStart > orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+func (v *BinanceFuturesDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *BinanceFuturesDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
@@ -0,0 +1,63 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuturesDepthUpdateValidator(t *testing.T) {
+	v := &BinanceFuturesDepthUpdateValidator{}
+
+	// u <= previous u
+	err := v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 90, SequenceEnd: 100, PrevSequenceEnd: 89}, 100)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	// pu == previous u
+	err = v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 115, SequenceEnd: 120, PrevSequenceEnd: 110}, 110)
+	assert.Nil(t, err, "Error should be nil")
+
+	// pu != previous u
+	err = v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 125, SequenceEnd: 130, PrevSequenceEnd: 120}, 110)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+	assert.True(t, v.IsErrOutOfSequece(err))
+
+	// U <= previous u is not enough after the first event
+	err = v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 105, SequenceEnd: 130, PrevSequenceEnd: 120}, 110)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
+
+func TestFuturesDepthUpdateValidator_FirstUpdate(t *testing.T) {
+	v := &BinanceFuturesDepthUpdateValidator{}
+
+	// u < lastUpdateId
+	err := v.IsValidFirstUpd(&domain.OrderBookUpdate{SequenceStart: 90, SequenceEnd: 99, PrevSequenceEnd: 89}, 100)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	// u == lastUpdateId
+	err = v.IsValidFirstUpd(&domain.OrderBookUpdate{SequenceStart: 95, SequenceEnd: 100, PrevSequenceEnd: 94}, 100)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	// U <= lastUpdateId AND u > lastUpdateId
+	err = v.IsValidFirstUpd(&domain.OrderBookUpdate{SequenceStart: 95, SequenceEnd: 110, PrevSequenceEnd: 94}, 100)
+	assert.Nil(t, err, "Error should be nil")
+
+	// U > lastUpdateId
+	err = v.IsValidFirstUpd(&domain.OrderBookUpdate{SequenceStart: 105, SequenceEnd: 110, PrevSequenceEnd: 104}, 100)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
+
+func TestParseFuturesDepthUpdate(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	msg := []byte(`{"stream":"btcusdt@depth","data":{"e":"depthUpdate","E":123456789,"T":123456788,"s":"BTCUSDT","U":157,"u":160,"pu":149,"b":[["0.0024","10"]],"a":[["0.0026","100"]]}}`)
+
+	update, err := parseFuturesDepthUpdate(msg, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(157), update.SequenceStart, "SequenceStart should match")
+	assert.Equal(t, int64(160), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, int64(149), update.PrevSequenceEnd, "PrevSequenceEnd should match")
+	assert.Equal(t, [][]string{{"0.0024", "10"}}, update.Bids, "Bids should match")
+	assert.Equal(t, [][]string{{"0.0026", "100"}}, update.Asks, "Asks should match")
+}
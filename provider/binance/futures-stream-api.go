@@ -0,0 +1,90 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const binanceFuturesWebsocketEndpoint = "wss://fstream.binance.com/stream"
+
+// BinanceFuturesStreamAPI streams the depth of the USDⓈ-M futures markets.
+type BinanceFuturesStreamAPI struct {
+	streamClient StreamSubscriber
+	syncAPI      domain.ProviderSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type FuturesDepthUpdateData struct {
+	DepthUpdateData
+	TransactionTime int64 `json:"T"`
+	// Final update id of the previous event.
+	PrevFinalUpdateId int64 `json:"pu"`
+}
+
+func NewBinanceFuturesStreamAPI(client StreamSubscriber, syncAPI domain.ProviderSyncAPI, validator domain.IDepthUpdateValidator) *BinanceFuturesStreamAPI {
+	return &BinanceFuturesStreamAPI{
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(FuturesProviderName),
+	}
+}
+
+func (bs *BinanceFuturesStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	topic := fmt.Sprintf("%s@depth", strings.ToLower(bs.symbols.Native(symbol)))
+	subscribtion, err := bs.streamClient.Subscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+	s := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(s)
+
+		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				s <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			update, err := parseFuturesDepthUpdate(msg, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			s <- update
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      s,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       topic,
+	}, nil
+}
+
+func (bs *BinanceFuturesStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(bs, bs.syncAPI, bs.validator)
+	return maintainer.CreareOrderBook(FuturesProviderName, symbol)
+}
+
+func parseFuturesDepthUpdate(msg []byte, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	var message Message[FuturesDepthUpdateData]
+	if err := json.Unmarshal(msg, &message); err != nil {
+		return nil, err
+	}
+
+	update := domain.NewOrderBookUpdate(
+		message.Data.Bids, message.Data.Asks,
+		message.Data.FirstUpdateId, message.Data.FinalUpdateId,
+		symbol,
+	)
+	update.PrevSequenceEnd = message.Data.PrevFinalUpdateId
+
+	return update, nil
+}
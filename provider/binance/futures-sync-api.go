@@ -0,0 +1,146 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const binanceFuturesBaseURL = "https://fapi.binance.com/fapi/v1"
+
+// The depth limits accepted by the futures depth endpoint.
+var futuresDepthLimits = []int{5, 10, 20, 50, 100, 500, 1000}
+
+// BinanceFuturesSyncAPI serves the snapshots and the premium index of the USDⓈ-M futures markets over REST.
+type BinanceFuturesSyncAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+type FuturesDepthData struct {
+	LastUpdateId    int64      `json:"lastUpdateId"`
+	TransactionTime int64      `json:"T"`
+	Bids            [][]string `json:"bids"`
+	Asks            [][]string `json:"asks"`
+}
+
+type PremiumIndexData struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	LastFundingRate string `json:"lastFundingRate"`
+	NextFundingTime int64  `json:"nextFundingTime"`
+	Time            int64  `json:"time"`
+}
+
+type ErrorData struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func NewBinanceFuturesSyncAPI(baseURL string, httpClient *http.Client) *BinanceFuturesSyncAPI {
+	return &BinanceFuturesSyncAPI{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(FuturesProviderName),
+	}
+}
+
+func (api *BinanceFuturesSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	query := url.Values{}
+	query.Set("symbol", api.symbols.Native(symbol))
+	query.Set("limit", strconv.Itoa(futuresDepthLimit(limit)))
+
+	data := &FuturesDepthData{}
+	if err := api.get("depth", query, data); err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:         domain.OrderBookSource_Provider,
+		LastUpdateId:   data.LastUpdateId,
+		LastUpdateTime: data.TransactionTime,
+		Bids:           data.Bids,
+		Asks:           data.Asks,
+	}, nil
+}
+
+func (api *BinanceFuturesSyncAPI) MarkPrice(symbol *domain.MarketSymbol) (*domain.MarkPrice, error) {
+	data, err := api.premiumIndex(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mark price: %w", err)
+	}
+
+	return &domain.MarkPrice{
+		MarkPrice:  data.MarkPrice,
+		IndexPrice: data.IndexPrice,
+		Time:       data.Time,
+	}, nil
+}
+
+func (api *BinanceFuturesSyncAPI) FundingRate(symbol *domain.MarketSymbol) (*domain.FundingRate, error) {
+	data, err := api.premiumIndex(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding rate: %w", err)
+	}
+
+	return &domain.FundingRate{
+		FundingRate:     data.LastFundingRate,
+		NextFundingTime: data.NextFundingTime,
+		Time:            data.Time,
+	}, nil
+}
+
+func (api *BinanceFuturesSyncAPI) premiumIndex(symbol *domain.MarketSymbol) (*PremiumIndexData, error) {
+	query := url.Values{}
+	query.Set("symbol", api.symbols.Native(symbol))
+
+	data := &PremiumIndexData{}
+	if err := api.get("premiumIndex", query, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (api *BinanceFuturesSyncAPI) get(path string, query url.Values, v interface{}) error {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/%s?%s", api.baseURL, path, query.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errData := &ErrorData{}
+		json.Unmarshal(body, errData)
+		return fmt.Errorf("binance error: status=%d, code=%d, msg=%s", resp.StatusCode, errData.Code, errData.Msg)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	return nil
+}
+
+// futuresDepthLimit returns the smallest accepted limit that covers the requested depth.
+func futuresDepthLimit(limit int) int {
+	for _, l := range futuresDepthLimits {
+		if limit <= l {
+			return l
+		}
+	}
+
+	return futuresDepthLimits[len(futuresDepthLimits)-1]
+}
@@ -0,0 +1,67 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinanceFuturesSyncAPI_OrderBookSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/depth", r.URL.Path)
+		assert.Equal(t, "BTCUSDT", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "500", r.URL.Query().Get("limit"))
+
+		w.Write([]byte(`{"lastUpdateId":1027024,"E":1589436922972,"T":1589436922959,"bids":[["4.00000000","431.00000000"]],"asks":[["4.00000200","12.00000000"]]}`))
+	}))
+	defer server.Close()
+
+	api := NewBinanceFuturesSyncAPI(server.URL, server.Client())
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	snapshot, err := api.OrderBookSnapshot(symbol, 200)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(1027024), snapshot.LastUpdateId, "LastUpdateId should match")
+	assert.Equal(t, [][]string{{"4.00000000", "431.00000000"}}, snapshot.Bids, "Bids should match")
+	assert.Equal(t, [][]string{{"4.00000200", "12.00000000"}}, snapshot.Asks, "Asks should match")
+}
+
+func TestBinanceFuturesSyncAPI_PremiumIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/premiumIndex", r.URL.Path)
+		assert.Equal(t, "BTCUSDT", r.URL.Query().Get("symbol"))
+
+		w.Write([]byte(`{"symbol":"BTCUSDT","markPrice":"11793.63104562","indexPrice":"11781.80495970","estimatedSettlePrice":"11781.16138815","lastFundingRate":"0.00038246","interestRate":"0.00010000","nextFundingTime":1597392000000,"time":1597370495002}`))
+	}))
+	defer server.Close()
+
+	api := NewBinanceFuturesSyncAPI(server.URL, server.Client())
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	markPrice, err := api.MarkPrice(symbol)
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, &domain.MarkPrice{MarkPrice: "11793.63104562", IndexPrice: "11781.80495970", Time: 1597370495002}, markPrice)
+
+	fundingRate, err := api.FundingRate(symbol)
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, &domain.FundingRate{FundingRate: "0.00038246", NextFundingTime: 1597392000000, Time: 1597370495002}, fundingRate)
+}
+
+func TestBinanceFuturesSyncAPI_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+	}))
+	defer server.Close()
+
+	api := NewBinanceFuturesSyncAPI(server.URL, server.Client())
+
+	symbol, _ := domain.NewMarketSymbol("foo", "bar")
+	_, err := api.OrderBookSnapshot(symbol, 10)
+
+	assert.ErrorContains(t, err, "Invalid symbol.")
+}
@@ -0,0 +1,75 @@
+package binance
+
+import (
+	"os"
+
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const ProviderName = "binance"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	if len(opts.ReplayFiles) > 0 {
+		return NewReplayProvider(opts.ReplayFiles, opts.ReplaySpeed)
+	}
+
+	endpoints := baseEndpoints
+	if len(config.BinanceStreamEndpoints) > 0 {
+		endpoints = config.BinanceStreamEndpoints
+	}
+
+	wsAPIEndpoint := binanceWSAPIEndpoint
+	if endpoint := os.Getenv("BINANCE_WS_API_ENDPOINT"); endpoint != "" {
+		wsAPIEndpoint = endpoint
+	}
+
+	dialer := opts.NewDialer()
+	recorder := opts.NewRecorder()
+	streamClient := NewBinanceStreamShards(opts.StreamURLsOr(endpoints), dialer, config.BinanceStreamsPerConnection, config.BinanceHotMarkets)
+	streamClient.SetRecorder(recorder)
+	syncAPI := NewBinanceAPI(opts.SyncURLOr(wsAPIEndpoint), opts.RestURLOr(binanceRestBaseURL), dialer, opts.NewHTTPClient())
+	validator := &BinanceDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewBinanceStreamAPI(streamClient, domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceOrderBook), validator),
+		SyncAPI:              domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(ProviderName),
+	}, nil
+}
+
+const FuturesProviderName = "binance-futures"
+
+// NewFuturesProvider instantiates the provider of the USDⓈ-M futures markets.
+func NewFuturesProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	if len(opts.ReplayFiles) > 0 {
+		return NewFuturesReplayProvider(opts.ReplayFiles, opts.ReplaySpeed)
+	}
+
+	recorder := opts.NewRecorder()
+	streamClient := NewBinanceStreamShards(opts.StreamURLsOr([]string{binanceFuturesWebsocketEndpoint}), opts.NewDialer(), config.BinanceStreamsPerConnection, config.BinanceHotMarkets)
+	streamClient.SetRecorder(recorder)
+	syncAPI := NewBinanceFuturesSyncAPI(opts.RestURLOr(binanceFuturesBaseURL), opts.NewHTTPClient())
+	validator := &BinanceFuturesDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 FuturesProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewBinanceFuturesStreamAPI(streamClient, domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceOrderBook), validator),
+		SyncAPI:              domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		FuturesAPI:           syncAPI,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(FuturesProviderName),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the provider. The symbols are written without a separator, e.g. BTCUSDT,
+// the stream names use them in lowercase.
+func newSymbolMapper(provider string) *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(provider, domain.SymbolFormat{})
+}
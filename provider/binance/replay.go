@@ -0,0 +1,72 @@
+package binance
+
+import (
+	"encoding/json"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/infrastructure/capture"
+)
+
+// NewReplayProvider replays the capture files of the spot provider. The frames go through the same parsing
+// and the same order book maintainers as the live ones. The markets are not validated.
+func NewReplayProvider(paths []string, speed float64) (*domain.Provider, error) {
+	player, err := newPlayer(paths, speed)
+	if err != nil {
+		return nil, err
+	}
+	validator := &BinanceDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         player,
+		StreamAPI:            NewBinanceStreamAPI(replayStream{player}, player.SyncAPI(domain.SnapshotSourceOrderBook), validator),
+		SyncAPI:              player.SyncAPI(domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolMapper:         newSymbolMapper(ProviderName),
+	}, nil
+}
+
+// NewFuturesReplayProvider replays the capture files of the futures provider, the premium index is not captured.
+func NewFuturesReplayProvider(paths []string, speed float64) (*domain.Provider, error) {
+	player, err := newPlayer(paths, speed)
+	if err != nil {
+		return nil, err
+	}
+	validator := &BinanceFuturesDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 FuturesProviderName,
+		StreamClient:         player,
+		StreamAPI:            NewBinanceFuturesStreamAPI(replayStream{player}, player.SyncAPI(domain.SnapshotSourceOrderBook), validator),
+		SyncAPI:              player.SyncAPI(domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolMapper:         newSymbolMapper(FuturesProviderName),
+	}, nil
+}
+
+func newPlayer(paths []string, speed float64) (*capture.Player, error) {
+	records, err := capture.ReadAll(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return capture.NewPlayer(records, speed, routeFrame), nil
+}
+
+type replayStream struct {
+	*capture.Player
+}
+
+func (s replayStream) Subscribe(topic string) (SubscibeResult, error) {
+	return s.Player.Subscribe(topic), nil
+}
+
+// routeFrame routes the frames of the combined stream the way the stream client does, the subscribers get the whole frame.
+func routeFrame(frame []byte) (string, []byte, bool) {
+	var message Message[json.RawMessage]
+	if err := json.Unmarshal(frame, &message); err != nil || message.Stream == "" {
+		return "", nil, false
+	}
+
+	return message.Stream, frame, true
+}
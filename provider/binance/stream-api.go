@@ -3,19 +3,16 @@ package binance
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spooky-finn/cryptobridge/domain"
 )
 
-var baseEndpoints = []string{
-	"wss://stream.binance.com:9443/stream",
-	"wss://stream.binance.com:443",
-}
-
 type BinanceStreamAPI struct {
-	endpoint     string
-	streamClient *BinanceStreamClient
-	syncAPI      *BinanceSyncAPI
+	streamClient StreamSubscriber
+	syncAPI      domain.ProviderSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
 }
 
 type DethUpdateSubscribtion = domain.Subscription[Message[DepthUpdateData]]
@@ -30,16 +27,17 @@ type DepthUpdateData struct {
 	Asks          [][]string `json:"a"`
 }
 
-func NewBinanceStreamAPI(client *BinanceStreamClient, syncAPI *BinanceSyncAPI) *BinanceStreamAPI {
+func NewBinanceStreamAPI(client StreamSubscriber, syncAPI domain.ProviderSyncAPI, validator domain.IDepthUpdateValidator) *BinanceStreamAPI {
 	return &BinanceStreamAPI{
-		endpoint:     baseEndpoints[0],
 		streamClient: client,
 		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(ProviderName),
 	}
 }
 
 func (bs *BinanceStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
-	topic := fmt.Sprintf("%s@depth", symbol.Join(""))
+	topic := fmt.Sprintf("%s@depth", strings.ToLower(bs.symbols.Native(symbol)))
 	subscribtion, err := bs.streamClient.Subscribe(topic)
 	if err != nil {
 		return nil, err
@@ -50,6 +48,12 @@ func (bs *BinanceStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domai
 		defer close(s)
 
 		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				// the connection was restored, the updates in between are lost
+				s <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
 			var message Message[DepthUpdateData]
 			err := json.Unmarshal(msg, &message)
 
@@ -74,10 +78,9 @@ func (bs *BinanceStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domai
 }
 
 func (bs *BinanceStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
-	validator := &BinanceDepthUpdateValidator{}
-	maintainer := domain.NewOrderBookMaintainer(bs, bs.syncAPI, validator)
+	maintainer := domain.NewOrderBookMaintainer(bs, bs.syncAPI, bs.validator)
 
-	result := maintainer.CreareOrderBook("binance", symbol)
+	result := maintainer.CreareOrderBook(ProviderName, symbol)
 	if result.Err != nil {
 		return result
 	}
@@ -2,30 +2,38 @@ package binance
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math/rand"
-	"net/http"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/recws-org/recws"
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
 	"github.com/spooky-finn/cryptobridge/domain"
 )
 
-const (
-	binanceDefaultWebsocketEndpoint = "wss://stream.binance.com:9443/stream"
-	pingDelay                       = time.Minute * 9
-)
+var ErrNotConnected = errors.New("connection is not established")
+
+// The connections are identified globally in the captures of the shards.
+var connIdCounter atomic.Int64
 
 type Message[T any] struct {
 	Stream string `json:"stream"`
 	Data   T      `json:"data"`
 }
 
+// A SubscribtionEntry is the stream shared by the subscribers. An empty message is the reconnect event:
+// the stream is subscribed again after the reconnection and the messages in between are lost.
+// The empty message is also sent if the buffer overflows. The messages sent after the entry is closed are dropped.
 type SubscribtionEntry struct {
-	ch              chan []byte
+	consumer        *domain.StreamConsumer[[]byte]
 	subscriberCount int
+	// queued is set until the subscribe request is sent over the connection.
+	queued bool
 }
 
 type WebSocketRequestModel struct {
@@ -39,77 +47,104 @@ type WebSocketAckModel struct {
 	ReqId  int    `json:"id"`
 }
 
+// BinanceStreamClient is a combined-stream connection. The lost connection is redialed with the backoff
+// to the most preferred endpoint of the selector and the streams are subscribed again.
 type BinanceStreamClient struct {
-	conn          *recws.RecConn
+	endpoints *EndpointSelector
+	endpoint  string
+	dialer    *websocket.Dialer
+	// Time to wait for the connection if the subscription is queued.
+	subscribeTimeout time.Duration
+	conn             *websocket.Conn
+	// Closed while the connection is up.
+	connected     chan struct{}
 	subscriptions map[string]*SubscribtionEntry
 	mu            sync.Mutex
+	done          chan struct{}
+	closeOnce     sync.Once
+	// recorder captures the raw frames of the connections.
+	recorder domain.Recorder
 }
 
 type SubscibeResult = *domain.Subscription[[]byte]
 
-func NewBinanceStreamClient() *BinanceStreamClient {
+func NewBinanceStreamClient(endpoints *EndpointSelector, dialer *websocket.Dialer) *BinanceStreamClient {
 	return &BinanceStreamClient{
-		conn:          nil,
-		subscriptions: make(map[string]*SubscribtionEntry),
-		mu:            sync.Mutex{},
+		endpoints:        endpoints,
+		dialer:           dialer,
+		subscribeTimeout: config.BinanceSubscribeTimeout,
+		conn:             nil,
+		connected:        make(chan struct{}),
+		subscriptions:    make(map[string]*SubscribtionEntry),
+		mu:               sync.Mutex{},
+		done:             make(chan struct{}),
+		recorder:         domain.NopRecorder{},
 	}
 }
 
+// Connect dials the endpoints in the order of preference. If all of them fail, the client keeps redialing in the background.
 func (c *BinanceStreamClient) Connect() error {
-	conn := &recws.RecConn{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 5 * time.Second,
-		KeepAliveTimeout: pingDelay,
-		Conn:             nil,
-		NonVerbose:       false,
+	conn, endpoint, err := c.dial()
+	if err != nil {
+		go c.reconnect()
+		return err
 	}
 
-	conn.Dial(binanceDefaultWebsocketEndpoint, nil)
-
-	c.conn = conn
-
-	go c.read()
+	c.onConnect(conn, endpoint)
 	return nil
 }
 
-func (c *BinanceStreamClient) Subscribe(topic string) (SubscibeResult, error) {
+// Endpoint returns the endpoint of the current connection.
+func (c *BinanceStreamClient) Endpoint() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.conn.IsConnected() {
-		panic("connection is not established")
-	}
+	return c.endpoint
+}
 
+// Subscribe subscribes to the stream. While the connection is down the subscription is queued and sent
+// when the connection comes up. The error is returned if it doesn't come up within the subscribe timeout.
+func (c *BinanceStreamClient) Subscribe(topic string) (SubscibeResult, error) {
+	c.mu.Lock()
 	entry, ok := c.subscriptions[topic]
-	ch := make(chan []byte)
-
 	if ok {
 		entry.subscriberCount++
-		c.subscriptions[topic] = entry
-
-		ch = entry.ch
 	} else {
-		c.subscriptions[topic] = &SubscribtionEntry{
-			ch:              ch,
+		entry = &SubscribtionEntry{
+			consumer:        domain.NewStreamConsumer[[]byte](2048, nil),
 			subscriberCount: 1,
+			queued:          true,
 		}
+		c.subscriptions[topic] = entry
 
-		logger.Println("subscribing to the ", topic)
-
-		err := c.conn.WriteJSON(WebSocketRequestModel{
-			Method: "SUBSCRIBE",
-			ReqId:  getRandomReqID(),
-			Params: []string{topic},
-		})
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to send subscribe msg for topic=%s", topic)
+		if c.conn != nil {
+			logger.Println("subscribing to the ", topic)
+
+			err := c.conn.WriteJSON(WebSocketRequestModel{
+				Method: "SUBSCRIBE",
+				ReqId:  getRandomReqID(),
+				Params: []string{topic},
+			})
+			if err != nil {
+				// the reader fails on the broken connection, the topic is subscribed after the reconnection
+				logger.Printf("failed to send subscribe msg for topic=%s, it is queued: %s", topic, err)
+			} else {
+				entry.queued = false
+			}
 		}
+	}
+	queued := entry.queued
+	connected := c.connected
+	c.mu.Unlock()
 
+	if queued {
+		if err := c.waitConnected(topic, connected); err != nil {
+			return nil, err
+		}
 	}
 
 	return &domain.Subscription[[]byte]{
-		Stream: ch,
+		Stream: entry.consumer.Stream(),
 		Unsubscribe: func() {
 			c.unSubscribe(topic)
 		},
@@ -117,16 +152,59 @@ func (c *BinanceStreamClient) Subscribe(topic string) (SubscibeResult, error) {
 	}, nil
 }
 
+// waitConnected waits for the connection to flush the queued subscription. The subscription is released on the timeout.
+func (c *BinanceStreamClient) waitConnected(topic string, connected <-chan struct{}) error {
+	timer := time.NewTimer(c.subscribeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-connected:
+		return nil
+	case <-c.done:
+	case <-timer.C:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return ErrNotConnected
+	}
+	if !entry.queued {
+		// flushed right before the timeout
+		return nil
+	}
+
+	entry.subscriberCount--
+	if entry.subscriberCount == 0 {
+		entry.consumer.Close()
+		delete(c.subscriptions, topic)
+	}
+
+	return fmt.Errorf("%w: topic=%s is not subscribed within %v", ErrNotConnected, topic, c.subscribeTimeout)
+}
+
 func (c *BinanceStreamClient) unSubscribe(topic string) error {
 	logger.Println("unsubscribing from topic ", topic)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.subscriptions[topic].subscriberCount > 1 {
-		c.subscriptions[topic].subscriberCount -= 1
-	} else if c.subscriptions[topic].subscriberCount == 1 {
-		close(c.subscriptions[topic].ch)
-		delete(c.subscriptions, topic)
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil
+	}
+
+	if entry.subscriberCount > 1 {
+		entry.subscriberCount -= 1
+		return nil
+	}
+
+	entry.consumer.Close()
+	delete(c.subscriptions, topic)
+
+	if c.conn == nil {
+		return nil
 	}
 
 	err := c.conn.WriteJSON(WebSocketRequestModel{
@@ -142,47 +220,230 @@ func (c *BinanceStreamClient) unSubscribe(topic string) error {
 	return nil
 }
 
+// Topics returns the subscription list of the connection.
+func (c *BinanceStreamClient) Topics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic := range c.subscriptions {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// TopicCount returns the number of the streams subscribed over the connection.
+func (c *BinanceStreamClient) TopicCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.subscriptions)
+}
+
+func (c *BinanceStreamClient) hasTopic(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subscriptions[topic]
+	return ok
+}
+
+// Rebuild subscribes again to all the streams of the connection, the subscribers keep their channels.
+func (c *BinanceStreamClient) Rebuild() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+	return c.subscribeAll(c.conn)
+}
+
+func (c *BinanceStreamClient) dial() (*websocket.Conn, string, error) {
+	var err error
+	for _, endpoint := range c.endpoints.Endpoints() {
+		var conn *websocket.Conn
+
+		start := time.Now()
+		conn, _, err = c.dialer.Dial(endpoint, nil)
+		if err != nil {
+			logger.Printf("failed to dial to %s: %s", endpoint, err)
+			c.endpoints.ReportFailure(endpoint)
+			continue
+		}
+
+		c.endpoints.ReportSuccess(endpoint, time.Since(start))
+		return conn, endpoint, nil
+	}
+
+	return nil, "", fmt.Errorf("failed to dial to the binance websocket: %w", err)
+}
+
+// onConnect makes the connection current. binance doesn't restore the streams of the lost connection,
+// so they are subscribed again and the reconnect event is sent to the subscribers.
+func (c *BinanceStreamClient) onConnect(conn *websocket.Conn, endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		// closed while dialing
+		conn.Close()
+		return
+	default:
+	}
+
+	c.conn = conn
+	c.endpoint = endpoint
+	go c.read(conn, strconv.FormatInt(connIdCounter.Add(1), 10))
+
+	select {
+	case <-c.connected:
+	default:
+		close(c.connected)
+	}
+
+	if len(c.subscriptions) == 0 {
+		return
+	}
+
+	// the reconnect event precedes the messages of the new connection
+	for _, entry := range c.subscriptions {
+		// the queued subscriptions have not received any messages yet
+		if entry.queued {
+			entry.queued = false
+			continue
+		}
+		entry.consumer.Interrupt()
+	}
+
+	if err := c.subscribeAll(conn); err != nil {
+		logger.Printf("failed to resubscribe after the reconnection: %s", err)
+		return
+	}
+	logger.Printf("connected to %s, %d streams are subscribed", endpoint, len(c.subscriptions))
+}
+
+// onDisconnect redials unless the connection was closed by the client.
+func (c *BinanceStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+	c.connected = make(chan struct{})
+	c.mu.Unlock()
+
+	c.reconnect()
+}
+
+// reconnect dials with the backoff until it succeeds or the client is closed.
+func (c *BinanceStreamClient) reconnect() {
+	delay := time.Second
+	for {
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		conn, endpoint, err := c.dial()
+		if err == nil {
+			select {
+			case <-c.done:
+				conn.Close()
+			default:
+				c.onConnect(conn, endpoint)
+			}
+			return
+		}
+
+		logger.Printf("failed to reconnect: %s", err)
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// subscribeAll sends a single subscribe request for all the streams. The caller must hold the mutex.
+func (c *BinanceStreamClient) subscribeAll(conn *websocket.Conn) error {
+	if len(c.subscriptions) == 0 {
+		return nil
+	}
+
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic := range c.subscriptions {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return conn.WriteJSON(WebSocketRequestModel{
+		Method: "SUBSCRIBE",
+		ReqId:  getRandomReqID(),
+		Params: topics,
+	})
+}
+
 func (c *BinanceStreamClient) Close() error {
-	return c.conn.Conn.Close()
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
 }
 
-func (c *BinanceStreamClient) read() {
+func (c *BinanceStreamClient) read(conn *websocket.Conn, connId string) {
 	for {
-		_, msg, err := c.conn.ReadMessage()
+		_, msg, err := conn.ReadMessage()
 		if err != nil {
-			fmt.Printf("error: while reading from connection %v \n", err)
-			continue
+			logger.Printf("error while reading from connection: %s", err)
+			select {
+			case <-c.done:
+			default:
+				c.recorder.Disconnect(connId)
+			}
+			c.onDisconnect(conn)
+			return
 		}
+		c.recorder.Frame(connId, msg)
 
 		var multiStreamData map[string]interface{}
 
 		err = json.Unmarshal(msg, &multiStreamData)
 		if err != nil {
-			log.Fatalf("error: %v message %v", err, string(msg))
+			logger.Printf("error: %v message %v", err, string(msg))
+			continue
 		}
 
-		// FIXME: handle log of ack message
 		// if message have id then it is a response to a subscription
-		if multiStreamData["id"] != nil {
-			c.mu.Lock()
-			id := int(multiStreamData["id"].(float64))
-			entry, ok := c.subscriptions[fmt.Sprintf("%v", id)]
-			if ok {
-				fmt.Println("receive ack")
-				entry.ch <- msg
+		if id, ok := multiStreamData["id"]; ok && id != nil {
+			if config.DebugMode {
+				logger.Printf("received ack of the request id=%v: %s", id, string(msg))
 			}
-
-			delete(c.subscriptions, fmt.Sprintf("%v", id))
-			c.mu.Unlock()
+			continue
 		}
 
-		if multiStreamData["stream"] != nil {
-			topic := multiStreamData["stream"].(string)
+		if topic, ok := multiStreamData["stream"].(string); ok {
 			c.mu.Lock()
 			entry, ok := c.subscriptions[topic]
 			c.mu.Unlock()
+			// the entry may be closed in the meantime, the message is dropped then
 			if ok {
-				entry.ch <- msg
+				entry.consumer.Send(msg)
 			}
 		}
 	}
@@ -0,0 +1,125 @@
+package binance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// The dialer of the fake servers, the unreachable endpoints fail fast.
+var testDialer = &websocket.Dialer{HandshakeTimeout: 100 * time.Millisecond}
+
+func TestBinanceStreamClient_ResubscribeOnReconnect(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewBinanceStreamClient(NewEndpointSelector([]string{"ws" + strings.TrimPrefix(server.URL, "http")}), testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	btc, err := client.Subscribe("btcusdt@depth")
+	assert.NoError(t, err)
+	eth, err := client.Subscribe("ethusdt@depth")
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(server.allRequests()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	server.dropConnections()
+
+	// the subscribers receive the reconnect event
+	for _, stream := range []<-chan []byte{btc.Stream, eth.Stream} {
+		select {
+		case msg := <-stream:
+			assert.Empty(t, msg)
+		case <-time.After(10 * time.Second):
+			t.Fatal("reconnect event is not received")
+		}
+	}
+
+	// all the streams are subscribed again by a single request on the new connection
+	assert.Eventually(t, func() bool {
+		return server.connCount() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		for _, req := range server.allRequests() {
+			if req.Method == "SUBSCRIBE" && strings.Join(req.Params, ",") == "btcusdt@depth,ethusdt@depth" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBinanceStreamClient_QueuedSubscription(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewBinanceStreamClient(NewEndpointSelector([]string{"ws" + strings.TrimPrefix(server.URL, "http")}), testDialer)
+	client.subscribeTimeout = 5 * time.Second
+	defer client.Close()
+
+	// the connection comes up while the subscription waits
+	time.AfterFunc(100*time.Millisecond, func() {
+		assert.NoError(t, client.Connect())
+	})
+
+	sub, err := client.Subscribe("btcusdt@depth")
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		requests := server.allRequests()
+		return len(requests) == 1 && requests[0].Params[0] == "btcusdt@depth"
+	}, time.Second, 10*time.Millisecond)
+
+	// the queued subscription doesn't receive the reconnect event
+	select {
+	case msg := <-sub.Stream:
+		t.Fatalf("unexpected message: %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBinanceStreamClient_QueuedSubscriptionTimeout(t *testing.T) {
+	client := NewBinanceStreamClient(NewEndpointSelector([]string{"ws://127.0.0.1:1"}), testDialer)
+	client.subscribeTimeout = 50 * time.Millisecond
+	defer client.Close()
+
+	_, err := client.Subscribe("btcusdt@depth")
+	assert.ErrorIs(t, err, ErrNotConnected)
+	assert.Equal(t, 0, client.TopicCount())
+}
+
+func TestBinanceStreamClient_UnsubscribeWhileReceiving(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewBinanceStreamClient(NewEndpointSelector([]string{"ws" + strings.TrimPrefix(server.URL, "http")}), testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	btc, err := client.Subscribe("btcusdt@depth")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(server.allRequests()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the messages received after the stream is closed are dropped
+	for i := 0; i < 100; i++ {
+		server.push(btc.Topic, `{"u":1}`)
+	}
+	btc.Unsubscribe()
+	for i := 0; i < 100; i++ {
+		server.push(btc.Topic, `{"u":2}`)
+	}
+
+	assert.Eventually(t, func() bool {
+		_, ok := <-btc.Stream
+		return !ok
+	}, time.Second, time.Millisecond, "Stream should be closed")
+}
@@ -0,0 +1,244 @@
+package binance
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// Binance accepts up to 1024 streams over a single connection.
+const maxStreamsPerConnection = 1024
+
+// StreamSubscriber subscribes to the streams of the binance combined-stream endpoint.
+type StreamSubscriber interface {
+	Subscribe(topic string) (SubscibeResult, error)
+}
+
+// BinanceStreamShards spreads the streams across several combined-stream connections.
+// A shard is opened when all the shards reach the streams cap. The hot markets get dedicated shards,
+// so their updates are not delayed by the traffic of the other markets.
+type BinanceStreamShards struct {
+	endpoints  *EndpointSelector
+	dialer     *websocket.Dialer
+	maxStreams int
+	hotMarkets map[string]bool
+	recorder   domain.Recorder
+
+	mu     sync.Mutex
+	shards []*BinanceStreamClient
+	pinned map[string]*BinanceStreamClient
+	topics map[string]*BinanceStreamClient
+	// reserved counts the topics of the shard that are being subscribed without the mutex.
+	reserved map[*BinanceStreamClient]int
+}
+
+// NewBinanceStreamShards creates the shards of the interchangeable endpoints. Hot markets are the market symbols, e.g. btc_usdt.
+func NewBinanceStreamShards(endpoints []string, dialer *websocket.Dialer, maxStreams int, hotMarkets []string) *BinanceStreamShards {
+	if maxStreams <= 0 || maxStreams > maxStreamsPerConnection {
+		maxStreams = maxStreamsPerConnection
+	}
+
+	hot := make(map[string]bool, len(hotMarkets))
+	for _, market := range hotMarkets {
+		if symbol, err := domain.NewMarketSymbolFromString(market); err == nil {
+			hot[symbol.Join("")] = true
+		}
+	}
+
+	return &BinanceStreamShards{
+		endpoints:  NewEndpointSelector(endpoints),
+		dialer:     dialer,
+		maxStreams: maxStreams,
+		hotMarkets: hot,
+		recorder:   domain.NopRecorder{},
+		pinned:     make(map[string]*BinanceStreamClient),
+		topics:     make(map[string]*BinanceStreamClient),
+		reserved:   make(map[*BinanceStreamClient]int),
+	}
+}
+
+// SetRecorder makes the shards capture the raw frames. It must be called before Connect.
+func (s *BinanceStreamShards) SetRecorder(recorder domain.Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recorder = recorder
+}
+
+// Connect measures the latency of the endpoints and opens the first shard, the others are opened on demand.
+func (s *BinanceStreamShards) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.shards) > 0 {
+		return nil
+	}
+
+	if len(s.endpoints.endpoints) > 1 {
+		s.endpoints.Probe(s.dialer)
+	}
+
+	return s.open().Connect()
+}
+
+func (s *BinanceStreamShards) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, shard := range s.allShards() {
+		if err := shard.Close(); err != nil {
+			logger.Printf("failed to close the shard: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe picks the shard of the topic under the mutex. The new shard is dialed and the ack is awaited without it,
+// so the slow subscription doesn't block the subscriptions of the other shards.
+func (s *BinanceStreamShards) Subscribe(topic string) (SubscibeResult, error) {
+	s.mu.Lock()
+	shard, ok := s.topics[topic]
+	dial := false
+	if !ok {
+		shard, dial = s.acquire(topic)
+		// the concurrent subscriptions of the topic go to the same shard
+		s.topics[topic] = shard
+		s.reserved[shard]++
+	}
+	s.mu.Unlock()
+
+	if dial {
+		if err := shard.Connect(); err != nil {
+			logger.Printf("failed to connect the shard of topic=%s: %s", topic, err)
+		}
+	}
+
+	sub, err := shard.Subscribe(topic)
+
+	s.mu.Lock()
+	if !ok {
+		if s.reserved[shard]--; s.reserved[shard] == 0 {
+			delete(s.reserved, shard)
+		}
+	}
+	if err != nil {
+		if s.topics[topic] == shard && !shard.hasTopic(topic) {
+			delete(s.topics, topic)
+		}
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.topics[topic] = shard
+	s.mu.Unlock()
+
+	return &domain.Subscription[[]byte]{
+		Stream: sub.Stream,
+		Unsubscribe: func() {
+			sub.Unsubscribe()
+
+			s.mu.Lock()
+			if !shard.hasTopic(topic) {
+				delete(s.topics, topic)
+			}
+			s.mu.Unlock()
+		},
+		Topic: topic,
+	}, nil
+}
+
+// Shards returns the subscription lists of the shards. The pinned shards follow the general ones.
+func (s *BinanceStreamShards) Shards() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shards := s.allShards()
+	result := make([][]string, len(shards))
+	for i, shard := range shards {
+		result[i] = shard.Topics()
+	}
+
+	return result
+}
+
+// Rebuild subscribes again to all the streams of the shard in the order of Shards.
+func (s *BinanceStreamShards) Rebuild(shard int) error {
+	s.mu.Lock()
+	shards := s.allShards()
+	s.mu.Unlock()
+
+	if shard < 0 || shard >= len(shards) {
+		return nil
+	}
+
+	return shards[shard].Rebuild()
+}
+
+// acquire returns the shard for the new topic: the dedicated shard of the hot market,
+// or the least loaded shard below the cap. The new shard is returned with dial set, it is connected by the caller.
+// The subscriptions of the shard that failed to connect are queued.
+func (s *BinanceStreamShards) acquire(topic string) (*BinanceStreamClient, bool) {
+	market := topicMarket(topic)
+	if s.hotMarkets[market] {
+		if shard, ok := s.pinned[market]; ok {
+			return shard, false
+		}
+
+		shard := s.newShard()
+		s.pinned[market] = shard
+		return shard, true
+	}
+
+	var best *BinanceStreamClient
+	bestCount := s.maxStreams
+	for _, shard := range s.shards {
+		if count := shard.TopicCount() + s.reserved[shard]; count < bestCount {
+			best, bestCount = shard, count
+		}
+	}
+
+	if best != nil {
+		return best, false
+	}
+
+	return s.open(), true
+}
+
+// open adds a new shard. The shard is added even if it fails to connect, it keeps redialing in the background.
+func (s *BinanceStreamShards) open() *BinanceStreamClient {
+	shard := s.newShard()
+	s.shards = append(s.shards, shard)
+
+	return shard
+}
+
+func (s *BinanceStreamShards) newShard() *BinanceStreamClient {
+	shard := NewBinanceStreamClient(s.endpoints, s.dialer)
+	shard.recorder = s.recorder
+
+	return shard
+}
+
+func (s *BinanceStreamShards) allShards() []*BinanceStreamClient {
+	shards := append([]*BinanceStreamClient{}, s.shards...)
+
+	markets := make([]string, 0, len(s.pinned))
+	for market := range s.pinned {
+		markets = append(markets, market)
+	}
+	sort.Strings(markets)
+	for _, market := range markets {
+		shards = append(shards, s.pinned[market])
+	}
+
+	return shards
+}
+
+// topicMarket returns the market of the stream name, e.g. btcusdt for btcusdt@depth.
+func topicMarket(topic string) string {
+	market, _, _ := strings.Cut(topic, "@")
+	return market
+}
@@ -0,0 +1,186 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStreamServer records the subscribe requests of each connection. The dials are refused while rejectDials is set.
+type fakeStreamServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	requests    map[*websocket.Conn][]WebSocketRequestModel
+	rejectDials bool
+}
+
+func newFakeStreamServer(t *testing.T) *fakeStreamServer {
+	s := &fakeStreamServer{requests: make(map[*websocket.Conn][]WebSocketRequestModel)}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		reject := s.rejectDials
+		s.mu.Unlock()
+		if reject {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			req := WebSocketRequestModel{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.requests[conn] = append(s.requests[conn], req)
+			s.mu.Unlock()
+		}
+	}))
+
+	return s
+}
+
+// dropConnections closes the server side of all the connections.
+func (s *fakeStreamServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.requests {
+		conn.Close()
+	}
+}
+
+// allRequests returns the requests of all the connections.
+func (s *fakeStreamServer) allRequests() []WebSocketRequestModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []WebSocketRequestModel
+	for _, requests := range s.requests {
+		result = append(result, requests...)
+	}
+	return result
+}
+
+// push sends the message of the stream to all the connections.
+func (s *fakeStreamServer) push(topic, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.requests {
+		conn.WriteJSON(Message[json.RawMessage]{Stream: topic, Data: json.RawMessage(data)})
+	}
+}
+
+func (s *fakeStreamServer) connCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.requests)
+}
+
+func TestBinanceStreamShards(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	shards := NewBinanceStreamShards([]string{"ws" + strings.TrimPrefix(server.URL, "http")}, testDialer, 2, []string{"btc_usdt"})
+	assert.NoError(t, shards.Connect())
+	defer shards.Close()
+
+	for _, topic := range []string{"btcusdt@depth", "ethusdt@depth", "bnbusdt@depth", "xrpusdt@depth", "btcusdt@trade"} {
+		_, err := shards.Subscribe(topic)
+		assert.NoError(t, err)
+	}
+
+	// the general shards are capped by 2 streams, the hot market has the dedicated shard
+	assert.Equal(t, [][]string{
+		{"bnbusdt@depth", "ethusdt@depth"},
+		{"xrpusdt@depth"},
+		{"btcusdt@depth", "btcusdt@trade"},
+	}, shards.Shards())
+
+	assert.Eventually(t, func() bool {
+		return server.connCount() == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBinanceStreamShards_Unsubscribe(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	shards := NewBinanceStreamShards([]string{"ws" + strings.TrimPrefix(server.URL, "http")}, testDialer, 1, nil)
+	defer shards.Close()
+
+	eth, err := shards.Subscribe("ethusdt@depth")
+	assert.NoError(t, err)
+	eth.Unsubscribe()
+
+	// the released slot of the shard is reused
+	_, err = shards.Subscribe("bnbusdt@depth")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"bnbusdt@depth"}}, shards.Shards())
+}
+
+func TestBinanceStreamShards_QueuedSubscriptionDoesNotBlock(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	shards := NewBinanceStreamShards([]string{"ws" + strings.TrimPrefix(server.URL, "http")}, testDialer, 2, []string{"btc_usdt"})
+	assert.NoError(t, shards.Connect())
+	defer shards.Close()
+
+	server.mu.Lock()
+	server.rejectDials = true
+	server.mu.Unlock()
+
+	// the shard of the hot market fails to connect, its subscription waits for the connection
+	queued := make(chan error, 1)
+	go func() {
+		_, err := shards.Subscribe("btcusdt@depth")
+		queued <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return len(shards.Shards()) == 2
+	}, time.Second, 10*time.Millisecond, "the shard of the hot market should be added")
+
+	subscribed := make(chan error, 1)
+	go func() {
+		_, err := shards.Subscribe("ethusdt@depth")
+		subscribed <- err
+	}()
+
+	select {
+	case err := <-subscribed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the subscription of the connected shard is blocked by the queued one")
+	}
+
+	select {
+	case <-queued:
+		t.Fatal("the queued subscription should wait for the connection")
+	default:
+	}
+}
+
+func TestTopicMarket(t *testing.T) {
+	assert.Equal(t, "btcusdt", topicMarket("btcusdt@depth@100ms"))
+	assert.Equal(t, "btcusdt", topicMarket("btcusdt"))
+}
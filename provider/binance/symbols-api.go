@@ -0,0 +1,62 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const tradingStatus = "TRADING"
+
+// ExchangeInfo is the list of the markets of the spot and the futures exchangeInfo endpoints.
+type ExchangeInfo struct {
+	Symbols []struct {
+		Symbol     string `json:"symbol"`
+		Status     string `json:"status"`
+		BaseAsset  string `json:"baseAsset"`
+		QuoteAsset string `json:"quoteAsset"`
+		// Set only by the futures endpoint.
+		ContractType string `json:"contractType"`
+	} `json:"symbols"`
+}
+
+func (api *BinanceSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), binanceWSAPITimeout)
+	defer cancel()
+
+	info := &ExchangeInfo{}
+	if err := api.restGet(ctx, "exchangeInfo", url.Values{}, info); err != nil {
+		return nil, fmt.Errorf("failed to get exchange info: %w", err)
+	}
+
+	return info.markets("", api.symbols), nil
+}
+
+// Symbols returns the perpetual contracts, the delivery contracts are not served.
+func (api *BinanceFuturesSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	info := &ExchangeInfo{}
+	if err := api.get("exchangeInfo", url.Values{}, info); err != nil {
+		return nil, fmt.Errorf("failed to get exchange info: %w", err)
+	}
+
+	return info.markets("PERPETUAL", api.symbols), nil
+}
+
+func (info *ExchangeInfo) markets(contractType string, symbols *domain.SymbolMapper) []*domain.MarketSymbol {
+	result := make([]*domain.MarketSymbol, 0, len(info.Symbols))
+	for _, s := range info.Symbols {
+		if s.Status != tradingStatus || s.ContractType != contractType {
+			continue
+		}
+
+		symbol, err := symbols.Canonical(s.BaseAsset, s.QuoteAsset)
+		if err != nil {
+			continue
+		}
+		result = append(result, symbol)
+	}
+
+	return result
+}
@@ -0,0 +1,31 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinanceFuturesSyncAPI_Symbols(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/exchangeInfo", r.URL.Path)
+
+		w.Write([]byte(`{"symbols":[
+			{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT","contractType":"PERPETUAL"},
+			{"symbol":"BTCUSDT_240628","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT","contractType":"CURRENT_QUARTER"},
+			{"symbol":"ETHUSDT","status":"SETTLING","baseAsset":"ETH","quoteAsset":"USDT","contractType":"PERPETUAL"}
+		]}`))
+	}))
+	defer server.Close()
+
+	api := NewBinanceFuturesSyncAPI(server.URL, server.Client())
+
+	symbols, err := api.Symbols()
+
+	assert.NoError(t, err, "Unexpected error")
+	if assert.Len(t, symbols, 1, "Only the trading perpetual contracts should be listed") {
+		assert.Equal(t, "btc_usdt", symbols[0].String())
+	}
+}
@@ -1,100 +1,300 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"os"
-	"strings"
+	"net/url"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/spooky-finn/cryptobridge/domain"
+	promclient "github.com/spooky-finn/cryptobridge/infrastructure/prometheus"
 )
 
 var logger = log.New(log.Writer(), "[binance] ", log.LstdFlags)
 
-const ENDPOINT = ""
+const (
+	ENDPOINT = ""
+	// Time to wait for the response of the websocket api.
+	binanceWSAPITimeout  = 10 * time.Second
+	binanceWSAPIEndpoint = "wss://ws-api.binance.com:443/ws-api/v3"
+	binanceRestBaseURL   = "https://api.binance.com/api/v3"
+	maxReconnectDelay    = 30 * time.Second
 
-// Get OrderBookSnapshot (Depth)
+	// The transports of the snapshot requests reported in the metrics.
+	transportWebsocket = "websocket"
+	transportRest      = "rest"
+)
+
+var (
+	ErrTimeout          = errors.New("timeout error")
+	ErrConnectionClosed = errors.New("websocket api connection is closed")
+)
+
+// An APIError is the error payload of a websocket api or rest response.
+type APIError struct {
+	Status int
+	Code   int
+	Msg    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance error: status=%d, code=%d, msg=%s", e.Status, e.Code, e.Msg)
+}
+
+// BinanceSyncAPI serves the snapshots over the websocket api. The connection is redialed with the backoff,
+// the rest api is used while the websocket api is unavailable.
 type BinanceSyncAPI struct {
+	endpoint   string
+	restURL    string
+	dialer     *websocket.Dialer
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+
 	conn       *websocket.Conn
 	writeMutex sync.Mutex
-	in         chan []byte
+
+	mu sync.Mutex
+	// The channels of the requests waiting for the response, keyed by the request id.
+	pending map[int]chan *GenericMessage[json.RawMessage]
+	lastId  atomic.Int64
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 type GenericMessage[T any] struct {
-	ID     int `json:"id"`
-	Status int `json:"status"`
-	Result T   `json:"result"`
+	ID     int        `json:"id"`
+	Status int        `json:"status"`
+	Result T          `json:"result"`
+	Error  *ErrorData `json:"error"`
 }
 
-func NewBinanceAPI() *BinanceSyncAPI {
+// NewBinanceAPI dials the websocket api of the endpoint, the snapshots are requested from the rest api of the restURL
+// while the websocket api is unavailable.
+func NewBinanceAPI(endpoint, restURL string, dialer *websocket.Dialer, httpClient *http.Client) *BinanceSyncAPI {
 	logger.Println("instantiating binance websocket api")
 	instance := &BinanceSyncAPI{
-		in: make(chan []byte),
+		endpoint:   endpoint,
+		restURL:    restURL,
+		dialer:     dialer,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(ProviderName),
+		pending:    make(map[int]chan *GenericMessage[json.RawMessage]),
+		done:       make(chan struct{}),
 	}
 
-	Dialer := websocket.Dialer{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 5 * time.Second,
+	if err := instance.dial(); err != nil {
+		logger.Printf("error dialing binance sync ws api: %s", err.Error())
+		go instance.redial()
 	}
 
-	endpoint := os.Getenv("BINANCE_WS_API_ENDPOINT")
-	conn, _, err := Dialer.Dial(endpoint, nil)
+	return instance
+}
+
+func (api *BinanceSyncAPI) dial() error {
+	conn, _, err := api.dialer.Dial(api.endpoint, nil)
 	if err != nil {
-		logger.Printf("error dialing binance sync ws api: %s", err.Error())
+		return err
 	}
-	instance.conn = conn
 
-	go instance.listener(conn)
-	return instance
+	api.mu.Lock()
+	api.conn = conn
+	api.mu.Unlock()
+
+	go api.listener(conn)
+	return nil
+}
+
+// redial reconnects to the websocket api with the backoff until it succeeds or the api is closed.
+func (api *BinanceSyncAPI) redial() {
+	delay := time.Second
+	for {
+		select {
+		case <-api.done:
+			return
+		case <-time.After(delay):
+		}
+
+		err := api.dial()
+		if err == nil {
+			logger.Println("reconnected to the binance websocket api")
+			return
+		}
+
+		logger.Printf("failed to reconnect to the binance websocket api: %s", err)
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// IsConnected reports whether the snapshots are requested over the websocket api.
+func (api *BinanceSyncAPI) IsConnected() bool {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.conn != nil
+}
+
+func (api *BinanceSyncAPI) Close() error {
+	api.closeOnce.Do(func() { close(api.done) })
+
+	api.mu.Lock()
+	conn := api.conn
+	api.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
 }
 
 func (api *BinanceSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
-	reqId := getRandomReqID()
+	ctx, cancel := context.WithTimeout(context.Background(), binanceWSAPITimeout)
+	defer cancel()
+
+	return api.OrderBookSnapshotContext(ctx, symbol, limit)
+}
 
+// OrderBookSnapshotContext requests the snapshot and waits for the response until the context is done.
+// The rest api is requested if the websocket api is unavailable.
+func (api *BinanceSyncAPI) OrderBookSnapshotContext(ctx context.Context, symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
 	// params is a object of symbol and limit
 	params := map[string]interface{}{
-		"symbol": strings.ToUpper(symbol.Join("")),
+		"symbol": api.symbols.Native(symbol),
 		"limit":  fmt.Sprintf("%d", limit),
 	}
 
-	api.writeMutex.Lock()
-	err := api.conn.WriteJSON(map[string]interface{}{
-		"method": "depth",
-		"params": params,
-		"id":     reqId,
-	})
-	api.writeMutex.Unlock()
+	var data domain.OrderBookSnapshot
+	result, err := api.request(ctx, "depth", params)
 
-	if err != nil {
+	switch {
+	case err == nil:
+		promclient.SyncAPIRequestsCounter.WithLabelValues(ProviderName, transportWebsocket).Inc()
+		if err := json.Unmarshal(result, &data); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, ErrConnectionClosed):
+		logger.Printf("websocket api is unavailable, requesting the snapshot over rest: %s", err)
+		promclient.SyncAPIRequestsCounter.WithLabelValues(ProviderName, transportRest).Inc()
+		if err := api.restDepth(ctx, symbol, limit, &data); err != nil {
+			return nil, err
+		}
+	default:
 		return nil, err
 	}
 
-	msg, err := api.waitForResponse(reqId)
+	snapshot := &domain.OrderBookSnapshot{
+		Source:       domain.OrderBookSource_Provider,
+		LastUpdateId: data.LastUpdateId,
+		Bids:         data.Bids,
+		Asks:         data.Asks,
+	}
+
+	return snapshot, nil
+}
+
+// restDepth requests the snapshot from the rest depth endpoint.
+func (api *BinanceSyncAPI) restDepth(ctx context.Context, symbol *domain.MarketSymbol, limit int, v interface{}) error {
+	query := url.Values{}
+	query.Set("symbol", api.symbols.Native(symbol))
+	query.Set("limit", strconv.Itoa(limit))
+
+	return api.restGet(ctx, "depth", query, v)
+}
+
+func (api *BinanceSyncAPI) restGet(ctx context.Context, path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?%s", api.restURL, path, query.Encode()), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := api.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer resp.Body.Close()
 
-	// unmarshal message
-	var response GenericMessage[domain.OrderBookSnapshot]
-	err = json.Unmarshal(msg, &response)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	snapshot := &domain.OrderBookSnapshot{
-		Source:       domain.OrderBookSource_Provider,
-		LastUpdateId: response.Result.LastUpdateId,
-		Bids:         response.Result.Bids,
-		Asks:         response.Result.Asks,
+	if resp.StatusCode != http.StatusOK {
+		errData := &ErrorData{}
+		json.Unmarshal(body, errData)
+		return &APIError{Status: resp.StatusCode, Code: errData.Code, Msg: errData.Msg}
 	}
 
-	return snapshot, nil
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	return nil
+}
+
+// request sends the request and returns the result of the response with the same id.
+func (api *BinanceSyncAPI) request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	reqId := int(api.lastId.Add(1))
+	respCh := make(chan *GenericMessage[json.RawMessage], 1)
+
+	api.mu.Lock()
+	conn := api.conn
+	if conn == nil {
+		api.mu.Unlock()
+		return nil, ErrConnectionClosed
+	}
+	api.pending[reqId] = respCh
+	api.mu.Unlock()
+
+	defer func() {
+		api.mu.Lock()
+		delete(api.pending, reqId)
+		api.mu.Unlock()
+	}()
+
+	api.writeMutex.Lock()
+	err := conn.WriteJSON(map[string]interface{}{
+		"method": method,
+		"params": params,
+		"id":     reqId,
+	})
+	api.writeMutex.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrConnectionClosed, err)
+	}
+
+	select {
+	case response, ok := <-respCh:
+		if !ok {
+			return nil, ErrConnectionClosed
+		}
+		if response.Error != nil || response.Status != http.StatusOK {
+			apiErr := &APIError{Status: response.Status}
+			if response.Error != nil {
+				apiErr.Code = response.Error.Code
+				apiErr.Msg = response.Error.Msg
+			}
+			return nil, apiErr
+		}
+		return response.Result, nil
+
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	}
 }
 
 func (api *BinanceSyncAPI) listener(conn *websocket.Conn) {
@@ -102,38 +302,45 @@ func (api *BinanceSyncAPI) listener(conn *websocket.Conn) {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
 			logger.Println(err)
+			api.onDisconnect(conn)
 			return
 		}
-		api.in <- message
-	}
-}
-
-var ErrTimeout = errors.New("timeout error")
 
-func (api *BinanceSyncAPI) waitForResponse(messageId int) ([]byte, error) {
-	for {
-		select {
-		case msg := <-api.in:
-			// unmarshal message
-			var response map[string]interface{}
-			err := json.Unmarshal(msg, &response)
-			if err != nil {
-				return nil, err
-			}
+		var response GenericMessage[json.RawMessage]
+		if err := json.Unmarshal(message, &response); err != nil {
+			logger.Printf("failed to unmarshal websocket api response: %s", err)
+			continue
+		}
 
-			// try to get id from response message and compare it with messageId
-			if response["id"] != nil {
-				id := int(response["id"].(float64))
-				if id != messageId {
-					continue
-				}
-				return msg, nil
-			}
+		api.mu.Lock()
+		respCh, ok := api.pending[response.ID]
+		if ok {
+			// the response is delivered once, the channel is buffered
+			delete(api.pending, response.ID)
+			respCh <- &response
+		}
+		api.mu.Unlock()
+	}
+}
 
-			continue
+// onDisconnect releases the requests waiting on the lost connection and redials unless the api is closed.
+func (api *BinanceSyncAPI) onDisconnect(conn *websocket.Conn) {
+	api.mu.Lock()
+	current := api.conn == conn
+	if current {
+		api.conn = nil
+	}
+	for id, respCh := range api.pending {
+		close(respCh)
+		delete(api.pending, id)
+	}
+	api.mu.Unlock()
 
-		case <-time.After(10 * time.Second):
-			return nil, ErrTimeout
+	select {
+	case <-api.done:
+	default:
+		if current {
+			go api.redial()
 		}
 	}
 }
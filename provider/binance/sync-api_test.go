@@ -1,10 +1,21 @@
 package binance
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/joho/godotenv"
 	"github.com/spooky-finn/cryptobridge/domain"
+	promclient "github.com/spooky-finn/cryptobridge/infrastructure/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,7 +24,7 @@ func TestBinanceWSAPI_GetOrderBookSnapshot(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Create a new BinanceWSAPI instance
-	api := NewBinanceAPI()
+	api := newTestBinanceAPI(os.Getenv("BINANCE_WS_API_ENDPOINT"), binanceRestBaseURL)
 
 	// Define a market symbol and limit for the test
 	symbol, err := domain.NewMarketSymbol("xmr", "btc")
@@ -33,3 +44,161 @@ func TestBinanceWSAPI_GetOrderBookSnapshot(t *testing.T) {
 	// Cleanup - close the connection
 	api.conn.Close()
 }
+
+// newFakeWSAPIServer answers the depth requests in the reverse order of the batches of the given size.
+func newFakeWSAPIServer(t *testing.T, batch int) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		var requests []map[string]interface{}
+		for {
+			req := map[string]interface{}{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			requests = append(requests, req)
+			if len(requests) < batch {
+				continue
+			}
+
+			for i := len(requests) - 1; i >= 0; i-- {
+				id := requests[i]["id"]
+				symbol := requests[i]["params"].(map[string]interface{})["symbol"].(string)
+
+				if symbol == "FOOBAR" {
+					conn.WriteJSON(map[string]interface{}{
+						"id": id, "status": 400,
+						"error": map[string]interface{}{"code": -1121, "msg": "Invalid symbol."},
+					})
+					continue
+				}
+				conn.WriteJSON(map[string]interface{}{
+					"id": id, "status": 200,
+					"result": map[string]interface{}{
+						"lastUpdateId": 100,
+						"bids":         [][]string{{symbol, "1"}},
+						"asks":         [][]string{{symbol, "2"}},
+					},
+				})
+			}
+			requests = nil
+		}
+	}))
+}
+
+func TestBinanceSyncAPI_ConcurrentRequests(t *testing.T) {
+	server := newFakeWSAPIServer(t, 2)
+	defer server.Close()
+
+	api := newTestBinanceAPI("ws"+strings.TrimPrefix(server.URL, "http"), binanceRestBaseURL)
+	defer api.Close()
+
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+	eth, _ := domain.NewMarketSymbol("eth", "usdt")
+
+	wg := sync.WaitGroup{}
+	for _, symbol := range []*domain.MarketSymbol{btc, eth} {
+		wg.Add(1)
+		go func(symbol *domain.MarketSymbol) {
+			defer wg.Done()
+
+			snapshot, err := api.OrderBookSnapshot(symbol, 5)
+			assert.NoError(t, err)
+			// each caller receives the response to its own request
+			assert.Equal(t, strings.ToUpper(symbol.Join("")), snapshot.Bids[0][0])
+		}(symbol)
+	}
+	wg.Wait()
+}
+
+func TestBinanceSyncAPI_ErrorPayload(t *testing.T) {
+	server := newFakeWSAPIServer(t, 1)
+	defer server.Close()
+
+	api := newTestBinanceAPI("ws"+strings.TrimPrefix(server.URL, "http"), binanceRestBaseURL)
+	defer api.Close()
+
+	symbol, _ := domain.NewMarketSymbol("foo", "bar")
+	_, err := api.OrderBookSnapshot(symbol, 5)
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 400, apiErr.Status)
+	assert.Equal(t, -1121, apiErr.Code)
+}
+
+func TestBinanceSyncAPI_ContextCancel(t *testing.T) {
+	// the server waits for the second request that is never sent
+	server := newFakeWSAPIServer(t, 2)
+	defer server.Close()
+
+	api := newTestBinanceAPI("ws"+strings.TrimPrefix(server.URL, "http"), binanceRestBaseURL)
+	defer api.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	_, err := api.OrderBookSnapshotContext(ctx, symbol, 5)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Empty(t, api.pending)
+}
+
+func TestBinanceSyncAPI_Redial(t *testing.T) {
+	server := newFakeWSAPIServer(t, 1)
+	defer server.Close()
+
+	api := newTestBinanceAPI("ws"+strings.TrimPrefix(server.URL, "http"), binanceRestBaseURL)
+	defer api.Close()
+
+	// the connection is lost
+	api.mu.Lock()
+	api.conn.Close()
+	api.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return api.IsConnected()
+	}, 5*time.Second, 50*time.Millisecond)
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	snapshot, err := api.OrderBookSnapshot(symbol, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), snapshot.LastUpdateId)
+}
+
+func TestBinanceSyncAPI_RestFallback(t *testing.T) {
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/depth", r.URL.Path)
+		assert.Equal(t, "BTCUSDT", r.URL.Query().Get("symbol"))
+		w.Write([]byte(`{"lastUpdateId":42,"bids":[["1","1"]],"asks":[["2","1"]]}`))
+	}))
+	defer rest.Close()
+
+	// the websocket api is unreachable
+	ws := httptest.NewServer(http.NotFoundHandler())
+	ws.Close()
+
+	api := newTestBinanceAPI("ws"+strings.TrimPrefix(ws.URL, "http"), rest.URL)
+	defer api.Close()
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	before := testutil.ToFloat64(promclient.SyncAPIRequestsCounter.WithLabelValues(ProviderName, transportRest))
+
+	snapshot, err := api.OrderBookSnapshot(symbol, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), snapshot.LastUpdateId)
+	assert.Equal(t, before+1, testutil.ToFloat64(promclient.SyncAPIRequestsCounter.WithLabelValues(ProviderName, transportRest)))
+}
+
+// newTestBinanceAPI creates the api of the endpoints with the default transports.
+func newTestBinanceAPI(endpoint, restURL string) *BinanceSyncAPI {
+	opts := domain.ProviderOptions{}
+	return NewBinanceAPI(endpoint, restURL, opts.NewDialer(), opts.NewHTTPClient())
+}
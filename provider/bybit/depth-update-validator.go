@@ -0,0 +1,26 @@
+package bybit
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+// BybitDepthUpdateValidator checks that the update id of the delta follows the update id of the order book.
+type BybitDepthUpdateValidator struct{}
+
+func (v *BybitDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	if update.SequenceStart != orderBookLastUpdId+1 {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+func (v *BybitDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *BybitDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
@@ -0,0 +1,54 @@
+package bybit
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthUpdateValidator(t *testing.T) {
+	v := &BybitDepthUpdateValidator{}
+	upd := &domain.OrderBookUpdate{SequenceStart: 124, SequenceEnd: 124}
+
+	err := v.IsValidUpd(upd, 124)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	err = v.IsValidUpd(upd, 123)
+	assert.Nil(t, err, "Error should be nil")
+
+	err = v.IsValidUpd(upd, 120)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
+
+func TestParseOrderBookUpdate(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	update, err := parseOrderBookUpdate(&PushMessage{
+		Type: "delta",
+		Data: []byte(`{"s":"BTCUSDT","b":[["16493.50","0.006"]],"a":[["16611.00","0"]],"u":18521288,"seq":7961638724}`),
+	}, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.False(t, update.IsSnapshot, "Update should not be a snapshot")
+	assert.Equal(t, int64(18521288), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, [][]string{{"16493.50", "0.006"}}, update.Bids, "Bids should match")
+
+	// u=1 means the service was restarted and the message contains the whole book
+	update, err = parseOrderBookUpdate(&PushMessage{
+		Type: "delta",
+		Data: []byte(`{"s":"BTCUSDT","b":[],"a":[],"u":1}`),
+	}, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.True(t, update.IsSnapshot, "Update should be a snapshot")
+}
+
+func TestCategory(t *testing.T) {
+	assert.Equal(t, "bybit", CategorySpot.ProviderName())
+	assert.Equal(t, "bybit-linear", CategoryLinear.ProviderName())
+
+	api := NewBybitStreamAPI(CategoryLinear, nil, nil, nil)
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	assert.Equal(t, "orderbook.500.BTCUSDT", api.orderBookTopic(symbol))
+}
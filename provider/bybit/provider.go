@@ -0,0 +1,70 @@
+package bybit
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const (
+	ProviderName       = "bybit"
+	LinearProviderName = "bybit-linear"
+)
+
+// Category is the product type of bybit. Each category has its own websocket endpoint.
+type Category string
+
+const (
+	CategorySpot   Category = "spot"
+	CategoryLinear Category = "linear"
+)
+
+func (c Category) ProviderName() string {
+	if c == CategoryLinear {
+		return LinearProviderName
+	}
+	return ProviderName
+}
+
+// StreamDepth is the deepest orderbook topic available for the category.
+func (c Category) StreamDepth() int {
+	if c == CategoryLinear {
+		return 500
+	}
+	return 200
+}
+
+// SymbolMapper creates the mapper of the category, the symbols are written without a separator, e.g. BTCUSDT.
+func (c Category) SymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(c.ProviderName(), domain.SymbolFormat{})
+}
+
+// MaxSnapshotDepth is the max limit of the REST orderbook endpoint.
+func (c Category) MaxSnapshotDepth() int {
+	if c == CategoryLinear {
+		return 500
+	}
+	return 200
+}
+
+// NewProvider instantiates the spot provider.
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	return newProvider(CategorySpot, opts)
+}
+
+// NewLinearProvider instantiates the provider of the linear perpetual contracts.
+func NewLinearProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	return newProvider(CategoryLinear, opts)
+}
+
+func newProvider(category Category, opts domain.ProviderOptions) (*domain.Provider, error) {
+	streamClient := NewBybitStreamClient(opts.StreamURLOr(bybitDefaultWebsocketEndpoint+string(category)), opts.NewDialer())
+	syncAPI := NewBybitSyncAPI(category, opts.RestURLOr(bybitDefaultBaseURL), opts.NewHTTPClient())
+	validator := &BybitDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 category.ProviderName(),
+		StreamClient:         streamClient,
+		StreamAPI:            NewBybitStreamAPI(category, streamClient, syncAPI, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         category.SymbolMapper(),
+	}, nil
+}
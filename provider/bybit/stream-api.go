@@ -0,0 +1,101 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type BybitStreamAPI struct {
+	category     Category
+	streamClient *BybitStreamClient
+	syncAPI      *BybitSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type OrderBookData struct {
+	Symbol   string     `json:"s"`
+	Bids     [][]string `json:"b"`
+	Asks     [][]string `json:"a"`
+	UpdateId int64      `json:"u"`
+	Seq      int64      `json:"seq"`
+}
+
+func NewBybitStreamAPI(category Category, client *BybitStreamClient, syncAPI *BybitSyncAPI, validator domain.IDepthUpdateValidator) *BybitStreamAPI {
+	return &BybitStreamAPI{
+		category:     category,
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      category.SymbolMapper(),
+	}
+}
+
+func (s *BybitStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	subscribtion, err := s.streamClient.Subscribe(s.orderBookTopic(symbol))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		for msg := range subscribtion.Stream {
+			if msg.Type == DisconnectMessage {
+				// the deltas are lost until the topic is subscribed again
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			update, err := parseOrderBookUpdate(msg, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			out <- update
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       subscribtion.Topic,
+	}, nil
+}
+
+// RequestSnapshot resubscribes to the topic, bybit sends the snapshot on every subscription.
+func (s *BybitStreamAPI) RequestSnapshot(symbol *domain.MarketSymbol) error {
+	return s.streamClient.Resubscribe(s.orderBookTopic(symbol))
+}
+
+// GetOrderBook builds the order book from the snapshot of the stream.
+// bybit pushes the snapshot on subscription and after the service restart, so the sync API is not involved.
+func (s *BybitStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.syncAPI, s.validator)
+	return maintainer.CreateOrderBookFromStream(s.category.ProviderName(), symbol)
+}
+
+func (s *BybitStreamAPI) orderBookTopic(symbol *domain.MarketSymbol) string {
+	return fmt.Sprintf("orderbook.%d.%s", s.category.StreamDepth(), s.symbols.Native(symbol))
+}
+
+func parseOrderBookUpdate(msg *PushMessage, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	data := &OrderBookData{}
+	if err := json.Unmarshal(msg.Data, data); err != nil {
+		return nil, err
+	}
+
+	update := domain.NewOrderBookUpdate(
+		data.Bids, data.Asks,
+		data.UpdateId, data.UpdateId,
+		symbol,
+	)
+	// u=1 is sent in the delta as well after the service restart, it must be treated as a snapshot.
+	update.IsSnapshot = msg.Type == "snapshot" || data.UpdateId == 1
+
+	return update, nil
+}
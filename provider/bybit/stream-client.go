@@ -0,0 +1,353 @@
+package bybit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/helpers"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[bybit] ", log.LstdFlags)
+
+const (
+	bybitDefaultWebsocketEndpoint = "wss://stream.bybit.com/v5/public/"
+	bybitDefaultTimeout           = 10 * time.Second
+	// bybit recommends to send the ping every 20 seconds to keep the connection alive.
+	pingInterval = 20 * time.Second
+)
+
+// Type of the message emitted to the subscribers when the connection is lost.
+const DisconnectMessage = "disconnect"
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+type WebSocketRequestModel struct {
+	ReqId string   `json:"req_id"`
+	Op    string   `json:"op"`
+	Args  []string `json:"args,omitempty"`
+}
+
+type WebSocketResponseModel struct {
+	Success bool            `json:"success"`
+	RetMsg  string          `json:"ret_msg"`
+	ReqId   string          `json:"req_id"`
+	Op      string          `json:"op"`
+	Topic   string          `json:"topic"`
+	Type    string          `json:"type"`
+	Ts      int64           `json:"ts"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// A PushMessage is the data pushed by the topic with its type: snapshot or delta.
+type PushMessage struct {
+	Type string
+	Data json.RawMessage
+}
+
+// A SubscribtionEntry is the upstream subscription to the topic shared by the consumers.
+// Each consumer has its own channel, the disconnect message is sent to it when the connection is lost.
+type SubscribtionEntry struct {
+	consumers map[int64]*domain.StreamConsumer[*PushMessage]
+}
+
+// BybitStreamClient is the connection to the public websocket. The lost connection is redialed with the backoff
+// and the topics are subscribed again, so bybit sends the new snapshots.
+type BybitStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	conn     *websocket.Conn
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	pending       map[string]chan *WebSocketResponseModel
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewBybitStreamClient(endpoint string, dialer *websocket.Dialer) *BybitStreamClient {
+	return &BybitStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		subscriptions: make(map[string]*SubscribtionEntry),
+		pending:       make(map[string]chan *WebSocketResponseModel),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *BybitStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the bybit websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	go c.keepHeartbeat(conn)
+	return nil
+}
+
+func (c *BybitStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes to the topic. The upstream subscription is shared by the consumers of the same topic,
+// it is made only for the first consumer and released when the last one unsubscribes.
+func (c *BybitStreamClient) Subscribe(topic string) (*domain.Subscription[*PushMessage], error) {
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer(2048, &PushMessage{Type: DisconnectMessage})
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(topic, consumerId, consumer), nil
+	}
+
+	c.subscriptions[topic] = &SubscribtionEntry{
+		consumers: map[int64]*domain.StreamConsumer[*PushMessage]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", topic)
+	}
+
+	if err := c.send("subscribe", topic); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, topic)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to topic=%s: %w", topic, err)
+	}
+
+	return c.newSubscription(topic, consumerId, consumer), nil
+}
+
+// Resubscribe renews the subscription on the server side. The subscribers keep their channels.
+func (c *BybitStreamClient) Resubscribe(topic string) error {
+	if err := c.send("unsubscribe", topic); err != nil {
+		return err
+	}
+
+	return c.send("subscribe", topic)
+}
+
+func (c *BybitStreamClient) newSubscription(topic string, consumerId int64, consumer *domain.StreamConsumer[*PushMessage]) *domain.Subscription[*PushMessage] {
+	return &domain.Subscription[*PushMessage]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", topic, err)
+			}
+		},
+		Topic: topic,
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *BybitStreamClient) unsubscribe(topic string, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+
+	return c.send("unsubscribe", topic)
+}
+
+// send writes the operation and waits for the response with the same req_id.
+func (c *BybitStreamClient) send(op string, args ...string) error {
+	reqId := helpers.IntToString(time.Now().UnixNano())
+	respCh := make(chan *WebSocketResponseModel, 1)
+
+	c.mu.Lock()
+	conn := c.conn
+	c.pending[reqId] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, reqId)
+		c.mu.Unlock()
+	}()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	err := c.write(conn, WebSocketRequestModel{ReqId: reqId, Op: op, Args: args})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-respCh:
+		if !resp.Success {
+			return fmt.Errorf("bybit error: %s", resp.RetMsg)
+		}
+		return nil
+	case <-time.After(bybitDefaultTimeout):
+		return fmt.Errorf("triggered wait response timeout in %v", bybitDefaultTimeout)
+	}
+}
+
+func (c *BybitStreamClient) write(conn *websocket.Conn, msg interface{}) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	return conn.WriteJSON(msg)
+}
+
+func (c *BybitStreamClient) read(conn *websocket.Conn) {
+	for {
+		resp := &WebSocketResponseModel{}
+		if err := conn.ReadJSON(resp); err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		if resp.Topic != "" {
+			for _, consumer := range c.consumers(resp.Topic) {
+				consumer.Send(&PushMessage{Type: resp.Type, Data: resp.Data})
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		respCh, ok := c.pending[resp.ReqId]
+		c.mu.Unlock()
+		if ok {
+			select {
+			case respCh <- resp:
+			default:
+			}
+		}
+	}
+}
+
+// consumers returns the consumers of the topic. The messages are sent to them without holding the mutex.
+func (c *BybitStreamClient) consumers(topic string) []*domain.StreamConsumer[*PushMessage] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[*PushMessage], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect redials with the backoff unless the connection was closed by the client.
+// The consumers are interrupted before the topics are subscribed again, bybit sends the snapshots on the subscription.
+func (c *BybitStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic, entry := range c.subscriptions {
+		topics = append(topics, topic)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(topics) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the bybit websocket, %d topics are subscribed", len(topics))
+	}
+}
+
+func (c *BybitStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *BybitStreamClient) resubscribe(topics []string) error {
+	for _, topic := range topics {
+		if err := c.send("subscribe", topic); err != nil {
+			return fmt.Errorf("topic=%s: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *BybitStreamClient) keepHeartbeat(conn *websocket.Conn) {
+	pt := time.NewTicker(pingInterval)
+	defer pt.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-pt.C:
+			err := c.write(conn, WebSocketRequestModel{ReqId: "ping", Op: "ping"})
+			if err != nil {
+				logger.Printf("err while writing ping message to the conn: %s", err.Error())
+				return
+			}
+		}
+	}
+}
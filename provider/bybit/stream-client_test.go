@@ -0,0 +1,65 @@
+package bybit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeStreamServer answers the operations and pushes the snapshot to the connection on every subscription.
+func newFakeStreamServer(t *testing.T) *wstest.Server {
+	return wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := WebSocketRequestModel{}
+		json.Unmarshal(msg, &req)
+
+		replies := []interface{}{WebSocketResponseModel{Success: true, ReqId: req.ReqId, Op: req.Op}}
+		if req.Op != "subscribe" {
+			return replies, nil
+		}
+		replies = append(replies, WebSocketResponseModel{Topic: req.Args[0], Type: "snapshot", Data: json.RawMessage(`{"u":1}`)})
+		return replies, req.Args
+	})
+}
+
+func delta(topic, data string) WebSocketResponseModel {
+	return WebSocketResponseModel{Topic: topic, Type: "delta", Data: json.RawMessage(data)}
+}
+
+func TestBybitStreamClient_Reconnect(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewBybitStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	topic := "orderbook.50.BTCUSDT"
+	first, err := client.Subscribe(topic)
+	assert.NoError(t, err)
+	assert.Equal(t, "snapshot", wstest.Receive(t, first.Stream).Type)
+	second, err := client.Subscribe(topic)
+	assert.NoError(t, err)
+
+	// each consumer receives all the messages
+	server.Push(topic, delta(topic, `{"u":2}`))
+	assert.Equal(t, `{"u":2}`, string(wstest.Receive(t, first.Stream).Data))
+	assert.Equal(t, `{"u":2}`, string(wstest.Receive(t, second.Stream).Data))
+
+	server.DropConnections()
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, first.Stream).Type, "the consumer should be interrupted")
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, second.Stream).Type, "the consumer should be interrupted")
+
+	// the topic is subscribed again on the new connection, bybit sends the snapshot
+	assert.Equal(t, "snapshot", wstest.Receive(t, first.Stream).Type)
+	assert.Equal(t, "snapshot", wstest.Receive(t, second.Stream).Type)
+}
+
+func TestBybitStreamClient_CloseTwice(t *testing.T) {
+	client := NewBybitStreamClient("ws://127.0.0.1:0", websocket.DefaultDialer)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+}
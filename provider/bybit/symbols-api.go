@@ -0,0 +1,83 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type InstrumentsResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Symbol    string `json:"symbol"`
+			BaseCoin  string `json:"baseCoin"`
+			QuoteCoin string `json:"quoteCoin"`
+			Status    string `json:"status"`
+			// Set only for the derivatives.
+			ContractType string `json:"contractType"`
+		} `json:"list"`
+		NextPageCursor string `json:"nextPageCursor"`
+	} `json:"result"`
+}
+
+// Symbols returns the markets of the category, the linear category is limited to the perpetual contracts.
+func (api *BybitSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	result := []*domain.MarketSymbol{}
+
+	cursor := ""
+	for {
+		query := url.Values{}
+		query.Set("category", string(api.category))
+		query.Set("limit", "1000")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		data, err := api.instruments(query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range data.Result.List {
+			if inst.Status != "Trading" || (api.category == CategoryLinear && inst.ContractType != "LinearPerpetual") {
+				continue
+			}
+			if symbol, err := api.symbols.Canonical(inst.BaseCoin, inst.QuoteCoin); err == nil {
+				result = append(result, symbol)
+			}
+		}
+
+		if cursor = data.Result.NextPageCursor; cursor == "" {
+			return result, nil
+		}
+	}
+}
+
+func (api *BybitSyncAPI) instruments(query url.Values) (*InstrumentsResponse, error) {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/market/instruments-info?%s", api.baseURL, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instruments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &InstrumentsResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if data.RetCode != 0 {
+		return nil, fmt.Errorf("failed to get instruments: code=%d, msg=%s", data.RetCode, data.RetMsg)
+	}
+
+	return data, nil
+}
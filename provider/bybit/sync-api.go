@@ -0,0 +1,79 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const bybitDefaultBaseURL = "https://api.bybit.com/v5"
+
+// BybitSyncAPI requests the order book over REST. It serves the provider snapshots while the local order book is initializing.
+type BybitSyncAPI struct {
+	category   Category
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+type OrderBookResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		OrderBookData
+		Ts int64 `json:"ts"`
+	} `json:"result"`
+}
+
+func NewBybitSyncAPI(category Category, baseURL string, httpClient *http.Client) *BybitSyncAPI {
+	return &BybitSyncAPI{
+		category:   category,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    category.SymbolMapper(),
+	}
+}
+
+func (api *BybitSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if max := api.category.MaxSnapshotDepth(); limit > max {
+		limit = max
+	}
+
+	query := url.Values{}
+	query.Set("category", string(api.category))
+	query.Set("symbol", api.symbols.Native(symbol))
+	query.Set("limit", strconv.Itoa(limit))
+
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/market/orderbook?%s", api.baseURL, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &OrderBookResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if data.RetCode != 0 {
+		return nil, fmt.Errorf("failed to get order book snapshot: code=%d, msg=%s", data.RetCode, data.RetMsg)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:         domain.OrderBookSource_Provider,
+		LastUpdateId:   data.Result.UpdateId,
+		LastUpdateTime: data.Result.Ts,
+		Bids:           data.Result.Bids,
+		Asks:           data.Result.Asks,
+	}, nil
+}
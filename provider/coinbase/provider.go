@@ -0,0 +1,26 @@
+package coinbase
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const ProviderName = "coinbase"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	streamClient := NewCoinbaseStreamClient(opts.StreamURLOr(coinbaseDefaultWebsocketEndpoint), opts.NewDialer(), level2Channel, heartbeatChannel)
+	syncAPI := NewCoinbaseSyncAPI(opts.RestURLOr(coinbaseDefaultBaseURL), opts.NewHTTPClient())
+	validator := &domain.ContinuityDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewCoinbaseStreamAPI(streamClient, syncAPI, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the products, e.g. BTC-USD.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Separator: "-"})
+}
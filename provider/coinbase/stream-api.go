@@ -0,0 +1,174 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	level2Channel    = "level2_batch"
+	heartbeatChannel = "heartbeat"
+
+	// coinbase sends the heartbeat every second, the stream is considered interrupted if nothing is received in the timeout.
+	heartbeatTimeout = 5 * time.Second
+)
+
+type CoinbaseStreamAPI struct {
+	streamClient *CoinbaseStreamClient
+	syncAPI      domain.ProviderSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type SnapshotData struct {
+	Bids [][]string `json:"bids"`
+	Asks [][]string `json:"asks"`
+}
+
+// L2UpdateData contains the changes of the book in the form of [side, price, size].
+type L2UpdateData struct {
+	Changes [][]string `json:"changes"`
+}
+
+func NewCoinbaseStreamAPI(client *CoinbaseStreamClient, syncAPI domain.ProviderSyncAPI, validator domain.IDepthUpdateValidator) *CoinbaseStreamAPI {
+	return &CoinbaseStreamAPI{
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(),
+	}
+}
+
+// DepthDiffStream emits the snapshots and the updates of the level2_batch channel.
+// coinbase doesn't number the updates of the channel, so the sequence is assigned locally in the order of the receiving.
+// The continuity is watched by the heartbeats instead: the interrupted update is emitted when the connection is lost
+// or the heartbeats are missed.
+func (s *CoinbaseStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	subscribtion, err := s.streamClient.Subscribe(s.symbols.Native(symbol))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		var sequence int64
+		watchdog := time.NewTimer(heartbeatTimeout)
+		defer watchdog.Stop()
+
+		interrupted := false
+		interrupt := func() {
+			if interrupted {
+				return
+			}
+			interrupted = true
+
+			sequence++
+			update := domain.NewInterruptedUpdate(symbol)
+			update.SequenceStart, update.SequenceEnd = sequence, sequence
+			out <- update
+		}
+
+		for {
+			select {
+			case <-watchdog.C:
+				logger.Printf("heartbeat is missed, product=%s", subscribtion.Topic)
+				interrupt()
+				watchdog.Reset(heartbeatTimeout)
+
+			case msg, ok := <-subscribtion.Stream:
+				if !ok {
+					return
+				}
+
+				if !watchdog.Stop() {
+					select {
+					case <-watchdog.C:
+					default:
+					}
+				}
+				watchdog.Reset(heartbeatTimeout)
+
+				switch msg.Type {
+				case DisconnectMessage:
+					interrupt()
+					continue
+				case heartbeatChannel:
+					continue
+				}
+
+				update, err := parseUpdate(msg, sequence+1, symbol)
+				if err != nil {
+					logger.Printf("Error unmarshaling message: %s", err)
+					continue
+				}
+				if update == nil {
+					continue
+				}
+
+				if update.IsSnapshot {
+					interrupted = false
+				}
+				sequence++
+				out <- update
+			}
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       subscribtion.Topic,
+	}, nil
+}
+
+// RequestSnapshot resubscribes to the product, coinbase sends the snapshot on every subscription.
+func (s *CoinbaseStreamAPI) RequestSnapshot(symbol *domain.MarketSymbol) error {
+	return s.streamClient.Resubscribe(s.symbols.Native(symbol))
+}
+
+func (s *CoinbaseStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.syncAPI, s.validator)
+	return maintainer.CreateOrderBookFromStream(ProviderName, symbol)
+}
+
+// parseUpdate converts the snapshot and l2update messages. It returns nil for the other messages.
+func parseUpdate(msg *PushMessage, sequence int64, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	switch msg.Type {
+	case "snapshot":
+		data := &SnapshotData{}
+		if err := json.Unmarshal(msg.Data, data); err != nil {
+			return nil, err
+		}
+
+		update := domain.NewOrderBookUpdate(data.Bids, data.Asks, sequence, sequence, symbol)
+		update.IsSnapshot = true
+		return update, nil
+
+	case "l2update":
+		data := &L2UpdateData{}
+		if err := json.Unmarshal(msg.Data, data); err != nil {
+			return nil, err
+		}
+
+		bids, asks := make([][]string, 0), make([][]string, 0)
+		for _, change := range data.Changes {
+			if len(change) < 3 {
+				continue
+			}
+
+			if change[0] == "buy" {
+				bids = append(bids, change[1:3])
+			} else {
+				asks = append(asks, change[1:3])
+			}
+		}
+
+		return domain.NewOrderBookUpdate(bids, asks, sequence, sequence, symbol), nil
+	}
+
+	return nil, nil
+}
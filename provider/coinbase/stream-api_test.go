@@ -0,0 +1,55 @@
+package coinbase
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUpdate_Snapshot(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+	msg := &PushMessage{
+		Type: "snapshot",
+		Data: []byte(`{"type":"snapshot","product_id":"BTC-USD","bids":[["10101.10","0.45054140"]],"asks":[["10102.55","0.57753524"]]}`),
+	}
+
+	update, err := parseUpdate(msg, 1, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.True(t, update.IsSnapshot, "Update should be the snapshot")
+	assert.Equal(t, [][]string{{"10101.10", "0.45054140"}}, update.Bids, "Bids should match")
+	assert.Equal(t, [][]string{{"10102.55", "0.57753524"}}, update.Asks, "Asks should match")
+}
+
+func TestParseUpdate_L2Update(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+	msg := &PushMessage{
+		Type: "l2update",
+		Data: []byte(`{"type":"l2update","product_id":"BTC-USD","changes":[["buy","10101.80000000","0.162567"],["sell","10102.55","0"]]}`),
+	}
+
+	update, err := parseUpdate(msg, 5, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.False(t, update.IsSnapshot, "Update should not be the snapshot")
+	assert.Equal(t, int64(5), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, [][]string{{"10101.80000000", "0.162567"}}, update.Bids, "Bids should match")
+	assert.Equal(t, [][]string{{"10102.55", "0"}}, update.Asks, "Asks should match")
+}
+
+func TestParseUpdate_Unknown(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+	msg := &PushMessage{Type: "ticker", Data: []byte(`{"type":"ticker","product_id":"BTC-USD"}`)}
+
+	update, err := parseUpdate(msg, 1, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Nil(t, update, "Update should be nil")
+}
+
+func TestSymbolMapper(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+
+	assert.Equal(t, "BTC-USD", newSymbolMapper().Native(symbol))
+}
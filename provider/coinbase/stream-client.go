@@ -0,0 +1,351 @@
+package coinbase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[coinbase] ", log.LstdFlags)
+
+const (
+	coinbaseDefaultWebsocketEndpoint = "wss://ws-feed.exchange.coinbase.com"
+	coinbaseDefaultTimeout           = 10 * time.Second
+)
+
+// Type of the message emitted to the subscribers when the connection is lost.
+const DisconnectMessage = "disconnect"
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+type WebSocketRequestModel struct {
+	Type       string   `json:"type"`
+	ProductIds []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+
+	Signature  string `json:"signature,omitempty"`
+	Key        string `json:"key,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+	Timestamp  string `json:"timestamp,omitempty"`
+}
+
+// A PushMessage is a message of the product with its type: snapshot, l2update, heartbeat or disconnect.
+type PushMessage struct {
+	Type string
+	Data json.RawMessage
+}
+
+// A SubscribtionEntry is the upstream subscription to the product shared by the consumers.
+// Each consumer has its own channel, the disconnect message is sent to it when the connection is lost.
+type SubscribtionEntry struct {
+	consumers map[int64]*domain.StreamConsumer[*PushMessage]
+}
+
+type CoinbaseStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	channels []string
+	conn     *websocket.Conn
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	acks          chan *WebSocketResponseModel
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+type WebSocketResponseModel struct {
+	Type      string `json:"type"`
+	ProductId string `json:"product_id"`
+	Message   string `json:"message"`
+	Reason    string `json:"reason"`
+}
+
+// NewCoinbaseStreamClient creates the client subscribing each product to the channels.
+func NewCoinbaseStreamClient(endpoint string, dialer *websocket.Dialer, channels ...string) *CoinbaseStreamClient {
+	return &CoinbaseStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		channels:      channels,
+		subscriptions: make(map[string]*SubscribtionEntry),
+		acks:          make(chan *WebSocketResponseModel, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *CoinbaseStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the coinbase websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	return nil
+}
+
+func (c *CoinbaseStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes the product to the channels of the client. The upstream subscription is shared by the consumers
+// of the same product, it is made only for the first consumer and released when the last one unsubscribes.
+func (c *CoinbaseStreamClient) Subscribe(productId string) (*domain.Subscription[*PushMessage], error) {
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer(2048, &PushMessage{Type: DisconnectMessage})
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[productId]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(productId, consumerId, consumer), nil
+	}
+
+	c.subscriptions[productId] = &SubscribtionEntry{
+		consumers: map[int64]*domain.StreamConsumer[*PushMessage]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", productId)
+	}
+
+	if err := c.send("subscribe", productId); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, productId)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to product=%s: %w", productId, err)
+	}
+
+	return c.newSubscription(productId, consumerId, consumer), nil
+}
+
+// Resubscribe renews the subscription on the server side. The subscribers keep their channels.
+func (c *CoinbaseStreamClient) Resubscribe(productId string) error {
+	if err := c.send("unsubscribe", productId); err != nil {
+		return err
+	}
+
+	return c.send("subscribe", productId)
+}
+
+func (c *CoinbaseStreamClient) newSubscription(productId string, consumerId int64, consumer *domain.StreamConsumer[*PushMessage]) *domain.Subscription[*PushMessage] {
+	return &domain.Subscription[*PushMessage]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(productId, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from product=%s: %s", productId, err)
+			}
+		},
+		Topic: productId,
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *CoinbaseStreamClient) unsubscribe(productId string, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[productId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, productId)
+	c.mu.Unlock()
+
+	return c.send("unsubscribe", productId)
+}
+
+// send writes the request and waits for the subscriptions message. coinbase doesn't correlate the responses,
+// so the requests are serialized by the write mutex.
+func (c *CoinbaseStreamClient) send(msgType string, productIds ...string) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	req := WebSocketRequestModel{Type: msgType, ProductIds: productIds, Channels: c.channels}
+	sign(&req)
+
+	if err := conn.WriteJSON(req); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-c.acks:
+		if resp.Type == "error" {
+			return fmt.Errorf("coinbase error: %s, reason: %s", resp.Message, resp.Reason)
+		}
+		return nil
+	case <-time.After(coinbaseDefaultTimeout):
+		return fmt.Errorf("triggered wait response timeout in %v", coinbaseDefaultTimeout)
+	}
+}
+
+func (c *CoinbaseStreamClient) read(conn *websocket.Conn) {
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		resp := &WebSocketResponseModel{}
+		if err := json.Unmarshal(msg, resp); err != nil {
+			logger.Printf("failed to unmarshal message: %s, msg: %s", err, string(msg))
+			continue
+		}
+
+		switch resp.Type {
+		case "subscriptions", "error":
+			select {
+			case c.acks <- resp:
+			default:
+				logger.Printf("unexpected %s message: %s", resp.Type, string(msg))
+			}
+
+		default:
+			for _, consumer := range c.consumers(resp.ProductId) {
+				consumer.Send(&PushMessage{Type: resp.Type, Data: msg})
+			}
+		}
+	}
+}
+
+// consumers returns the consumers of the product. The messages are sent to them without holding the mutex.
+func (c *CoinbaseStreamClient) consumers(productId string) []*domain.StreamConsumer[*PushMessage] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[productId]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[*PushMessage], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect notifies the subscribers about the lost continuity and redials with the backoff.
+// The products are resubscribed on the new connection, so coinbase sends the new snapshots.
+func (c *CoinbaseStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	productIds := make([]string, 0, len(c.subscriptions))
+	for productId, entry := range c.subscriptions {
+		productIds = append(productIds, productId)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(productIds) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the coinbase websocket, %d products are subscribed", len(productIds))
+	}
+}
+
+func (c *CoinbaseStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *CoinbaseStreamClient) resubscribe(productIds []string) error {
+	if len(productIds) == 0 {
+		return nil
+	}
+	return c.send("subscribe", productIds...)
+}
+
+// sign authenticates the request if the api key is configured. The level2 channels are available only for the authenticated connections.
+func sign(req *WebSocketRequestModel) {
+	key := os.Getenv("COINBASE_API_KEY")
+	secret := os.Getenv("COINBASE_API_SECRET")
+	if key == "" || secret == "" {
+		return
+	}
+
+	decodedSecret, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		logger.Printf("failed to decode the api secret: %s", err)
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, decodedSecret)
+	mac.Write([]byte(timestamp + "GET" + "/users/self/verify"))
+
+	req.Key = key
+	req.Passphrase = os.Getenv("COINBASE_PASSPHRASE")
+	req.Timestamp = timestamp
+	req.Signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
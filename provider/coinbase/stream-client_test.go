@@ -0,0 +1,85 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeFeedServer acknowledges the subscriptions and sends the snapshot of each subscribed product.
+// The subscription is rejected while rejectSubscriptions is positive.
+func newFakeFeedServer(t *testing.T, rejectSubscriptions *atomic.Int32) *wstest.Server {
+	return wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := WebSocketRequestModel{}
+		json.Unmarshal(msg, &req)
+
+		if req.Type != "subscribe" {
+			return []interface{}{WebSocketResponseModel{Type: "subscriptions"}}, nil
+		}
+		if rejectSubscriptions.Load() > 0 {
+			rejectSubscriptions.Add(-1)
+			return []interface{}{WebSocketResponseModel{Type: "error", Message: "rejected"}}, nil
+		}
+
+		replies := []interface{}{WebSocketResponseModel{Type: "subscriptions"}}
+		for _, productId := range req.ProductIds {
+			replies = append(replies, WebSocketResponseModel{Type: "snapshot", ProductId: productId})
+		}
+		return replies, req.ProductIds
+	})
+}
+
+// receiveSnapshot skips the disconnect messages.
+func receiveSnapshot(t *testing.T, stream chan *PushMessage) *PushMessage {
+	for {
+		if msg := wstest.Receive(t, stream); msg.Type != DisconnectMessage {
+			return msg
+		}
+	}
+}
+
+func TestCoinbaseStreamClient_Reconnect(t *testing.T) {
+	var rejectSubscriptions atomic.Int32
+	server := newFakeFeedServer(t, &rejectSubscriptions)
+	defer server.Close()
+
+	client := NewCoinbaseStreamClient(server.Endpoint(), websocket.DefaultDialer, "level2")
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	first, err := client.Subscribe("BTC-USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "snapshot", wstest.Receive(t, first.Stream).Type)
+	second, err := client.Subscribe("BTC-USD")
+	assert.NoError(t, err)
+
+	// each consumer receives all the messages
+	server.Push("BTC-USD", WebSocketResponseModel{Type: "l2update", ProductId: "BTC-USD"})
+	assert.Equal(t, "l2update", wstest.Receive(t, first.Stream).Type)
+	assert.Equal(t, "l2update", wstest.Receive(t, second.Stream).Type)
+
+	// the first resubscription is rejected, the connection is closed and dialed again
+	rejectSubscriptions.Store(1)
+	server.DropConnections()
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, first.Stream).Type, "the consumer should be interrupted")
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, second.Stream).Type, "the consumer should be interrupted")
+
+	// the failed resubscription interrupts the consumers again
+	assert.Equal(t, "snapshot", receiveSnapshot(t, first.Stream).Type)
+	assert.Equal(t, "snapshot", receiveSnapshot(t, second.Stream).Type)
+	assert.Eventually(t, func() bool {
+		return server.Connections() == 1
+	}, 5*time.Second, 10*time.Millisecond, "the rejected connection should be closed")
+}
+
+func TestCoinbaseStreamClient_CloseTwice(t *testing.T) {
+	client := NewCoinbaseStreamClient("ws://127.0.0.1:0", websocket.DefaultDialer)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+}
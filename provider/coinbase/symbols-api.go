@@ -0,0 +1,58 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type Product struct {
+	Id              string `json:"id"`
+	BaseCurrency    string `json:"base_currency"`
+	QuoteCurrency   string `json:"quote_currency"`
+	Status          string `json:"status"`
+	TradingDisabled bool   `json:"trading_disabled"`
+}
+
+func (api *CoinbaseSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/products", api.baseURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cryptobridge")
+
+	resp, err := api.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coinbase error: status=%d, response: %s", resp.StatusCode, body)
+	}
+
+	data := []Product{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data))
+	for _, product := range data {
+		if product.Status != "online" || product.TradingDisabled {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(product.BaseCurrency, product.QuoteCurrency); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const coinbaseDefaultBaseURL = "https://api.exchange.coinbase.com"
+
+type CoinbaseSyncAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+// BookData is the aggregated level 2 book, the levels are in the form of [price, size, num-orders].
+type BookData struct {
+	Sequence int64           `json:"sequence"`
+	Bids     [][]interface{} `json:"bids"`
+	Asks     [][]interface{} `json:"asks"`
+	Message  string          `json:"message"`
+}
+
+func NewCoinbaseSyncAPI(baseURL string, httpClient *http.Client) *CoinbaseSyncAPI {
+	return &CoinbaseSyncAPI{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(),
+	}
+}
+
+// OrderBookSnapshot returns the top 50 levels of the book, the deepest aggregated book of the REST api.
+func (api *CoinbaseSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/products/%s/book?level=2", api.baseURL, api.symbols.Native(symbol)), nil)
+	if err != nil {
+		return nil, err
+	}
+	// coinbase rejects the requests without the user agent
+	req.Header.Set("User-Agent", "cryptobridge")
+
+	resp, err := api.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &BookData{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coinbase error: status=%d, msg=%s", resp.StatusCode, data.Message)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:       domain.OrderBookSource_Provider,
+		LastUpdateId: data.Sequence,
+		Bids:         restLevels(data.Bids, limit),
+		Asks:         restLevels(data.Asks, limit),
+	}, nil
+}
+
+func restLevels(levels [][]interface{}, limit int) [][]string {
+	if limit > 0 && len(levels) > limit {
+		levels = levels[:limit]
+	}
+
+	result := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		result = append(result, []string{fmt.Sprint(level[0]), fmt.Sprint(level[1])})
+	}
+
+	return result
+}
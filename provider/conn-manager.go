@@ -1,95 +1,157 @@
 package provider
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"sync"
 
+	"github.com/spooky-finn/cryptobridge/config"
 	"github.com/spooky-finn/cryptobridge/domain"
-	"github.com/spooky-finn/cryptobridge/provider/binance"
-	"github.com/spooky-finn/cryptobridge/provider/kucoin"
 )
 
 var logger = log.New(os.Stdout, "[api-resolver] ", log.LstdFlags)
 
 type ConnectionManager struct {
-	KucoinWS        *kucoin.KucoinStreamClient
-	KucoinSyncAPI   *kucoin.KucoinSyncAPI
-	KucoinStreamAPI *kucoin.KucoinStreamAPI
-
-	BinanceWC        *binance.BinanceStreamClient
-	BinanceSyncAPI   *binance.BinanceSyncAPI
-	BinanceStreamAPI *binance.BinanceStreamAPI
+	providers map[string]*domain.Provider
+	symbols   map[string]*domain.SymbolCache
+	// recorders are the recorders of the options, they are closed after the providers.
+	recorders map[string]io.Closer
 }
 
-func NewConnectionManager() *ConnectionManager {
-	binanceStreamClient := binance.NewBinanceStreamClient()
-	binanceSyncAPI := binance.NewBinanceAPI()
-
-	kucoinSyncAPI := kucoin.NewKucoinSyncAPI()
-	wsConnOpts, err := kucoinSyncAPI.WsConnOpts()
-	if err != nil {
-		panic("failed to get ws connection options: " + err.Error())
+// NewConnectionManager instantiates only the providers listed in names.
+// The providers missing in opts use the production endpoints and the default transports.
+func NewConnectionManager(names []string, opts map[string]domain.ProviderOptions) (*ConnectionManager, error) {
+	providers := make(map[string]*domain.Provider, len(names))
+	symbols := make(map[string]*domain.SymbolCache, len(names))
+	recorders := make(map[string]io.Closer)
+
+	for _, name := range names {
+		if _, ok := providers[name]; ok {
+			continue
+		}
+
+		p, err := newProvider(name, opts[name])
+		if err != nil {
+			return nil, err
+		}
+		providers[name] = p
+
+		if closer, ok := opts[name].Recorder.(io.Closer); ok {
+			recorders[name] = closer
+		}
+
+		if p.SymbolsAPI != nil {
+			symbols[name] = domain.NewSymbolCache(name, p.SymbolsAPI, config.SymbolsRefreshInterval)
+		}
 	}
 
-	kucoinStreamClient := kucoin.NewKucoinStreamClient(wsConnOpts)
-	KucoinStreamAPI := kucoin.NewKucoinStreamAPI(kucoinStreamClient, kucoinSyncAPI)
-
 	return &ConnectionManager{
-		KucoinWS:         kucoinStreamClient,
-		KucoinSyncAPI:    kucoinSyncAPI,
-		KucoinStreamAPI:  KucoinStreamAPI,
-		BinanceWC:        binanceStreamClient,
-		BinanceSyncAPI:   binanceSyncAPI,
-		BinanceStreamAPI: binance.NewBinanceStreamAPI(binanceStreamClient, binanceSyncAPI),
-	}
+		providers: providers,
+		symbols:   symbols,
+		recorders: recorders,
+	}, nil
 }
 
 func (cm *ConnectionManager) Init() {
 	wg := &sync.WaitGroup{}
-	wg.Add(2)
-	go cm.DialBinance(wg)
-	go cm.DialKucoin(wg)
+	wg.Add(len(cm.providers))
+	for _, p := range cm.providers {
+		go cm.dial(p, wg)
+	}
 	wg.Wait()
+
+	for _, cache := range cm.symbols {
+		go cache.Run()
+	}
 }
 
-func (cm *ConnectionManager) StreamAPI(provider string) domain.ProviderStreamAPI {
-	switch provider {
-	case "kucoin":
-		return cm.KucoinStreamAPI
-	case "binance":
-		return cm.BinanceStreamAPI
+func (cm *ConnectionManager) Provider(name string) (*domain.Provider, error) {
+	p, ok := cm.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownProvider, name)
 	}
 
-	panic("unknown provider: " + provider)
+	return p, nil
 }
 
-func (cm *ConnectionManager) SyncAPI(provider string) domain.ProviderSyncAPI {
-	switch provider {
-	case "kucoin":
-		return cm.KucoinSyncAPI
-	case "binance":
-		return cm.BinanceSyncAPI
+func (cm *ConnectionManager) StreamAPI(provider string) (domain.ProviderStreamAPI, error) {
+	p, err := cm.Provider(provider)
+	if err != nil {
+		return nil, err
 	}
 
-	panic("unknown provider: " + provider)
+	return p.StreamAPI, nil
 }
 
-func (cm *ConnectionManager) DialBinance(wg *sync.WaitGroup) {
-	if err := cm.BinanceWC.Connect(); err != nil {
-		logger.Printf("failed to connect to binance ws: " + err.Error())
+func (cm *ConnectionManager) SyncAPI(provider string) (domain.ProviderSyncAPI, error) {
+	p, err := cm.Provider(provider)
+	if err != nil {
+		return nil, err
 	}
-	wg.Done()
+
+	return p.SyncAPI, nil
 }
 
-func (cm *ConnectionManager) DialKucoin(wg *sync.WaitGroup) {
-	if err := cm.KucoinWS.Connect(); err != nil {
-		logger.Printf("failed to connect to kucoin ws: " + err.Error())
+func (cm *ConnectionManager) FuturesAPI(provider string) (domain.ProviderFuturesAPI, error) {
+	p, err := cm.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.FuturesAPI == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrFuturesNotSupported, provider)
+	}
+
+	return p.FuturesAPI, nil
+}
+
+func (cm *ConnectionManager) SymbolMapper(provider string) (*domain.SymbolMapper, error) {
+	p, err := cm.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.SymbolMapper, nil
+}
+
+func (cm *ConnectionManager) ValidateSymbol(provider string, symbol *domain.MarketSymbol) error {
+	if _, err := cm.Provider(provider); err != nil {
+		return err
+	}
+
+	cache, ok := cm.symbols[provider]
+	if !ok {
+		return nil
+	}
+
+	return cache.Validate(symbol)
+}
+
+func (cm *ConnectionManager) dial(p *domain.Provider, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if err := p.StreamClient.Connect(); err != nil {
+		logger.Printf("failed to connect to %s ws: %s", p.Name, err.Error())
 	}
-	wg.Done()
 }
 
 func (cm *ConnectionManager) Close() {
-	cm.KucoinWS.Close()
-	cm.BinanceWC.Close()
+	for _, cache := range cm.symbols {
+		cache.Stop()
+	}
+
+	for _, p := range cm.providers {
+		if err := p.StreamClient.Close(); err != nil {
+			logger.Printf("failed to close %s ws: %s", p.Name, err.Error())
+		}
+	}
+
+	// the captures are flushed once the connections are closed
+	for name, recorder := range cm.recorders {
+		if err := recorder.Close(); err != nil {
+			logger.Printf("failed to close the %s capture: %s", name, err.Error())
+		}
+	}
 }
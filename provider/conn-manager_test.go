@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/gateio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConnectionManager_UnknownProvider(t *testing.T) {
+	cm, err := NewConnectionManager([]string{"unknown"}, nil)
+
+	assert.Nil(t, cm, "Connection manager should be nil")
+	assert.True(t, errors.Is(err, domain.ErrUnknownProvider), "Error should be ErrUnknownProvider")
+}
+
+func TestConnectionManager_UnknownProvider(t *testing.T) {
+	cm, err := NewConnectionManager(nil, nil)
+	assert.NoError(t, err, "Unexpected error")
+
+	_, err = cm.StreamAPI("unknown")
+	assert.True(t, errors.Is(err, domain.ErrUnknownProvider), "Error should be ErrUnknownProvider")
+
+	_, err = cm.SyncAPI("unknown")
+	assert.True(t, errors.Is(err, domain.ErrUnknownProvider), "Error should be ErrUnknownProvider")
+}
+
+func TestRegistered(t *testing.T) {
+	assert.Contains(t, Registered(), "binance")
+	assert.Contains(t, Registered(), "kucoin")
+}
+
+func TestSupportsCapture(t *testing.T) {
+	assert.True(t, SupportsCapture("binance"))
+	assert.True(t, SupportsCapture("kucoin"))
+	assert.False(t, SupportsCapture("gateio"), "Provider that doesn't record should not get the capture")
+}
+
+type closingRecorder struct {
+	domain.NopRecorder
+	closed int
+}
+
+func (r *closingRecorder) Close() error {
+	r.closed++
+	return nil
+}
+
+func TestConnectionManager_CloseRecorders(t *testing.T) {
+	recorder := &closingRecorder{}
+	cm, err := NewConnectionManager([]string{gateio.ProviderName}, map[string]domain.ProviderOptions{
+		gateio.ProviderName: {Recorder: recorder},
+	})
+	assert.NoError(t, err, "Unexpected error")
+
+	cm.Close()
+	assert.Equal(t, 1, recorder.closed, "Recorder should be closed with the connection manager")
+}
+
+// newFakeGateio serves the gateio rest api and the websocket feed of btc_usdt.
+// The feed acks the requests and sends a depth update after the subscription.
+func newFakeGateio(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/spot/currency_pairs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"BTC_USDT","base":"BTC","quote":"USDT","trade_status":"tradable"}]`))
+	})
+	mux.HandleFunc("/spot/order_book", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "BTC_USDT", r.URL.Query().Get("currency_pair"))
+		w.Write([]byte(`{"id":100,"current":1,"update":1,"asks":[["101","1"]],"bids":[["99","1"]]}`))
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			req := &gateio.WebSocketRequestModel{}
+			if err := conn.ReadJSON(req); err != nil {
+				return
+			}
+
+			conn.WriteJSON(map[string]interface{}{"id": req.Id, "channel": req.Channel, "event": req.Event, "result": map[string]string{"status": "success"}})
+			if req.Event != "subscribe" {
+				continue
+			}
+
+			update, _ := json.Marshal(map[string]interface{}{"s": req.Payload[0], "U": 100, "u": 101, "b": [][]string{{"98", "2"}}, "a": [][]string{}})
+			conn.WriteJSON(map[string]interface{}{"channel": req.Channel, "event": "update", "result": json.RawMessage(update)})
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestConnectionManager_FakeProvider(t *testing.T) {
+	server := newFakeGateio(t)
+	defer server.Close()
+
+	cm, err := NewConnectionManager([]string{gateio.ProviderName}, map[string]domain.ProviderOptions{
+		gateio.ProviderName: {
+			RestURL:    server.URL,
+			StreamURLs: []string{"ws" + strings.TrimPrefix(server.URL, "http") + "/ws"},
+		},
+	})
+	assert.NoError(t, err)
+	cm.Init()
+	defer cm.Close()
+
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+	eth, _ := domain.NewMarketSymbol("eth", "usdt")
+	assert.NoError(t, cm.ValidateSymbol(gateio.ProviderName, btc))
+	assert.ErrorIs(t, cm.ValidateSymbol(gateio.ProviderName, eth), domain.ErrUnknownMarket)
+
+	streamAPI, err := cm.StreamAPI(gateio.ProviderName)
+	assert.NoError(t, err)
+
+	result := streamAPI.GetOrderBook(btc)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, int64(100), result.Snapshot.LastUpdateId)
+
+	assert.Eventually(t, func() bool {
+		snapshot := result.OrderBook.TakeSnapshot(10)
+		return snapshot.LastUpdateId == 101 && len(snapshot.Bids) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,27 @@
+package gateio
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+type GateioDepthUpdateValidator struct{}
+
+func (v *GateioDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	// Discard the update if u < baseId+1
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	// The update is applicable if U <= baseId+1 <= u. Afterwards, U of each update is equal to the previous u+1
+	if update.SequenceStart > orderBookLastUpdId+1 {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+func (v *GateioDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *GateioDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
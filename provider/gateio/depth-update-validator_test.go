@@ -0,0 +1,41 @@
+package gateio
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthUpdateValidator(t *testing.T) {
+	v := &GateioDepthUpdateValidator{}
+
+	upd := &domain.OrderBookUpdate{
+		SequenceStart: 123,
+		SequenceEnd:   130,
+	}
+
+	// u < baseId+1
+	err := v.IsValidUpd(upd, 130)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	// U <= baseId+1 <= u
+	err = v.IsValidUpd(upd, 125)
+	assert.Nil(t, err, "Error should be nil")
+
+	// U == previous u+1
+	err = v.IsValidUpd(upd, 122)
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestDepthUpdateValidator_OutOfSeq(t *testing.T) {
+	v := &GateioDepthUpdateValidator{}
+
+	upd := &domain.OrderBookUpdate{
+		SequenceStart: 125,
+		SequenceEnd:   136,
+	}
+
+	err := v.IsValidUpd(upd, 122)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
@@ -0,0 +1,26 @@
+package gateio
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const ProviderName = "gateio"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	streamClient := NewGateioStreamClient(opts.StreamURLOr(gateioDefaultWebsocketEndpoint), opts.NewDialer())
+	syncAPI := NewGateioSyncAPI(opts.RestURLOr(gateioDefaultBaseURL), opts.NewHTTPClient())
+	validator := &GateioDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewGateioStreamAPI(streamClient, syncAPI, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the currency pairs, e.g. BTC_USDT.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Separator: "_"})
+}
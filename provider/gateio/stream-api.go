@@ -0,0 +1,82 @@
+package gateio
+
+import (
+	"encoding/json"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	orderBookUpdateChannel  = "spot.order_book_update"
+	orderBookUpdateInterval = "100ms"
+)
+
+type GateioStreamAPI struct {
+	streamClient *GateioStreamClient
+	syncAPI      *GateioSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type DepthUpdateData struct {
+	Time          int64      `json:"t"`
+	Event         string     `json:"e"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateId int64      `json:"U"`
+	FinalUpdateId int64      `json:"u"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+func NewGateioStreamAPI(client *GateioStreamClient, syncAPI *GateioSyncAPI, validator domain.IDepthUpdateValidator) *GateioStreamAPI {
+	return &GateioStreamAPI{
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(),
+	}
+}
+
+func (s *GateioStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	subscribtion, err := s.streamClient.Subscribe(orderBookUpdateChannel, s.symbols.Native(symbol), orderBookUpdateInterval)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				// the connection was restored, the updates in between are lost
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			data := &DepthUpdateData{}
+			if err := json.Unmarshal(msg, data); err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			out <- domain.NewOrderBookUpdate(
+				data.Bids, data.Asks,
+				data.FirstUpdateId, data.FinalUpdateId,
+				symbol,
+			)
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       subscribtion.Topic,
+	}, nil
+}
+
+func (s *GateioStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.syncAPI, s.validator)
+	return maintainer.CreareOrderBook(ProviderName, symbol)
+}
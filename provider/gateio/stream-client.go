@@ -0,0 +1,371 @@
+package gateio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[gateio] ", log.LstdFlags)
+
+const (
+	gateioDefaultWebsocketEndpoint = "wss://api.gateio.ws/ws/v4/"
+	gateioDefaultTimeout           = 10 * time.Second
+	pingInterval                   = 10 * time.Second
+)
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+type WebSocketRequestModel struct {
+	Id      int64    `json:"id"`
+	Time    int64    `json:"time"`
+	Channel string   `json:"channel"`
+	Event   string   `json:"event,omitempty"`
+	Payload []string `json:"payload,omitempty"`
+}
+
+type WebSocketErrorModel struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type WebSocketResponseModel struct {
+	Id      int64                `json:"id"`
+	Time    int64                `json:"time"`
+	Channel string               `json:"channel"`
+	Event   string               `json:"event"`
+	Error   *WebSocketErrorModel `json:"error"`
+	Result  json.RawMessage      `json:"result"`
+}
+
+// A SubscribtionEntry is the upstream subscription to the channel of the currency pair shared by the consumers.
+// Each consumer has its own channel, an empty message is sent to it when the connection is restored.
+type SubscribtionEntry struct {
+	channel   string
+	payload   []string
+	consumers map[int64]*domain.StreamConsumer[[]byte]
+}
+
+// GateioStreamClient is the connection to the websocket. The lost connection is redialed with the backoff
+// and the channels are subscribed again.
+type GateioStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	conn     *websocket.Conn
+	reqId    int64
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	pending       map[int64]chan *WebSocketResponseModel
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewGateioStreamClient(endpoint string, dialer *websocket.Dialer) *GateioStreamClient {
+	return &GateioStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		subscriptions: make(map[string]*SubscribtionEntry),
+		pending:       make(map[int64]chan *WebSocketResponseModel),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *GateioStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the gateio websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	go c.keepHeartbeat(conn)
+	return nil
+}
+
+func (c *GateioStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes to the market channel of the currency pair. The upstream subscription is shared by the consumers
+// of the same channel and pair, it is made only for the first consumer and released when the last one unsubscribes.
+func (c *GateioStreamClient) Subscribe(channel string, payload ...string) (*domain.Subscription[[]byte], error) {
+	if len(payload) == 0 {
+		return nil, errors.New("payload must start with the currency pair")
+	}
+	topic := getTopic(channel, payload[0])
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer[[]byte](2048, nil)
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(topic, consumerId, consumer), nil
+	}
+
+	c.subscriptions[topic] = &SubscribtionEntry{
+		channel:   channel,
+		payload:   payload,
+		consumers: map[int64]*domain.StreamConsumer[[]byte]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", topic)
+	}
+
+	if _, err := c.send(channel, "subscribe", payload); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, topic)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to topic=%s: %w", topic, err)
+	}
+
+	return c.newSubscription(topic, consumerId, consumer), nil
+}
+
+func (c *GateioStreamClient) newSubscription(topic string, consumerId int64, consumer *domain.StreamConsumer[[]byte]) *domain.Subscription[[]byte] {
+	return &domain.Subscription[[]byte]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", topic, err)
+			}
+		},
+		Topic: topic,
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *GateioStreamClient) unsubscribe(topic string, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+
+	_, err := c.send(entry.channel, "unsubscribe", entry.payload)
+	return err
+}
+
+// send writes the request and waits for the response with the same id.
+func (c *GateioStreamClient) send(channel, event string, payload []string) (*WebSocketResponseModel, error) {
+	id := atomic.AddInt64(&c.reqId, 1)
+	respCh := make(chan *WebSocketResponseModel, 1)
+
+	c.mu.Lock()
+	conn := c.conn
+	c.pending[id] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+
+	err := c.write(conn, WebSocketRequestModel{
+		Id:      id,
+		Time:    time.Now().Unix(),
+		Channel: channel,
+		Event:   event,
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("gateio error: code=%d, msg=%s", resp.Error.Code, resp.Error.Message)
+		}
+		return resp, nil
+	case <-time.After(gateioDefaultTimeout):
+		return nil, fmt.Errorf("triggered wait response timeout in %v", gateioDefaultTimeout)
+	}
+}
+
+func (c *GateioStreamClient) write(conn *websocket.Conn, msg interface{}) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	return conn.WriteJSON(msg)
+}
+
+func (c *GateioStreamClient) read(conn *websocket.Conn) {
+	for {
+		msg := &WebSocketResponseModel{}
+		if err := conn.ReadJSON(msg); err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		if msg.Event == "update" || msg.Event == "all" {
+			c.dispatch(msg)
+			continue
+		}
+
+		c.mu.Lock()
+		respCh, ok := c.pending[msg.Id]
+		c.mu.Unlock()
+		if ok {
+			respCh <- msg
+		}
+	}
+}
+
+// dispatch routes the update to the subscribers. Market channels carry the currency pair in the result.s field.
+func (c *GateioStreamClient) dispatch(msg *WebSocketResponseModel) {
+	var result struct {
+		Symbol string `json:"s"`
+	}
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		logger.Printf("failed to unmarshal result of channel=%s: %s", msg.Channel, err)
+		return
+	}
+
+	for _, consumer := range c.consumers(getTopic(msg.Channel, result.Symbol)) {
+		consumer.Send(msg.Result)
+	}
+}
+
+// consumers returns the consumers of the topic. The messages are sent to them without holding the mutex.
+func (c *GateioStreamClient) consumers(topic string) []*domain.StreamConsumer[[]byte] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[[]byte], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect redials with the backoff unless the connection was closed by the client.
+// The consumers are interrupted before the channels are subscribed again, the updates in between are lost.
+func (c *GateioStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	entries := make([]*SubscribtionEntry, 0, len(c.subscriptions))
+	for _, entry := range c.subscriptions {
+		entries = append(entries, entry)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(entries) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the gateio websocket, %d channels are subscribed", len(entries))
+	}
+}
+
+func (c *GateioStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *GateioStreamClient) resubscribe(entries []*SubscribtionEntry) error {
+	for _, entry := range entries {
+		if _, err := c.send(entry.channel, "subscribe", entry.payload); err != nil {
+			return fmt.Errorf("topic=%s: %w", getTopic(entry.channel, entry.payload[0]), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *GateioStreamClient) keepHeartbeat(conn *websocket.Conn) {
+	pt := time.NewTicker(pingInterval)
+	defer pt.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-pt.C:
+			err := c.write(conn, WebSocketRequestModel{
+				Time:    time.Now().Unix(),
+				Channel: "spot.ping",
+			})
+			if err != nil {
+				logger.Printf("err while writing ping message to the conn: %s", err.Error())
+				return
+			}
+		}
+	}
+}
+
+func getTopic(channel, currencyPair string) string {
+	return fmt.Sprintf("%s:%s", channel, currencyPair)
+}
@@ -0,0 +1,67 @@
+package gateio
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeStreamServer acknowledges the requests, the subscriptions are kept by the currency pair.
+func newFakeStreamServer(t *testing.T) *wstest.Server {
+	return wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := WebSocketRequestModel{}
+		json.Unmarshal(msg, &req)
+
+		var subscribed []string
+		if req.Event == "subscribe" {
+			subscribed = append(subscribed, req.Payload[0])
+		}
+		return []interface{}{WebSocketResponseModel{Id: req.Id, Channel: req.Channel, Event: req.Event}}, subscribed
+	})
+}
+
+func update(result string) WebSocketResponseModel {
+	return WebSocketResponseModel{Channel: orderBookUpdateChannel, Event: "update", Result: json.RawMessage(result)}
+}
+
+func TestGateioStreamClient_Reconnect(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewGateioStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	first, err := client.Subscribe(orderBookUpdateChannel, "BTC_USDT", orderBookUpdateInterval)
+	assert.NoError(t, err)
+	second, err := client.Subscribe(orderBookUpdateChannel, "BTC_USDT", orderBookUpdateInterval)
+	assert.NoError(t, err)
+
+	// each consumer receives all the updates
+	server.Push("BTC_USDT", update(`{"s":"BTC_USDT","U":1,"u":1}`))
+	assert.Equal(t, `{"s":"BTC_USDT","U":1,"u":1}`, string(wstest.Receive(t, first.Stream)))
+	assert.Equal(t, `{"s":"BTC_USDT","U":1,"u":1}`, string(wstest.Receive(t, second.Stream)))
+
+	server.DropConnections()
+	assert.Empty(t, wstest.Receive(t, first.Stream), "the consumer should be interrupted")
+	assert.Empty(t, wstest.Receive(t, second.Stream), "the consumer should be interrupted")
+
+	assert.Eventually(t, func() bool {
+		return server.Subscribed("BTC_USDT")
+	}, 5*time.Second, 10*time.Millisecond, "the channel should be subscribed again")
+
+	server.Push("BTC_USDT", update(`{"s":"BTC_USDT","U":5,"u":5}`))
+	assert.Equal(t, `{"s":"BTC_USDT","U":5,"u":5}`, string(wstest.Receive(t, first.Stream)))
+	assert.Equal(t, `{"s":"BTC_USDT","U":5,"u":5}`, string(wstest.Receive(t, second.Stream)))
+}
+
+func TestGateioStreamClient_CloseTwice(t *testing.T) {
+	client := NewGateioStreamClient("ws://127.0.0.1:0", websocket.DefaultDialer)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+}
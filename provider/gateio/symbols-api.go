@@ -0,0 +1,53 @@
+package gateio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type CurrencyPair struct {
+	Id          string `json:"id"`
+	Base        string `json:"base"`
+	Quote       string `json:"quote"`
+	TradeStatus string `json:"trade_status"`
+}
+
+func (api *GateioSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/spot/currency_pairs", api.baseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency pairs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := &ErrorResponse{}
+		json.Unmarshal(body, errResp)
+		return nil, fmt.Errorf("failed to get currency pairs: status=%d, label=%s, message=%s", resp.StatusCode, errResp.Label, errResp.Message)
+	}
+
+	data := []CurrencyPair{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data))
+	for _, pair := range data {
+		if pair.TradeStatus != "tradable" {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(pair.Base, pair.Quote); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
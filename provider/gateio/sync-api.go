@@ -0,0 +1,86 @@
+package gateio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	gateioDefaultBaseURL = "https://api.gateio.ws/api/v4"
+	// Depth of the snapshot recommended by gateio to initialize the local order book.
+	gateioMaxSnapshotDepth = 100
+)
+
+type GateioSyncAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+type OrderBookSnapshot struct {
+	Id      int64      `json:"id"`
+	Current int64      `json:"current"`
+	Update  int64      `json:"update"`
+	Asks    [][]string `json:"asks"`
+	Bids    [][]string `json:"bids"`
+}
+
+type ErrorResponse struct {
+	Label   string `json:"label"`
+	Message string `json:"message"`
+}
+
+func NewGateioSyncAPI(baseURL string, httpClient *http.Client) *GateioSyncAPI {
+	return &GateioSyncAPI{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(),
+	}
+}
+
+func (api *GateioSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if limit > gateioMaxSnapshotDepth {
+		limit = gateioMaxSnapshotDepth
+	}
+
+	query := url.Values{}
+	query.Set("currency_pair", api.symbols.Native(symbol))
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("with_id", "true")
+
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/spot/order_book?%s", api.baseURL, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := &ErrorResponse{}
+		json.Unmarshal(body, errResp)
+		return nil, fmt.Errorf("failed to get order book snapshot: status=%d, label=%s, message=%s", resp.StatusCode, errResp.Label, errResp.Message)
+	}
+
+	data := &OrderBookSnapshot{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:         domain.OrderBookSource_Provider,
+		LastUpdateId:   data.Id,
+		LastUpdateTime: data.Update,
+		Bids:           data.Bids,
+		Asks:           data.Asks,
+	}, nil
+}
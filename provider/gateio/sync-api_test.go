@@ -0,0 +1,47 @@
+package gateio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGateioSyncAPI_OrderBookSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/spot/order_book", r.URL.Path)
+		assert.Equal(t, "BTC_USDT", r.URL.Query().Get("currency_pair"))
+		assert.Equal(t, "100", r.URL.Query().Get("limit"))
+		assert.Equal(t, "true", r.URL.Query().Get("with_id"))
+
+		w.Write([]byte(`{"id":123456,"current":1623898993123,"update":1623898993121,"asks":[["1.52","1.151"]],"bids":[["1.17","201.863"]]}`))
+	}))
+	defer server.Close()
+
+	api := NewGateioSyncAPI(server.URL, server.Client())
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	snapshot, err := api.OrderBookSnapshot(symbol, 1000)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(123456), snapshot.LastUpdateId, "LastUpdateId should match")
+	assert.Equal(t, [][]string{{"1.52", "1.151"}}, snapshot.Asks, "Asks should match")
+	assert.Equal(t, [][]string{{"1.17", "201.863"}}, snapshot.Bids, "Bids should match")
+}
+
+func TestGateioSyncAPI_OrderBookSnapshotError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"label":"INVALID_CURRENCY_PAIR","message":"Invalid currency pair FOO_BAR"}`))
+	}))
+	defer server.Close()
+
+	api := NewGateioSyncAPI(server.URL, server.Client())
+
+	symbol, _ := domain.NewMarketSymbol("foo", "bar")
+	_, err := api.OrderBookSnapshot(symbol, 10)
+
+	assert.ErrorContains(t, err, "INVALID_CURRENCY_PAIR")
+}
@@ -0,0 +1,27 @@
+package huobi
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+// HuobiDepthUpdateValidator checks that the prevSeqNum of the update is equal to the seqNum of the order book.
+// The seqNum of the mbp feed is not continuous, so the SequenceStart can't be used.
+type HuobiDepthUpdateValidator struct{}
+
+func (v *HuobiDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	if update.PrevSequenceEnd != orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+func (v *HuobiDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *HuobiDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
@@ -0,0 +1,45 @@
+package huobi
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthUpdateValidator(t *testing.T) {
+	v := &HuobiDepthUpdateValidator{}
+
+	upd := &domain.OrderBookUpdate{
+		SequenceStart:   101,
+		SequenceEnd:     110,
+		PrevSequenceEnd: 100,
+		Bids:            [][]string{{"10000", "1"}},
+		Asks:            [][]string{{"10100", "1.5"}},
+	}
+
+	// seqNum <= lastUpdateId means the update is already in the snapshot
+	err := v.IsValidUpd(upd, 110)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	// prevSeqNum == lastUpdateId
+	err = v.IsValidUpd(upd, 100)
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestDepthUpdateValidator_OutOfSeq(t *testing.T) {
+	v := &HuobiDepthUpdateValidator{}
+
+	upd := &domain.OrderBookUpdate{
+		SequenceStart:   106,
+		SequenceEnd:     110,
+		PrevSequenceEnd: 105,
+	}
+
+	// the seqNum is not continuous, so the update must follow exactly the lastUpdateId
+	err := v.IsValidUpd(upd, 100)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+
+	err = v.IsValidUpd(upd, 107)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
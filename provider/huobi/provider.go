@@ -0,0 +1,30 @@
+package huobi
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const ProviderName = "huobi"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	feedEndpoint := opts.StreamURLOr(huobiDefaultFeedEndpoint)
+	dialer := opts.NewDialer()
+
+	streamClient := NewHuobiStreamClient(feedEndpoint, dialer)
+	// the snapshots are requested over a dedicated connection to the feed
+	syncAPI := NewHuobiSyncAPI(NewHuobiStreamClient(feedEndpoint, dialer), opts.RestURLOr(huobiDefaultBaseURL), opts.NewHTTPClient())
+	validator := &HuobiDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewHuobiStreamAPI(streamClient, syncAPI, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the symbols, e.g. btcusdt.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Lowercase: true})
+}
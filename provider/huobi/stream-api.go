@@ -0,0 +1,107 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// Number of the levels of the incremental market by price feed.
+const mbpLevels = 150
+
+type HuobiStreamAPI struct {
+	streamClient *HuobiStreamClient
+	syncAPI      *HuobiSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+// DepthData is the tick of the mbp feed and the data of the mbp request. Prices and sizes are sent as numbers.
+type DepthData struct {
+	SeqNum     int64           `json:"seqNum"`
+	PrevSeqNum int64           `json:"prevSeqNum"`
+	Bids       [][]json.Number `json:"bids"`
+	Asks       [][]json.Number `json:"asks"`
+}
+
+func NewHuobiStreamAPI(client *HuobiStreamClient, syncAPI *HuobiSyncAPI, validator domain.IDepthUpdateValidator) *HuobiStreamAPI {
+	return &HuobiStreamAPI{
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(),
+	}
+}
+
+func (s *HuobiStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	topic := mbpTopic(s.symbols.Native(symbol))
+	subscribtion, err := s.streamClient.Subscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				// the connection was restored, the ticks in between are lost
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			update, err := parseDepthUpdate(msg, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			out <- update
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       topic,
+	}, nil
+}
+
+func (s *HuobiStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.syncAPI, s.validator)
+	return maintainer.CreareOrderBook(ProviderName, symbol)
+}
+
+func parseDepthUpdate(msg []byte, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	data := &DepthData{}
+	if err := json.Unmarshal(msg, data); err != nil {
+		return nil, err
+	}
+
+	update := domain.NewOrderBookUpdate(
+		toStringLevels(data.Bids), toStringLevels(data.Asks),
+		data.PrevSeqNum+1, data.SeqNum,
+		symbol,
+	)
+	update.PrevSequenceEnd = data.PrevSeqNum
+
+	return update, nil
+}
+
+func mbpTopic(native string) string {
+	return fmt.Sprintf("market.%s.mbp.%d", native, mbpLevels)
+}
+
+func toStringLevels(levels [][]json.Number) [][]string {
+	result := make([][]string, len(levels))
+	for i, level := range levels {
+		result[i] = make([]string, len(level))
+		for j, v := range level {
+			result[i][j] = v.String()
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,41 @@
+package huobi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDepthUpdate(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	msg := []byte(`{"seqNum":100020142010,"prevSeqNum":100020142009,"asks":[[645.140000000000000000,26.755973959140651643]],"bids":[[644.9,0]]}`)
+
+	update, err := parseDepthUpdate(msg, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(100020142010), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, int64(100020142009), update.PrevSequenceEnd, "PrevSequenceEnd should match")
+	assert.Equal(t, [][]string{{"645.140000000000000000", "26.755973959140651643"}}, update.Asks, "Asks should keep the original numbers")
+	assert.Equal(t, [][]string{{"644.9", "0"}}, update.Bids, "Bids should match")
+}
+
+func TestMbpTopic(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("BTC", "USDT")
+
+	assert.Equal(t, "market.btcusdt.mbp.150", mbpTopic(newSymbolMapper().Native(symbol)))
+}
+
+func TestDecompress(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(`{"ping":1492420473027}`))
+	w.Close()
+
+	msg, err := decompress(buf.Bytes())
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, `{"ping":1492420473027}`, string(msg))
+}
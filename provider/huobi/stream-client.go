@@ -0,0 +1,364 @@
+package huobi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/helpers"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[huobi] ", log.LstdFlags)
+
+const (
+	huobiDefaultFeedEndpoint = "wss://api.huobi.pro/feed"
+	huobiDefaultTimeout      = 10 * time.Second
+)
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+// A Frame is any message received from the huobi websocket after decompression.
+type Frame struct {
+	Ping     int64           `json:"ping"`
+	Id       string          `json:"id"`
+	Status   string          `json:"status"`
+	Subbed   string          `json:"subbed"`
+	Unsubbed string          `json:"unsubbed"`
+	Rep      string          `json:"rep"`
+	Ch       string          `json:"ch"`
+	Ts       int64           `json:"ts"`
+	ErrCode  string          `json:"err-code"`
+	ErrMsg   string          `json:"err-msg"`
+	Tick     json.RawMessage `json:"tick"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// A SubscribtionEntry is the upstream subscription to the topic shared by the consumers.
+// Each consumer has its own channel, an empty message is sent to it when the connection is restored.
+type SubscribtionEntry struct {
+	consumers map[int64]*domain.StreamConsumer[[]byte]
+}
+
+// HuobiStreamClient is the connection to the feed. The lost connection is redialed with the backoff
+// and the topics are subscribed again.
+type HuobiStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	conn     *websocket.Conn
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	pending       map[string]chan *Frame
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewHuobiStreamClient(endpoint string, dialer *websocket.Dialer) *HuobiStreamClient {
+	return &HuobiStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		subscriptions: make(map[string]*SubscribtionEntry),
+		pending:       make(map[string]chan *Frame),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *HuobiStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the huobi websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	return nil
+}
+
+func (c *HuobiStreamClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn != nil
+}
+
+func (c *HuobiStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes to the topic. The upstream subscription is shared by the consumers of the same topic,
+// it is made only for the first consumer and released when the last one unsubscribes.
+func (c *HuobiStreamClient) Subscribe(topic string) (*domain.Subscription[[]byte], error) {
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer[[]byte](2048, nil)
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(topic, consumerId, consumer), nil
+	}
+
+	c.subscriptions[topic] = &SubscribtionEntry{
+		consumers: map[int64]*domain.StreamConsumer[[]byte]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", topic)
+	}
+
+	id := getMsgId()
+	if _, err := c.send(map[string]string{"sub": topic, "id": id}, id); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, topic)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to topic=%s: %w", topic, err)
+	}
+
+	return c.newSubscription(topic, consumerId, consumer), nil
+}
+
+// Request sends a one-off request for the topic and returns the response.
+func (c *HuobiStreamClient) Request(topic string) (*Frame, error) {
+	id := getMsgId()
+	return c.send(map[string]string{"req": topic, "id": id}, id)
+}
+
+func (c *HuobiStreamClient) newSubscription(topic string, consumerId int64, consumer *domain.StreamConsumer[[]byte]) *domain.Subscription[[]byte] {
+	return &domain.Subscription[[]byte]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", topic, err)
+			}
+		},
+		Topic: topic,
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *HuobiStreamClient) unsubscribe(topic string, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+
+	id := getMsgId()
+	_, err := c.send(map[string]string{"unsub": topic, "id": id}, id)
+	return err
+}
+
+// send writes the message and waits for the response with the same id.
+func (c *HuobiStreamClient) send(msg interface{}, id string) (*Frame, error) {
+	respCh := make(chan *Frame, 1)
+
+	c.mu.Lock()
+	conn := c.conn
+	c.pending[id] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+
+	c.writeMutex.Lock()
+	err := conn.WriteJSON(msg)
+	c.writeMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case frame := <-respCh:
+		if frame.Status != "ok" {
+			return nil, fmt.Errorf("huobi error: code=%s, msg=%s", frame.ErrCode, frame.ErrMsg)
+		}
+		return frame, nil
+	case <-time.After(huobiDefaultTimeout):
+		return nil, fmt.Errorf("triggered wait response timeout in %v", huobiDefaultTimeout)
+	}
+}
+
+func (c *HuobiStreamClient) read(conn *websocket.Conn) {
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		msg, err = decompress(msg)
+		if err != nil {
+			logger.Printf("failed to decompress message: %s", err)
+			continue
+		}
+
+		frame := &Frame{}
+		if err := json.Unmarshal(msg, frame); err != nil {
+			logger.Printf("failed to unmarshal message: %s, msg: %s", err, string(msg))
+			continue
+		}
+
+		c.handle(conn, frame)
+	}
+}
+
+func (c *HuobiStreamClient) handle(conn *websocket.Conn, frame *Frame) {
+	switch {
+	case frame.Ping != 0:
+		c.writeMutex.Lock()
+		err := conn.WriteJSON(map[string]int64{"pong": frame.Ping})
+		c.writeMutex.Unlock()
+		if err != nil {
+			logger.Printf("failed to write pong message: %s", err)
+		}
+
+	case frame.Id != "":
+		c.mu.Lock()
+		respCh, ok := c.pending[frame.Id]
+		c.mu.Unlock()
+		if ok {
+			respCh <- frame
+		}
+
+	case frame.Ch != "":
+		for _, consumer := range c.consumers(frame.Ch) {
+			consumer.Send(frame.Tick)
+		}
+	}
+}
+
+// consumers returns the consumers of the topic. The messages are sent to them without holding the mutex.
+func (c *HuobiStreamClient) consumers(topic string) []*domain.StreamConsumer[[]byte] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[[]byte], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect redials with the backoff unless the connection was closed by the client.
+// The consumers are interrupted before the topics are subscribed again, the ticks in between are lost.
+func (c *HuobiStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic, entry := range c.subscriptions {
+		topics = append(topics, topic)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(topics) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the huobi websocket, %d topics are subscribed", len(topics))
+	}
+}
+
+func (c *HuobiStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *HuobiStreamClient) resubscribe(topics []string) error {
+	for _, topic := range topics {
+		id := getMsgId()
+		if _, err := c.send(map[string]string{"sub": topic, "id": id}, id); err != nil {
+			return fmt.Errorf("topic=%s: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+// decompress unpacks the gzip compressed frame. All market data frames of huobi are gzip compressed.
+func decompress(msg []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func getMsgId() string {
+	return helpers.IntToString(time.Now().UnixNano())
+}
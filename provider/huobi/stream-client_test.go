@@ -0,0 +1,77 @@
+package huobi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeFeedServer acknowledges the subscriptions, all the frames are gzip compressed as huobi does.
+func newFakeFeedServer(t *testing.T) *wstest.Server {
+	server := wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := map[string]string{}
+		json.Unmarshal(msg, &req)
+
+		var subscribed []string
+		if topic, ok := req["sub"]; ok {
+			subscribed = append(subscribed, topic)
+		}
+		return []interface{}{map[string]string{"id": req["id"], "status": "ok"}}, subscribed
+	})
+
+	server.Encode = func(v interface{}) (int, []byte) {
+		msg, _ := json.Marshal(v)
+		buf := &bytes.Buffer{}
+		w := gzip.NewWriter(buf)
+		w.Write(msg)
+		w.Close()
+		return websocket.BinaryMessage, buf.Bytes()
+	}
+	return server
+}
+
+func tick(topic, tick string) map[string]interface{} {
+	return map[string]interface{}{"ch": topic, "tick": json.RawMessage(tick)}
+}
+
+func TestHuobiStreamClient_Reconnect(t *testing.T) {
+	server := newFakeFeedServer(t)
+	defer server.Close()
+
+	client := NewHuobiStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	topic := "market.btcusdt.mbp.150"
+	first, err := client.Subscribe(topic)
+	assert.NoError(t, err)
+	second, err := client.Subscribe(topic)
+	assert.NoError(t, err)
+
+	// each consumer receives all the ticks
+	server.Push(topic, tick(topic, `{"seqNum":1}`))
+	assert.Equal(t, `{"seqNum":1}`, string(wstest.Receive(t, first.Stream)))
+	assert.Equal(t, `{"seqNum":1}`, string(wstest.Receive(t, second.Stream)))
+
+	server.DropConnections()
+	assert.Empty(t, wstest.Receive(t, first.Stream), "the consumer should be interrupted")
+	assert.Empty(t, wstest.Receive(t, second.Stream), "the consumer should be interrupted")
+
+	assert.Eventually(t, func() bool {
+		return server.Subscribed(topic)
+	}, 5*time.Second, 10*time.Millisecond, "the topic should be subscribed again")
+
+	server.Push(topic, tick(topic, `{"seqNum":5}`))
+	assert.Equal(t, `{"seqNum":5}`, string(wstest.Receive(t, first.Stream)))
+	assert.Equal(t, `{"seqNum":5}`, string(wstest.Receive(t, second.Stream)))
+
+	second.Unsubscribe()
+	_, ok := <-second.Stream
+	assert.False(t, ok, "the stream should be closed on unsubscribe")
+}
@@ -0,0 +1,58 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// The REST api serves only the list of the markets, the snapshots are requested over the websocket.
+const huobiDefaultBaseURL = "https://api.huobi.pro"
+
+type SymbolsResponse struct {
+	Status  string `json:"status"`
+	ErrCode string `json:"err-code"`
+	ErrMsg  string `json:"err-msg"`
+	Data    []struct {
+		Symbol        string `json:"symbol"`
+		BaseCurrency  string `json:"base-currency"`
+		QuoteCurrency string `json:"quote-currency"`
+		State         string `json:"state"`
+	} `json:"data"`
+}
+
+func (api *HuobiSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/v1/common/symbols", api.baseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbols: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &SymbolsResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if data.Status != "ok" {
+		return nil, fmt.Errorf("failed to get symbols: code=%s, msg=%s", data.ErrCode, data.ErrMsg)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data.Data))
+	for _, s := range data.Data {
+		if s.State != "online" {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(s.BaseCurrency, s.QuoteCurrency); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
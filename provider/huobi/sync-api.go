@@ -0,0 +1,66 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// HuobiSyncAPI requests the mbp snapshots over the dedicated websocket connection.
+// The REST depth endpoint is not used because its version is not comparable with the seqNum of the feed.
+type HuobiSyncAPI struct {
+	client *HuobiStreamClient
+
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+func NewHuobiSyncAPI(client *HuobiStreamClient, baseURL string, httpClient *http.Client) *HuobiSyncAPI {
+	logger.Println("instantiating huobi websocket api")
+	if err := client.Connect(); err != nil {
+		logger.Printf("error dialing huobi sync ws api: %s", err.Error())
+	}
+
+	return &HuobiSyncAPI{
+		client:     client,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(),
+	}
+}
+
+func (api *HuobiSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if !api.client.IsConnected() {
+		if err := api.client.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	frame, err := api.client.Request(mbpTopic(api.symbols.Native(symbol)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+
+	data := &DepthData{}
+	if err := json.Unmarshal(frame.Data, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, frame.Data)
+	}
+
+	if len(data.Asks) > limit {
+		data.Asks = data.Asks[:limit]
+	}
+
+	if len(data.Bids) > limit {
+		data.Bids = data.Bids[:limit]
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:       domain.OrderBookSource_Provider,
+		LastUpdateId: data.SeqNum,
+		Bids:         toStringLevels(data.Bids),
+		Asks:         toStringLevels(data.Asks),
+	}, nil
+}
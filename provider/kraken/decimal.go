@@ -0,0 +1,33 @@
+package kraken
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// formatDecimal formats the number with the fixed number of decimals without the float conversion.
+// The v2 websocket drops the trailing zeros, but the checksum is calculated from the values with the precision of the pair.
+func formatDecimal(n json.Number, decimals int) string {
+	s := n.String()
+
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return s
+		}
+		return strconv.FormatFloat(f, 'f', decimals, 64)
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if decimals == 0 || len(fracPart) > decimals {
+		return s
+	}
+
+	return intPart + "." + fracPart + strings.Repeat("0", decimals-len(fracPart))
+}
+
+// checksumValue removes the decimal point and the leading zeros of the value.
+func checksumValue(s string) string {
+	return strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+}
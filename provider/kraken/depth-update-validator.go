@@ -0,0 +1,56 @@
+package kraken
+
+import (
+	"hash/crc32"
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// Number of the levels of each side included into the checksum.
+const checksumDepth = 10
+
+// KrakenDepthUpdateValidator relies on the checksum, the updates of kraken have no sequence.
+type KrakenDepthUpdateValidator struct{}
+
+func (v *KrakenDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	return nil
+}
+
+// IsValidState compares the checksum of the update with the checksum of the top 10 levels of the local order book.
+func (v *KrakenDepthUpdateValidator) IsValidState(update *domain.OrderBookUpdate, orderBook *domain.OrderBook) error {
+	bids, asks := orderBook.RawDepth(checksumDepth)
+	if checksum(bids, asks) != update.Checksum {
+		return domain.ErrOrderBookChecksumMismatch
+	}
+
+	return nil
+}
+
+func (v *KrakenDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *KrakenDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
+
+// checksum is crc32 of the concatenated price and qty of the asks and then the bids,
+// each without the decimal point and the leading zeros.
+func checksum(bids, asks [][]string) uint32 {
+	b := strings.Builder{}
+	for _, level := range asks {
+		b.WriteString(checksumValue(level[0]))
+		b.WriteString(checksumValue(level[1]))
+	}
+	for _, level := range bids {
+		b.WriteString(checksumValue(level[0]))
+		b.WriteString(checksumValue(level[1]))
+	}
+
+	return crc32.ChecksumIEEE([]byte(b.String()))
+}
@@ -0,0 +1,67 @@
+package kraken
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	assert.Equal(t, "0.10000000", formatDecimal(json.Number("0.1"), 8))
+	assert.Equal(t, "45283.5", formatDecimal(json.Number("45283.5"), 1))
+	assert.Equal(t, "4.00000000", formatDecimal(json.Number("4"), 8))
+	assert.Equal(t, "0.00000500", formatDecimal(json.Number("5e-06"), 8))
+	assert.Equal(t, "12", formatDecimal(json.Number("12"), 0))
+}
+
+func TestChecksumValue(t *testing.T) {
+	assert.Equal(t, "452835", checksumValue("45283.5"))
+	assert.Equal(t, "500", checksumValue("0.00000500"))
+	assert.Equal(t, "10000000", checksumValue("0.10000000"))
+}
+
+func TestDepthUpdateValidator_IsValidState(t *testing.T) {
+	v := &KrakenDepthUpdateValidator{}
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+
+	ob := domain.NewOrderBook(ProviderName, symbol, &domain.OrderBookSnapshot{
+		LastUpdateId: 1,
+		Bids:         [][]string{{"45283.5", "0.10000000"}, {"45283.4", "1.54582015"}},
+		Asks:         [][]string{{"45285.2", "0.00100000"}, {"45286.4", "1.54571953"}},
+	})
+
+	expected := crc32.ChecksumIEEE([]byte("452852100000" + "452864154571953" + "45283510000000" + "452834154582015"))
+
+	err := v.IsValidState(&domain.OrderBookUpdate{Checksum: expected}, ob)
+	assert.Nil(t, err, "Error should be nil")
+
+	err = v.IsValidState(&domain.OrderBookUpdate{Checksum: expected + 1}, ob)
+	assert.Equal(t, domain.ErrOrderBookChecksumMismatch, err, "Error should match")
+}
+
+func TestParseBookUpdate(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+	precision := &Precision{PairDecimals: 1, LotDecimals: 8}
+	msg := &PushMessage{
+		Type: "update",
+		Data: []byte(`{"symbol":"BTC/USD","bids":[{"price":45283.5,"qty":0.1}],"asks":[{"price":45285.2,"qty":0}],"checksum":2114181697}`),
+	}
+
+	update, err := parseBookUpdate(msg, precision, 7, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.False(t, update.IsSnapshot, "Update should not be a snapshot")
+	assert.Equal(t, int64(7), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, uint32(2114181697), update.Checksum, "Checksum should match")
+	assert.Equal(t, [][]string{{"45283.5", "0.10000000"}}, update.Bids, "Bids should be formatted with the pair precision")
+	assert.Equal(t, [][]string{{"45285.2", "0.00000000"}}, update.Asks, "Asks should be formatted with the pair precision")
+}
+
+func TestRestPair(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usd")
+	assert.Equal(t, "XBTUSD", NewKrakenSyncAPI(krakenDefaultBaseURL, nil).restSymbols.Native(symbol))
+	assert.Equal(t, "BTC/USD", newSymbolMapper().Native(symbol))
+}
@@ -0,0 +1,26 @@
+package kraken
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const ProviderName = "kraken"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	streamClient := NewKrakenStreamClient(opts.StreamURLOr(krakenDefaultWebsocketEndpoint), opts.NewDialer())
+	syncAPI := NewKrakenSyncAPI(opts.RestURLOr(krakenDefaultBaseURL), opts.NewHTTPClient())
+	validator := &KrakenDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewKrakenStreamAPI(streamClient, syncAPI, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the websocket api, e.g. BTC/USD.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Separator: "/"})
+}
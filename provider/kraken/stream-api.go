@@ -0,0 +1,128 @@
+package kraken
+
+import (
+	"encoding/json"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	bookChannel = "book"
+	// The deepest book available on the v2 websocket.
+	bookDepth = 1000
+)
+
+type KrakenStreamAPI struct {
+	streamClient *KrakenStreamClient
+	syncAPI      *KrakenSyncAPI
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type BookLevel struct {
+	Price json.Number `json:"price"`
+	Qty   json.Number `json:"qty"`
+}
+
+type BookData struct {
+	Symbol   string      `json:"symbol"`
+	Bids     []BookLevel `json:"bids"`
+	Asks     []BookLevel `json:"asks"`
+	Checksum uint32      `json:"checksum"`
+}
+
+func NewKrakenStreamAPI(client *KrakenStreamClient, syncAPI *KrakenSyncAPI, validator domain.IDepthUpdateValidator) *KrakenStreamAPI {
+	return &KrakenStreamAPI{
+		streamClient: client,
+		syncAPI:      syncAPI,
+		validator:    validator,
+		symbols:      newSymbolMapper(),
+	}
+}
+
+// DepthDiffStream emits the snapshots and the updates of the book channel.
+// kraken doesn't number the updates, so the sequence is assigned locally in the order of the receiving.
+func (s *KrakenStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	precision, err := s.syncAPI.Precision(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribtion, err := s.streamClient.Subscribe(bookParams(s.symbols.Native(symbol)))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		var sequence int64
+		for msg := range subscribtion.Stream {
+			if msg.Type == DisconnectMessage {
+				// the updates are lost until the channel is subscribed again
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			sequence++
+			update, err := parseBookUpdate(msg, precision, sequence, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			out <- update
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       subscribtion.Topic,
+	}, nil
+}
+
+// RequestSnapshot resubscribes to the book channel, kraken sends the snapshot on every subscription.
+func (s *KrakenStreamAPI) RequestSnapshot(symbol *domain.MarketSymbol) error {
+	return s.streamClient.Resubscribe(bookParams(s.symbols.Native(symbol)))
+}
+
+func (s *KrakenStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.syncAPI, s.validator)
+
+	result := maintainer.CreateOrderBookFromStream(ProviderName, symbol)
+	if result.Err != nil {
+		return result
+	}
+
+	// kraken doesn't send the removal of the levels that fall out of the subscribed depth
+	result.OrderBook.SetMaxDepth(bookDepth)
+	return result
+}
+
+func parseBookUpdate(msg *PushMessage, precision *Precision, sequence int64, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	data := &BookData{}
+	if err := json.Unmarshal(msg.Data, data); err != nil {
+		return nil, err
+	}
+
+	update := domain.NewOrderBookUpdate(
+		precision.formatLevels(data.Bids), precision.formatLevels(data.Asks),
+		sequence, sequence,
+		symbol,
+	)
+	update.IsSnapshot = msg.Type == "snapshot"
+	update.Checksum = data.Checksum
+
+	return update, nil
+}
+
+func bookParams(native string) SubscribeParams {
+	return SubscribeParams{
+		Channel:  bookChannel,
+		Symbol:   []string{native},
+		Depth:    bookDepth,
+		Snapshot: true,
+	}
+}
@@ -0,0 +1,357 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[kraken] ", log.LstdFlags)
+
+const (
+	krakenDefaultWebsocketEndpoint = "wss://ws.kraken.com/v2"
+	krakenDefaultTimeout           = 10 * time.Second
+)
+
+// Type of the message emitted to the subscribers when the connection is lost.
+const DisconnectMessage = "disconnect"
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+type SubscribeParams struct {
+	Channel  string   `json:"channel"`
+	Symbol   []string `json:"symbol"`
+	Depth    int      `json:"depth,omitempty"`
+	Snapshot bool     `json:"snapshot,omitempty"`
+}
+
+type WebSocketRequestModel struct {
+	Method string           `json:"method"`
+	Params *SubscribeParams `json:"params,omitempty"`
+	ReqId  int64            `json:"req_id"`
+}
+
+type WebSocketResponseModel struct {
+	Method  string            `json:"method"`
+	Success bool              `json:"success"`
+	Error   string            `json:"error"`
+	ReqId   int64             `json:"req_id"`
+	Channel string            `json:"channel"`
+	Type    string            `json:"type"`
+	Data    []json.RawMessage `json:"data"`
+}
+
+// A PushMessage is a single entry of the channel data with the type of the message: snapshot or update.
+type PushMessage struct {
+	Type string
+	Data json.RawMessage
+}
+
+// A SubscribtionEntry is the upstream subscription to the channel of the symbol shared by the consumers.
+// Each consumer has its own channel, the disconnect message is sent to it when the connection is lost.
+type SubscribtionEntry struct {
+	params    SubscribeParams
+	consumers map[int64]*domain.StreamConsumer[*PushMessage]
+}
+
+// KrakenStreamClient is the connection to the v2 websocket. The lost connection is redialed with the backoff
+// and the channels are subscribed again, so kraken sends the new snapshots.
+type KrakenStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	conn     *websocket.Conn
+	reqId    int64
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	pending       map[int64]chan *WebSocketResponseModel
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewKrakenStreamClient(endpoint string, dialer *websocket.Dialer) *KrakenStreamClient {
+	return &KrakenStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		subscriptions: make(map[string]*SubscribtionEntry),
+		pending:       make(map[int64]chan *WebSocketResponseModel),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *KrakenStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the kraken websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	return nil
+}
+
+func (c *KrakenStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes to the channel of the symbol. The upstream subscription is shared by the consumers of the same topic,
+// it is made only for the first consumer and released when the last one unsubscribes.
+func (c *KrakenStreamClient) Subscribe(params SubscribeParams) (*domain.Subscription[*PushMessage], error) {
+	if len(params.Symbol) != 1 {
+		return nil, errors.New("exactly one symbol must be subscribed")
+	}
+	topic := getTopic(params.Channel, params.Symbol[0])
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer(2048, &PushMessage{Type: DisconnectMessage})
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(topic, consumerId, consumer), nil
+	}
+
+	c.subscriptions[topic] = &SubscribtionEntry{
+		params:    params,
+		consumers: map[int64]*domain.StreamConsumer[*PushMessage]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", topic)
+	}
+
+	if err := c.send("subscribe", params); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, topic)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to topic=%s: %w", topic, err)
+	}
+
+	return c.newSubscription(topic, consumerId, consumer), nil
+}
+
+// Resubscribe renews the subscription on the server side. The subscribers keep their channels.
+func (c *KrakenStreamClient) Resubscribe(params SubscribeParams) error {
+	if err := c.send("unsubscribe", params); err != nil {
+		return err
+	}
+
+	return c.send("subscribe", params)
+}
+
+func (c *KrakenStreamClient) newSubscription(topic string, consumerId int64, consumer *domain.StreamConsumer[*PushMessage]) *domain.Subscription[*PushMessage] {
+	return &domain.Subscription[*PushMessage]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", topic, err)
+			}
+		},
+		Topic: topic,
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *KrakenStreamClient) unsubscribe(topic string, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+
+	return c.send("unsubscribe", entry.params)
+}
+
+// send writes the method and waits for the response with the same req_id.
+func (c *KrakenStreamClient) send(method string, params SubscribeParams) error {
+	id := atomic.AddInt64(&c.reqId, 1)
+	respCh := make(chan *WebSocketResponseModel, 1)
+
+	c.mu.Lock()
+	conn := c.conn
+	c.pending[id] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	c.writeMutex.Lock()
+	err := conn.WriteJSON(WebSocketRequestModel{Method: method, Params: &params, ReqId: id})
+	c.writeMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-respCh:
+		if !resp.Success {
+			return fmt.Errorf("kraken error: %s", resp.Error)
+		}
+		return nil
+	case <-time.After(krakenDefaultTimeout):
+		return fmt.Errorf("triggered wait response timeout in %v", krakenDefaultTimeout)
+	}
+}
+
+func (c *KrakenStreamClient) read(conn *websocket.Conn) {
+	for {
+		resp := &WebSocketResponseModel{}
+		if err := conn.ReadJSON(resp); err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		if resp.Method != "" {
+			c.mu.Lock()
+			respCh, ok := c.pending[resp.ReqId]
+			c.mu.Unlock()
+			if ok {
+				select {
+				case respCh <- resp:
+				default:
+				}
+			}
+			continue
+		}
+
+		c.dispatch(resp)
+	}
+}
+
+// dispatch routes each entry of the data to the consumers of its symbol.
+func (c *KrakenStreamClient) dispatch(resp *WebSocketResponseModel) {
+	for _, data := range resp.Data {
+		var entry struct {
+			Symbol string `json:"symbol"`
+		}
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Symbol == "" {
+			continue
+		}
+
+		for _, consumer := range c.consumers(getTopic(resp.Channel, entry.Symbol)) {
+			consumer.Send(&PushMessage{Type: resp.Type, Data: data})
+		}
+	}
+}
+
+// consumers returns the consumers of the topic. The messages are sent to them without holding the mutex.
+func (c *KrakenStreamClient) consumers(topic string) []*domain.StreamConsumer[*PushMessage] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[*PushMessage], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect redials with the backoff unless the connection was closed by the client.
+// The consumers are interrupted before the channels are subscribed again, kraken sends the snapshots on the subscription.
+func (c *KrakenStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	params := make([]SubscribeParams, 0, len(c.subscriptions))
+	for _, entry := range c.subscriptions {
+		params = append(params, entry.params)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(params) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the kraken websocket, %d channels are subscribed", len(params))
+	}
+}
+
+func (c *KrakenStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *KrakenStreamClient) resubscribe(params []SubscribeParams) error {
+	for _, p := range params {
+		if err := c.send("subscribe", p); err != nil {
+			return fmt.Errorf("topic=%s: %w", getTopic(p.Channel, p.Symbol[0]), err)
+		}
+	}
+
+	return nil
+}
+
+func getTopic(channel, symbol string) string {
+	return fmt.Sprintf("%s:%s", channel, symbol)
+}
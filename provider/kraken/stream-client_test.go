@@ -0,0 +1,93 @@
+package kraken
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeStreamServer answers the methods and pushes the snapshot to the connection on every subscription.
+func newFakeStreamServer(t *testing.T) *wstest.Server {
+	return wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := WebSocketRequestModel{}
+		json.Unmarshal(msg, &req)
+
+		replies := []interface{}{WebSocketResponseModel{Method: req.Method, Success: true, ReqId: req.ReqId}}
+		if req.Method != "subscribe" {
+			return replies, nil
+		}
+		replies = append(replies, book(req.Params.Symbol[0], "snapshot"))
+		return replies, req.Params.Symbol
+	})
+}
+
+func book(symbol, typ string) WebSocketResponseModel {
+	data, _ := json.Marshal(BookData{Symbol: symbol})
+	return WebSocketResponseModel{Channel: bookChannel, Type: typ, Data: []json.RawMessage{data}}
+}
+
+func TestKrakenStreamClient_Reconnect(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewKrakenStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	first, err := client.Subscribe(bookParams("BTC/USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, "snapshot", wstest.Receive(t, first.Stream).Type)
+	second, err := client.Subscribe(bookParams("BTC/USD"))
+	assert.NoError(t, err)
+
+	// each consumer receives all the messages
+	server.Push("BTC/USD", book("BTC/USD", "update"))
+	assert.Equal(t, "update", wstest.Receive(t, first.Stream).Type)
+	assert.Equal(t, "update", wstest.Receive(t, second.Stream).Type)
+
+	server.DropConnections()
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, first.Stream).Type, "the consumer should be interrupted")
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, second.Stream).Type, "the consumer should be interrupted")
+
+	// the channel is subscribed again on the new connection, kraken sends the snapshot
+	assert.Equal(t, "snapshot", wstest.Receive(t, first.Stream).Type)
+	assert.Equal(t, "snapshot", wstest.Receive(t, second.Stream).Type)
+
+	second.Unsubscribe()
+	_, ok := <-second.Stream
+	assert.False(t, ok, "the stream should be closed on unsubscribe")
+}
+
+func TestKrakenStreamClient_SlowConsumer(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := NewKrakenStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	slow, err := client.Subscribe(bookParams("ETH/USD"))
+	assert.NoError(t, err)
+	other, err := client.Subscribe(bookParams("BTC/USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, "snapshot", wstest.Receive(t, other.Stream).Type)
+
+	// the overflow of the consumer that doesn't read doesn't block the others
+	for i := 0; i < 2100; i++ {
+		server.Push("ETH/USD", book("ETH/USD", "update"))
+	}
+	server.Push("BTC/USD", book("BTC/USD", "update"))
+	assert.Equal(t, "update", wstest.Receive(t, other.Stream).Type)
+
+	assert.Equal(t, "snapshot", wstest.Receive(t, slow.Stream).Type)
+}
+
+func TestKrakenStreamClient_CloseTwice(t *testing.T) {
+	client := NewKrakenStreamClient("ws://127.0.0.1:0", websocket.DefaultDialer)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+}
@@ -0,0 +1,39 @@
+package kraken
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// AssetPair is the pair of the AssetPairs endpoint, wsname is the pair with the legacy asset codes, e.g. XBT/USD.
+type AssetPair struct {
+	Wsname string `json:"wsname"`
+	Status string `json:"status"`
+}
+
+func (api *KrakenSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	resp := &Response[AssetPair]{}
+	if err := api.get("AssetPairs", url.Values{}, resp); err != nil {
+		return nil, fmt.Errorf("failed to get asset pairs: %w", err)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(resp.Result))
+	for _, pair := range resp.Result {
+		if pair.Status != "online" {
+			continue
+		}
+
+		assets := strings.Split(pair.Wsname, "/")
+		if len(assets) != 2 {
+			continue
+		}
+		if symbol, err := api.restSymbols.Canonical(assets[0], assets[1]); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
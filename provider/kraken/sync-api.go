@@ -0,0 +1,167 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	krakenDefaultBaseURL   = "https://api.kraken.com/0/public"
+	krakenMaxSnapshotDepth = 500
+)
+
+// The REST api still uses the legacy asset codes, the websocket api uses the canonical ones.
+var restAssetAliases = map[string]string{
+	"btc":  "xbt",
+	"doge": "xdg",
+}
+
+// Precision is the number of decimals of the price and the volume of the pair.
+type Precision struct {
+	PairDecimals int `json:"pair_decimals"`
+	LotDecimals  int `json:"lot_decimals"`
+}
+
+type KrakenSyncAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	// The pairs of the REST api, e.g. XBTUSD for btc_usd.
+	restSymbols *domain.SymbolMapper
+
+	mu         sync.Mutex
+	precisions map[string]*Precision
+}
+
+type Response[T any] struct {
+	Error  []string     `json:"error"`
+	Result map[string]T `json:"result"`
+}
+
+type DepthData struct {
+	Asks [][]interface{} `json:"asks"`
+	Bids [][]interface{} `json:"bids"`
+}
+
+func NewKrakenSyncAPI(baseURL string, httpClient *http.Client) *KrakenSyncAPI {
+	return &KrakenSyncAPI{
+		baseURL:     baseURL,
+		httpClient:  httpClient,
+		restSymbols: domain.NewSymbolMapper(domain.SymbolFormat{}, restAssetAliases),
+		precisions:  make(map[string]*Precision),
+	}
+}
+
+func (api *KrakenSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if limit > krakenMaxSnapshotDepth {
+		limit = krakenMaxSnapshotDepth
+	}
+
+	query := url.Values{}
+	query.Set("pair", api.restSymbols.Native(symbol))
+	query.Set("count", strconv.Itoa(limit))
+
+	resp := &Response[DepthData]{}
+	if err := api.get("Depth", query, resp); err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+
+	for _, data := range resp.Result {
+		return &domain.OrderBookSnapshot{
+			Source: domain.OrderBookSource_Provider,
+			Bids:   restLevels(data.Bids),
+			Asks:   restLevels(data.Asks),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("failed to get order book snapshot: empty result for %s", symbol)
+}
+
+// Precision returns the decimals of the pair. The precisions are cached, they are changed very rarely.
+func (api *KrakenSyncAPI) Precision(symbol *domain.MarketSymbol) (*Precision, error) {
+	pair := api.restSymbols.Native(symbol)
+
+	api.mu.Lock()
+	p, ok := api.precisions[pair]
+	api.mu.Unlock()
+	if ok {
+		return p, nil
+	}
+
+	query := url.Values{}
+	query.Set("pair", pair)
+
+	resp := &Response[Precision]{}
+	if err := api.get("AssetPairs", query, resp); err != nil {
+		return nil, fmt.Errorf("failed to get precision of the pair: %w", err)
+	}
+
+	for _, p := range resp.Result {
+		p := p
+		api.mu.Lock()
+		api.precisions[pair] = &p
+		api.mu.Unlock()
+		return &p, nil
+	}
+
+	return nil, fmt.Errorf("failed to get precision of the pair: empty result for %s", symbol)
+}
+
+func (api *KrakenSyncAPI) get(method string, query url.Values, v interface{ errors() []string }) error {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/%s?%s", api.baseURL, method, query.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if errs := v.errors(); len(errs) > 0 {
+		return fmt.Errorf("kraken error: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (r *Response[T]) errors() []string {
+	return r.Error
+}
+
+func (p *Precision) formatLevels(levels []BookLevel) [][]string {
+	result := make([][]string, len(levels))
+	for i, level := range levels {
+		result[i] = []string{
+			formatDecimal(level.Price, p.PairDecimals),
+			formatDecimal(level.Qty, p.LotDecimals),
+		}
+	}
+
+	return result
+}
+
+// restLevels converts the [price, volume, timestamp] levels of the REST api.
+func restLevels(levels [][]interface{}) [][]string {
+	result := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		result = append(result, []string{fmt.Sprint(level[0]), fmt.Sprint(level[1])})
+	}
+
+	return result
+}
@@ -0,0 +1,98 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type KucoinFuturesStreamAPI struct {
+	WebSocket TopicSubscriber
+	SyncAPI   *KucoinFuturesSyncAPI
+
+	validator domain.IDepthUpdateValidator
+	// snapshots serves the snapshots the order books are built of.
+	snapshots domain.ProviderSyncAPI
+	symbols   *domain.SymbolMapper
+}
+
+// FuturesDepthUpdateModel is a single change of the contract book.
+// The change is in the form of "price,side,size", each change has its own sequence.
+type FuturesDepthUpdateModel struct {
+	Sequence  int64  `json:"sequence"`
+	Change    string `json:"change"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func NewKucoinFuturesStreamAPI(wc TopicSubscriber, syncAPI *KucoinFuturesSyncAPI, validator domain.IDepthUpdateValidator) *KucoinFuturesStreamAPI {
+	return &KucoinFuturesStreamAPI{
+		WebSocket: wc,
+		SyncAPI:   syncAPI,
+		validator: validator,
+		snapshots: syncAPI,
+		symbols:   newFuturesSymbolMapper(),
+	}
+}
+
+func (s *KucoinFuturesStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	topic := fmt.Sprintf("/contractMarket/level2:%s", s.symbols.Native(symbol))
+	subscribtion, err := s.WebSocket.Subscribe(NewSubscribeMessage(topic, false))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			update, err := parseFuturesDepthUpdate(msg, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			out <- update
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Topic:       topic,
+		Unsubscribe: subscribtion.Unsubscribe,
+	}, nil
+}
+
+func (s *KucoinFuturesStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, s.snapshots, s.validator)
+	return maintainer.CreareOrderBook(FuturesProviderName, symbol)
+}
+
+func parseFuturesDepthUpdate(msg []byte, symbol *domain.MarketSymbol) (*domain.OrderBookUpdate, error) {
+	message := &FuturesDepthUpdateModel{}
+	if err := json.Unmarshal(msg, message); err != nil {
+		return nil, err
+	}
+
+	change := strings.Split(message.Change, ",")
+	if len(change) != 3 {
+		return nil, fmt.Errorf("invalid change: %s", message.Change)
+	}
+
+	bids, asks := [][]string{}, [][]string{}
+	level := []string{change[0], change[2]}
+	if change[1] == "buy" {
+		bids = append(bids, level)
+	} else {
+		asks = append(asks, level)
+	}
+
+	return domain.NewOrderBookUpdate(bids, asks, message.Sequence, message.Sequence, symbol), nil
+}
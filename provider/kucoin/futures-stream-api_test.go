@@ -0,0 +1,34 @@
+package kucoin
+
+import (
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFuturesDepthUpdate(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	update, err := parseFuturesDepthUpdate([]byte(`{"sequence":18,"change":"5000.0,sell,83","timestamp":1551770400000}`), symbol)
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(18), update.SequenceStart, "SequenceStart should match")
+	assert.Equal(t, int64(18), update.SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, [][]string{{"5000.0", "83"}}, update.Asks, "Asks should match")
+	assert.Empty(t, update.Bids, "Bids should be empty")
+
+	update, err = parseFuturesDepthUpdate([]byte(`{"sequence":19,"change":"4999.0,buy,0","timestamp":1551770400000}`), symbol)
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, [][]string{{"4999.0", "0"}}, update.Bids, "Bids should match")
+
+	_, err = parseFuturesDepthUpdate([]byte(`{"sequence":20,"change":"4999.0","timestamp":1551770400000}`), symbol)
+	assert.Error(t, err)
+}
+
+func TestFuturesDepthUpdateSequence(t *testing.T) {
+	v := &KucoinDepthUpdateValidator{}
+
+	assert.Nil(t, v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 101, SequenceEnd: 101}, 100))
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 100, SequenceEnd: 100}, 100))
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, v.IsValidUpd(&domain.OrderBookUpdate{SequenceStart: 102, SequenceEnd: 102}, 100))
+}
@@ -0,0 +1,80 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Kucoin/kucoin-go-sdk"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const kucoinFuturesBaseURL = "https://api-futures.kucoin.com"
+
+type KucoinFuturesSyncAPI struct {
+	apiService *kucoin.ApiService
+	symbols    *domain.SymbolMapper
+}
+
+// FuturesOrderBookSnapshot is the full level2 book of the contract, the levels are in the form of [price, size].
+type FuturesOrderBookSnapshot struct {
+	Symbol   string          `json:"symbol"`
+	Sequence int64           `json:"sequence"`
+	Ts       int64           `json:"ts"`
+	Bids     [][]json.Number `json:"bids"`
+	Asks     [][]json.Number `json:"asks"`
+}
+
+func NewKucoinFuturesSyncAPI(baseURL string, httpClient *http.Client) *KucoinFuturesSyncAPI {
+	return &KucoinFuturesSyncAPI{
+		apiService: kucoin.NewApiService(
+			kucoin.ApiBaseURIOption(baseURL),
+			kucoin.ApiRequesterOption(&httpRequester{client: httpClient}),
+		),
+		symbols: newFuturesSymbolMapper(),
+	}
+}
+
+func (api *KucoinFuturesSyncAPI) WsConnOpts() (*WebSocketTokenModel, error) {
+	return wsConnOpts(api.apiService)
+}
+
+func (api *KucoinFuturesSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	req := kucoin.NewRequest(http.MethodGet, "/api/v1/level2/snapshot", map[string]string{"symbol": api.symbols.Native(symbol)})
+	resp, err := api.apiService.Call(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+
+	if !resp.HttpSuccessful() || !resp.ApiSuccessful() {
+		return nil, fmt.Errorf("failed to get order book snapshot: code=%s, msg=%s", resp.Code, resp.Message)
+	}
+
+	data := &FuturesOrderBookSnapshot{}
+	if err = json.Unmarshal(resp.RawData, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, resp.RawData)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Source:       domain.OrderBookSource_Provider,
+		LastUpdateId: data.Sequence,
+		Bids:         futuresLevels(data.Bids, limit),
+		Asks:         futuresLevels(data.Asks, limit),
+	}, nil
+}
+
+func futuresLevels(levels [][]json.Number, limit int) [][]string {
+	if limit > 0 && len(levels) > limit {
+		levels = levels[:limit]
+	}
+
+	result := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		result = append(result, []string{level[0].String(), level[1].String()})
+	}
+
+	return result
+}
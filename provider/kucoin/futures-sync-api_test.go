@@ -0,0 +1,38 @@
+package kucoin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKucoinFuturesSyncAPI_OrderBookSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/level2/snapshot", r.URL.Path)
+		assert.Equal(t, "XBTUSDTM", r.URL.Query().Get("symbol"))
+
+		w.Write([]byte(`{"code":"200000","data":{"symbol":"XBTUSDTM","sequence":100,"asks":[[5000.0,1000],[6000.0,1983]],"bids":[[3200.0,800],[3100.0,100]],"ts":1604643655040584408}}`))
+	}))
+	defer server.Close()
+
+	api := NewKucoinFuturesSyncAPI(server.URL, server.Client())
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	snapshot, err := api.OrderBookSnapshot(symbol, 1)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Equal(t, int64(100), snapshot.LastUpdateId, "LastUpdateId should match")
+	assert.Equal(t, [][]string{{"5000.0", "1000"}}, snapshot.Asks, "Asks should match")
+	assert.Equal(t, [][]string{{"3200.0", "800"}}, snapshot.Bids, "Bids should match")
+}
+
+func TestFuturesSymbolMapper(t *testing.T) {
+	symbols := newFuturesSymbolMapper()
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+	eth, _ := domain.NewMarketSymbol("eth", "usdt")
+
+	assert.Equal(t, "XBTUSDTM", symbols.Native(btc))
+	assert.Equal(t, "ETHUSDTM", symbols.Native(eth))
+}
@@ -0,0 +1,80 @@
+package kucoin
+
+import (
+	"os"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const ProviderName = "kucoin"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	if len(opts.ReplayFiles) > 0 {
+		return NewReplayProvider(opts.ReplayFiles, opts.ReplaySpeed)
+	}
+
+	recorder := opts.NewRecorder()
+	syncAPI := NewKucoinSyncAPI(opts.RestURLOr(envOr("KUCOIN_BASE_URL", kucoinDefaultBaseURL)), opts.NewHTTPClient())
+	streamPool := NewKucoinStreamPool(ProviderName, syncAPI.WsConnOpts, opts.NewDialer(), maxTopicsPerConnection)
+	streamPool.SetRecorder(recorder)
+	validator := &KucoinDepthUpdateValidator{}
+
+	streamAPI := NewKucoinStreamAPI(streamPool, syncAPI, validator)
+	streamAPI.snapshots = domain.NewRecordedSyncAPI(streamAPI.snapshots, recorder, domain.SnapshotSourceOrderBook)
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamPool,
+		StreamAPI:            streamAPI,
+		SyncAPI:              domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+const FuturesProviderName = "kucoin-futures"
+
+func NewFuturesProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	if len(opts.ReplayFiles) > 0 {
+		return NewFuturesReplayProvider(opts.ReplayFiles, opts.ReplaySpeed)
+	}
+
+	recorder := opts.NewRecorder()
+	syncAPI := NewKucoinFuturesSyncAPI(opts.RestURLOr(envOr("KUCOIN_FUTURES_BASE_URL", kucoinFuturesBaseURL)), opts.NewHTTPClient())
+	streamPool := NewKucoinStreamPool(FuturesProviderName, syncAPI.WsConnOpts, opts.NewDialer(), maxTopicsPerConnection)
+	streamPool.SetRecorder(recorder)
+	// the updates of the futures feed have a single sequence, the spot rules cover it as a range of one
+	validator := &KucoinDepthUpdateValidator{}
+
+	streamAPI := NewKucoinFuturesStreamAPI(streamPool, syncAPI, validator)
+	streamAPI.snapshots = domain.NewRecordedSyncAPI(streamAPI.snapshots, recorder, domain.SnapshotSourceOrderBook)
+
+	return &domain.Provider{
+		Name:                 FuturesProviderName,
+		StreamClient:         streamPool,
+		StreamAPI:            streamAPI,
+		SyncAPI:              domain.NewRecordedSyncAPI(syncAPI, recorder, domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newFuturesSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the spot markets, e.g. BTC-USDT.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Separator: "-"})
+}
+
+// newFuturesSymbolMapper creates the mapper of the USDT-margined perpetual contracts, e.g. XBTUSDTM for btc_usdt.
+func newFuturesSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(FuturesProviderName, domain.SymbolFormat{Suffix: "M"})
+}
+
+// envOr returns the value of the environment variable or the default one if it is not set.
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
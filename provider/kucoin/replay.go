@@ -0,0 +1,82 @@
+package kucoin
+
+import (
+	"encoding/json"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/infrastructure/capture"
+)
+
+// NewReplayProvider replays the capture files of the spot provider. The frames go through the same parsing
+// and the same order book maintainers as the live ones. The markets are not validated.
+func NewReplayProvider(paths []string, speed float64) (*domain.Provider, error) {
+	player, err := newPlayer(paths, speed)
+	if err != nil {
+		return nil, err
+	}
+	validator := &KucoinDepthUpdateValidator{}
+
+	streamAPI := NewKucoinStreamAPI(replayStream{player}, nil, validator)
+	streamAPI.snapshots = player.SyncAPI(domain.SnapshotSourceOrderBook)
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         player,
+		StreamAPI:            streamAPI,
+		SyncAPI:              player.SyncAPI(domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// NewFuturesReplayProvider replays the capture files of the futures provider.
+func NewFuturesReplayProvider(paths []string, speed float64) (*domain.Provider, error) {
+	player, err := newPlayer(paths, speed)
+	if err != nil {
+		return nil, err
+	}
+	validator := &KucoinDepthUpdateValidator{}
+
+	streamAPI := NewKucoinFuturesStreamAPI(replayStream{player}, nil, validator)
+	streamAPI.snapshots = player.SyncAPI(domain.SnapshotSourceOrderBook)
+
+	return &domain.Provider{
+		Name:                 FuturesProviderName,
+		StreamClient:         player,
+		StreamAPI:            streamAPI,
+		SyncAPI:              player.SyncAPI(domain.SnapshotSourceSync),
+		DepthUpdateValidator: validator,
+		SymbolMapper:         newFuturesSymbolMapper(),
+	}, nil
+}
+
+func newPlayer(paths []string, speed float64) (*capture.Player, error) {
+	records, err := capture.ReadAll(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return capture.NewPlayer(records, speed, routeFrame), nil
+}
+
+type replayStream struct {
+	*capture.Player
+}
+
+func (s replayStream) Subscribe(channel *WebSocketSubscribeMessage) (*domain.Subscription[[]byte], error) {
+	return s.Player.Subscribe(channel.Topic), nil
+}
+
+// routeFrame routes the frames the way the stream client does, the subscribers get the data of the messages.
+func routeFrame(frame []byte) (string, []byte, bool) {
+	m := &WebSocketDownstreamMessage{}
+	if err := json.Unmarshal(frame, m); err != nil || m.WebSocketMessage == nil {
+		return "", nil, false
+	}
+
+	switch m.Type {
+	case Message, Notice, Command:
+		return m.Topic, m.RawData, true
+	}
+	return "", nil, false
+}
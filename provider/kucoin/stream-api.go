@@ -3,25 +3,39 @@ package kucoin
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/spooky-finn/cryptobridge/domain"
 )
 
 type KucoinStreamAPI struct {
-	WebSocket *KucoinStreamClient
+	WebSocket TopicSubscriber
 	SyncAPI   *KucoinSyncAPI
 
+	validator domain.IDepthUpdateValidator
+	// snapshots serves the snapshots the order books are built of.
+	snapshots domain.ProviderSyncAPI
+	// maxDepth limits the order books built of the public book, the deeper levels are not maintained.
+	// Such a book misses the levels that move into its depth from below, so it is rebuilt when a side shrinks.
+	maxDepth   int
+	symbols    *domain.SymbolMapper
 	apiTimeout time.Duration
 }
 
-func NewKucoinStreamAPI(wc *KucoinStreamClient, syncAPI *KucoinSyncAPI) *KucoinStreamAPI {
-	return &KucoinStreamAPI{
+func NewKucoinStreamAPI(wc TopicSubscriber, syncAPI *KucoinSyncAPI, validator domain.IDepthUpdateValidator) *KucoinStreamAPI {
+	api := &KucoinStreamAPI{
 		WebSocket:  wc,
 		SyncAPI:    syncAPI,
+		validator:  validator,
+		snapshots:  fullOrderBookAPI{syncAPI},
+		symbols:    newSymbolMapper(),
 		apiTimeout: time.Second * 10,
 	}
+	if syncAPI != nil {
+		api.maxDepth = syncAPI.MaxDepth()
+	}
+
+	return api
 }
 
 type DepthUpdateModel struct {
@@ -40,15 +54,23 @@ type OrderBookChanges struct {
 type DethUpdateSubscribtion = *domain.Subscription[*domain.OrderBookUpdate]
 
 func (s *KucoinStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
-	topic := fmt.Sprintf("/market/level2:%s", strings.ToUpper(symbol.Join("-")))
+	topic := fmt.Sprintf("/market/level2:%s", s.symbols.Native(symbol))
 	m := NewSubscribeMessage(topic, false)
 	subscribtion, err := s.WebSocket.Subscribe(m)
+	if err != nil {
+		return nil, err
+	}
 	out := make(chan *domain.OrderBookUpdate)
 
 	go func() {
 		defer close(out)
 
 		for msg := range subscribtion.Stream {
+			if len(msg) == 0 {
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
 			message := &DepthUpdateModel{}
 
 			err := json.Unmarshal(msg, &message)
@@ -68,22 +90,67 @@ func (s *KucoinStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.
 		Stream:      out,
 		Topic:       topic,
 		Unsubscribe: subscribtion.Unsubscribe,
-	}, err
+	}, nil
 }
 
+// GetOrderBook builds the local order book of the full book. Without the credentials it is built of the public book
+// of maxDepth levels, see publicBookValidator.
 func (s *KucoinStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
-	validator := &KucoinDepthUpdateValidator{}
-
-	maintainer := domain.NewOrderBookMaintainer(s, s.SyncAPI, validator)
+	validator := s.validator
+	if s.maxDepth > 0 {
+		validator = &publicBookValidator{IDepthUpdateValidator: s.validator, depth: s.maxDepth}
+	}
+	maintainer := domain.NewOrderBookMaintainer(s, s.snapshots, validator)
 
-	result := maintainer.CreareOrderBook("kucoin", symbol)
+	result := maintainer.CreareOrderBook(ProviderName, symbol)
 	if result.Err != nil {
 		return result
 	}
 
+	// the levels below the public book are not in the snapshot, so they are not kept
+	if s.maxDepth > 0 {
+		result.OrderBook.SetMaxDepth(s.maxDepth)
+	}
+
 	return &domain.CreareOrderBookResult{
 		OrderBook: result.OrderBook,
 		Snapshot:  result.Snapshot,
 		Err:       nil,
 	}
 }
+
+// The shrunken side of the public book is tolerated for this time after the previous rebuild,
+// so the busy market doesn't exhaust the rate limit of the snapshots.
+const publicBookRebuildInterval = time.Second
+
+// publicBookValidator rebuilds the order book built of the public book when a side shrinks below its depth.
+// The updates of the levels below the depth are not applied, so the levels that move up after the cancels are unknown.
+// The side that has never been full is the whole side of the market and is not rebuilt.
+type publicBookValidator struct {
+	domain.IDepthUpdateValidator
+	depth int
+
+	full        [2]bool
+	lastRebuild time.Time
+}
+
+func (v *publicBookValidator) IsValidState(update *domain.OrderBookUpdate, orderBook *domain.OrderBook) error {
+	bids, asks := orderBook.Depth(v.depth)
+
+	shrunk := false
+	for i, side := range [][][]float64{bids, asks} {
+		if len(side) >= v.depth {
+			v.full[i] = true
+		} else if v.full[i] {
+			shrunk = true
+		}
+	}
+
+	if !shrunk || time.Since(v.lastRebuild) < publicBookRebuildInterval {
+		return nil
+	}
+
+	v.full = [2]bool{}
+	v.lastRebuild = time.Now()
+	return fmt.Errorf("the order book shrank below the public depth %d", v.depth)
+}
@@ -14,20 +14,20 @@ import (
 
 func createDeps() (*KucoinStreamAPI, *KucoinSyncAPI) {
 	// Create a new KucoinStreamAPI instance
-	syncAPI := NewKucoinSyncAPI()
+	syncAPI := newTestSyncAPI(kucoinDefaultBaseURL)
 	wsConnectionOpts, err := syncAPI.WsConnOpts()
 	if err != nil {
 		fmt.Printf("Error while creating ws connection options %s", err.Error())
 		panic(err)
 	}
 
-	streamClient := NewKucoinStreamClient(wsConnectionOpts)
+	streamClient := NewKucoinStreamClient(wsConnectionOpts, testDialer)
 
 	if err := streamClient.Connect(); err != nil {
 		fmt.Printf("Error while connecting to kucoin %s", err.Error())
 	}
 
-	streamAPI := NewKucoinStreamAPI(streamClient, syncAPI)
+	streamAPI := NewKucoinStreamAPI(streamClient, syncAPI, &KucoinDepthUpdateValidator{})
 
 	return streamAPI, syncAPI
 }
@@ -132,7 +132,7 @@ func TestWaitForSync(t *testing.T) {
 func TestKucoinCreateMultiplexTunnel(t *testing.T) {
 	// Create a new KucoinStreamAPI instance
 	streamAPI, _ := createDeps()
-	err := streamAPI.WebSocket.CreateMultiplexTunnel("main-tunnel")
+	err := streamAPI.WebSocket.(*KucoinStreamClient).CreateMultiplexTunnel("main-tunnel")
 	if err != nil {
 		t.Errorf("Error while creating multiplex tunnel %s", err.Error())
 		return
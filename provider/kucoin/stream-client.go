@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
-	"net/http"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
 	"time"
 
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"github.com/spooky-finn/cryptobridge/config"
@@ -17,9 +21,20 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+var ErrNotConnected = errors.New("connection is not established")
+
+// Consumers are identified globally, so the subscriptions can be moved between the connections of the pool.
+var consumerIdCounter atomic.Int64
+
+// The connections are identified globally in the captures of the pools.
+var connIdCounter atomic.Int64
+
 const (
 	kucoinDefaultTimeout           = time.Second * 5
 	kucoinDefaultWebsocketEndpoint = "wss://api.kucoin.com"
+	// Used if the instance server doesn't advertise the heartbeat settings.
+	kucoinDefaultPingInterval = 10 * time.Second
+	kucoinDefaultPingTimeout  = 10 * time.Second
 )
 
 // All message types of WebSocket.
@@ -85,11 +100,136 @@ func (s WebSocketServersModel) RandomServer() (*WebSocketServerModel, error) {
 	return s[rand.Intn(l)], nil
 }
 
+// ByLatency returns the servers ordered by the latency of the tcp connect to their endpoints.
+// The unreachable servers follow the reachable ones in the advertised order. The latency is measured
+// once in latencyTTL for each endpoint, the connections of the pool don't probe the servers again.
+func (s WebSocketServersModel) ByLatency(dialer *websocket.Dialer) WebSocketServersModel {
+	// the direct connect latency doesn't tell the latency through the proxy
+	if len(s) < 2 || dialer.Proxy != nil {
+		return s
+	}
+
+	latency := make([]time.Duration, len(s))
+	wg := sync.WaitGroup{}
+	for i, server := range s {
+		wg.Add(1)
+		go func(i int, server *WebSocketServerModel) {
+			defer wg.Done()
+			latency[i] = serverLatency.get(server.Endpoint, dialer.HandshakeTimeout)
+		}(i, server)
+	}
+	wg.Wait()
+
+	order := make([]int, len(s))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := latency[order[i]], latency[order[j]]
+		if (a < 0) != (b < 0) {
+			return b < 0
+		}
+		return a < b
+	})
+
+	result := make(WebSocketServersModel, len(s))
+	for i, idx := range order {
+		result[i] = s[idx]
+	}
+	return result
+}
+
+// Time the measured latency of the instance server is reused.
+const latencyTTL = 10 * time.Minute
+
+type latencyMeasurement struct {
+	latency    time.Duration
+	measuredAt time.Time
+}
+
+// latencyCache keeps the connect latency of the endpoints, -1 if the endpoint is unreachable.
+type latencyCache struct {
+	mu           sync.Mutex
+	measurements map[string]latencyMeasurement
+}
+
+var serverLatency = &latencyCache{measurements: make(map[string]latencyMeasurement)}
+
+func (c *latencyCache) get(endpoint string, timeout time.Duration) time.Duration {
+	c.mu.Lock()
+	m, ok := c.measurements[endpoint]
+	c.mu.Unlock()
+	if ok && time.Since(m.measuredAt) < latencyTTL {
+		return m.latency
+	}
+
+	latency := probe(endpoint, timeout)
+	c.mu.Lock()
+	c.measurements[endpoint] = latencyMeasurement{latency: latency, measuredAt: time.Now()}
+	c.mu.Unlock()
+
+	return latency
+}
+
+// probe measures the tcp connect to the endpoint. The websocket is not dialed, kucoin limits the connections of the token.
+func probe(endpoint string, timeout time.Duration) time.Duration {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		logger.Printf("invalid instance server endpoint %s: %s", endpoint, err)
+		return -1
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		port := "443"
+		if u.Scheme == "ws" {
+			port = "80"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	if timeout <= 0 {
+		timeout = kucoinDefaultTimeout
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		logger.Printf("instance server %s is unavailable: %s", endpoint, err)
+		return -1
+	}
+	conn.Close()
+
+	return time.Since(start)
+}
+
+func (s *WebSocketServerModel) url(token string) string {
+	return fmt.Sprintf("%s?token=%s", s.Endpoint, token)
+}
+
+// pingInterval returns the advertised heartbeat interval.
+func (s *WebSocketServerModel) pingInterval() time.Duration {
+	if s.PingInterval <= 0 {
+		return kucoinDefaultPingInterval
+	}
+	return time.Duration(s.PingInterval) * time.Millisecond
+}
+
+// pingTimeout returns the advertised time to wait for the pong.
+func (s *WebSocketServerModel) pingTimeout() time.Duration {
+	if s.PingTimeout <= 0 {
+		return kucoinDefaultPingTimeout
+	}
+	return time.Duration(s.PingTimeout) * time.Millisecond
+}
+
 // ReadData read the data in channel.
 func (m *WebSocketDownstreamMessage) ReadData(v interface{}) error {
 	return json.Unmarshal(m.RawData, v)
 }
 
+// A WebSocketUnsubscribeMessage represents a message to unsubscribe the public/private channel.
+type WebSocketUnsubscribeMessage WebSocketSubscribeMessage
+
 func NewSubscribeMessage(topic string, privateChannel bool) *WebSocketSubscribeMessage {
 	return &WebSocketSubscribeMessage{
 		WebSocketMessage: &WebSocketMessage{
@@ -102,11 +242,36 @@ func NewSubscribeMessage(topic string, privateChannel bool) *WebSocketSubscribeM
 	}
 }
 
+func NewUnsubscribeMessage(topic string, privateChannel bool) *WebSocketUnsubscribeMessage {
+	return &WebSocketUnsubscribeMessage{
+		WebSocketMessage: &WebSocketMessage{
+			Id:   getMsgId(),
+			Type: UnsubscribeMessage,
+		},
+		Topic:          topic,
+		PrivateChannel: privateChannel,
+		Response:       true,
+	}
+}
+
+// A SubscribtionEntry is an upstream subscription to the topic shared by the consumers.
+// Each consumer has its own channel, the messages of the topic are copied to all of them.
+// An empty message is sent to the consumers when the topic is resubscribed after the connection loss
+// or their buffer overflows, the messages in between are lost.
+type SubscribtionEntry struct {
+	privateChannel bool
+	consumers      map[int64]*domain.StreamConsumer[[]byte]
+}
+
 type KucoinStreamClient struct {
 	// Wait all goroutines quit
 	wg *sync.WaitGroup
 	// Stop subscribing channel
-	done chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	// Closed when the connection is lost or closed
+	closed     chan struct{}
+	closedOnce sync.Once
 	// // Pong channel to check pong message
 	pongs chan string
 	// // ACK channel to check pong message
@@ -116,6 +281,9 @@ type KucoinStreamClient struct {
 	// // Downstream message channel
 	// messages chan *WebSocketDownstreamMessage
 	token *WebSocketTokenModel
+	// The instance server of the connection
+	server *WebSocketServerModel
+	dialer *websocket.Dialer
 
 	conn       *websocket.Conn
 	writeMutex sync.Mutex
@@ -124,53 +292,84 @@ type KucoinStreamClient struct {
 	pintInterval    time.Duration
 	pingTimeout     time.Duration
 
-	clients  map[string]chan []byte
-	tunnelId string
+	mu            sync.Mutex
+	subscriptions map[string]*SubscribtionEntry
+	tunnelId      string
+
+	// connId identifies the connection in the capture of the recorder.
+	connId   string
+	recorder domain.Recorder
 }
 
-func NewKucoinStreamClient(token *WebSocketTokenModel) *KucoinStreamClient {
+func NewKucoinStreamClient(token *WebSocketTokenModel, dialer *websocket.Dialer) *KucoinStreamClient {
 	return &KucoinStreamClient{
 		wg: &sync.WaitGroup{},
 		// errors:   make(chan error, 1),
 		pongs: make(chan string, 1),
 		acks:  make(chan string, 1),
 		// messages: make(chan *WebSocketDownstreamMessage, 2048),
-		token: token,
+		token:  token,
+		dialer: dialer,
 
 		done:            make(chan struct{}),
+		closed:          make(chan struct{}),
 		enableHeartbeat: false,
-		pintInterval:    10 * time.Second,
-		pingTimeout:     time.Duration(token.Servers[0].PingTimeout) * time.Millisecond,
-		clients:         make(map[string]chan []byte),
+		subscriptions:   make(map[string]*SubscribtionEntry),
+		connId:          strconv.FormatInt(connIdCounter.Add(1), 10),
+		recorder:        domain.NopRecorder{},
 	}
 }
 
+// Connect connects to the instance server with the lowest latency. The next servers are tried on the connect errors.
 func (c *KucoinStreamClient) Connect() error {
-	dialer := &websocket.Dialer{
-		Proxy:           http.ProxyFromEnvironment,
-		ReadBufferSize:  512,
-		WriteBufferSize: 256,
+	if len(c.token.Servers) == 0 {
+		return errors.New("No available server ")
+	}
+
+	var err error
+	for _, server := range c.token.Servers.ByLatency(c.dialer) {
+		if err = c.connect(server); err == nil {
+			c.wg.Add(2)
+			go c.read()
+			go c.keepHeartbeat()
+			return nil
+		}
+
+		logger.Printf("failed to connect to the instance server %s: %s", server.Endpoint, err)
 	}
 
-	url := fmt.Sprintf("%s?token=%s", c.token.Servers[0].Endpoint, c.token.Token)
+	return err
+}
+
+func (c *KucoinStreamClient) connect(server *WebSocketServerModel) error {
+	dialer := *c.dialer
+	dialer.ReadBufferSize = 512
+	dialer.WriteBufferSize = 256
+
+	url := server.url(c.token.Token)
 	if config.DebugMode {
 		logger.Printf("connection to the kucoin stream websocket: %s\n", url)
 	}
 	conn, httpResp, err := dialer.Dial(url, nil)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to dial to the kucoin stream websocket. status: %s", httpResp.Status)
+		if httpResp != nil {
+			return errors.Wrapf(err, "Failed to dial to the kucoin stream websocket. status: %s", httpResp.Status)
+		}
+		return errors.Wrap(err, "Failed to dial to the kucoin stream websocket")
 	}
-	c.conn = conn
 
 	// Must read the first welcome message
+	conn.SetReadDeadline(time.Now().Add(kucoinDefaultTimeout))
 	for {
 		m := &WebSocketDownstreamMessage{}
-		if err := c.conn.ReadJSON(m); err != nil {
+		if err := conn.ReadJSON(m); err != nil {
+			conn.Close()
 			return err
 		}
 
 		if m.Type == ErrorMessage {
 			logger.Println("Kucoin error:", m)
+			conn.Close()
 			return errors.Errorf("Error message: %s", helpers.ToJsonString(m))
 		}
 		if m.Type == WelcomeMessage {
@@ -178,53 +377,187 @@ func (c *KucoinStreamClient) Connect() error {
 			break
 		}
 	}
+	conn.SetReadDeadline(time.Time{})
 
-	c.wg.Add(2)
-	go c.read()
-	go c.keepHeartbeat()
+	c.conn = conn
+	c.server = server
+	c.pintInterval = server.pingInterval()
+	c.pingTimeout = server.pingTimeout()
 	return nil
 }
 
+// Server returns the instance server of the connection.
+func (c *KucoinStreamClient) Server() *WebSocketServerModel {
+	return c.server
+}
+
+// Subscribe subscribes to the topic. The upstream subscription is shared by the consumers of the same topic,
+// it is made only for the first consumer and released when the last one unsubscribes.
 func (c *KucoinStreamClient) Subscribe(channel *WebSocketSubscribeMessage) (*domain.Subscription[[]byte], error) {
+	consumerId, consumer, err := c.subscribe(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Subscription[[]byte]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(channel.Topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", channel.Topic, err)
+			}
+		},
+		Topic: channel.Topic,
+	}, nil
+}
+
+func (c *KucoinStreamClient) subscribe(channel *WebSocketSubscribeMessage) (int64, *domain.StreamConsumer[[]byte], error) {
 	c.writeMutex.Lock()
 	defer c.writeMutex.Unlock()
 
-	ch := make(chan []byte, 2048)
-	c.clients[channel.Topic] = ch
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer[[]byte](2048, nil)
 
-	if config.DebugMode {
-		logger.Println("Subscribing to channel", channel.Topic)
+	c.mu.Lock()
+	entry, ok := c.subscriptions[channel.Topic]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return consumerId, consumer, nil
 	}
-	if err := c.conn.WriteJSON(channel); err != nil {
-		return nil, err
+
+	c.subscriptions[channel.Topic] = &SubscribtionEntry{
+		privateChannel: channel.PrivateChannel,
+		consumers:      map[int64]*domain.StreamConsumer[[]byte]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if err := c.request(channel.Id, channel); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, channel.Topic)
+		c.mu.Unlock()
+		consumer.Close()
+		return 0, nil, err
 	}
 
-	if err := c.waitForAck(channel.Id); err != nil {
-		return nil, err
+	return consumerId, consumer, nil
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *KucoinStreamClient) unsubscribe(topic string, consumerId int64) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
 	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+
+	msg := NewUnsubscribeMessage(topic, entry.privateChannel)
+	return c.request(msg.Id, msg)
+}
+
+// attach subscribes to the topic for the consumers of the entry moved from another connection.
+// The consumers are merged if the topic is already subscribed.
+func (c *KucoinStreamClient) attach(topic string, entry *SubscribtionEntry) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	c.mu.Lock()
+	if existing, ok := c.subscriptions[topic]; ok {
+		for consumerId, consumer := range entry.consumers {
+			existing.consumers[consumerId] = consumer
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	c.subscriptions[topic] = entry
+	c.mu.Unlock()
+
+	msg := NewSubscribeMessage(topic, entry.privateChannel)
+	if err := c.request(msg.Id, msg); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, topic)
+		c.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// detach removes all the subscriptions of the connection without closing the channels of the consumers.
+func (c *KucoinStreamClient) detach() map[string]*SubscribtionEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.subscriptions
+	c.subscriptions = make(map[string]*SubscribtionEntry)
+
+	return entries
+}
 
+// request writes the message and waits for its ack. The caller must hold the write mutex.
+func (c *KucoinStreamClient) request(id string, msg interface{}) error {
 	if config.DebugMode {
-		logger.Println("succesfully subscribet to the topic=", channel.Topic)
+		logger.Println("Send message:", helpers.ToJsonString(msg))
 	}
 
-	return &domain.Subscription[[]byte]{
-		Stream: ch,
-		Unsubscribe: func() {
-			// TODO: Implement unsubscribe
-			// c.Unsubscribe(NewUnsubscribeMessage(channel.Topic, channel.PrivateChannel))
-			panic("Unsubscribe not implemented")
-		},
-		Topic: channel.Topic,
-	}, nil
+	if err := c.conn.WriteJSON(msg); err != nil {
+		return err
+	}
+
+	return c.waitForAck(id)
+}
+
+func (c *KucoinStreamClient) hasTopic(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subscriptions[topic]
+	return ok
+}
+
+// TopicCount returns the number of the upstream subscriptions of the connection.
+func (c *KucoinStreamClient) TopicCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.subscriptions)
+}
+
+// Done is closed when the connection is lost or closed.
+func (c *KucoinStreamClient) Done() <-chan struct{} {
+	return c.closed
 }
 
 func (c *KucoinStreamClient) Close() error {
-	close(c.done)
-	close(c.acks)
-	close(c.pongs)
+	c.closeOnce.Do(func() { close(c.done) })
+	c.markClosed()
+
+	err := c.conn.Close()
 	c.wg.Wait()
 
-	return c.conn.NetConn().Close()
+	return err
+}
+
+func (c *KucoinStreamClient) markClosed() {
+	c.closedOnce.Do(func() { close(c.closed) })
 }
 
 func (c *KucoinStreamClient) CreateMultiplexTunnel(tunnelId string) error {
@@ -255,6 +588,8 @@ func (c *KucoinStreamClient) waitForAck(msgId string) error {
 				logger.Printf("received ack message: %s\n", id)
 			}
 			return nil
+		case <-c.closed:
+			return ErrNotConnected
 		case <-time.After(kucoinDefaultTimeout):
 			return errors.Errorf("triggered wait ack message timeout in %v", kucoinDefaultTimeout)
 		}
@@ -272,11 +607,24 @@ func (c *KucoinStreamClient) read() {
 		case <-c.done:
 			return
 		default:
-			m := &WebSocketDownstreamMessage{}
-			if err := c.conn.ReadJSON(m); err != nil {
-				logger.Fatalf("err while reading message from web conn: %s", err.Error())
+			_, frame, err := c.conn.ReadMessage()
+			if err != nil {
+				select {
+				case <-c.done:
+				default:
+					logger.Printf("err while reading message from web conn: %s", err.Error())
+					c.recorder.Disconnect(c.connId)
+				}
+				c.markClosed()
 				return
 			}
+			c.recorder.Frame(c.connId, frame)
+
+			m := &WebSocketDownstreamMessage{}
+			if err := json.Unmarshal(frame, m); err != nil {
+				logger.Printf("err while parsing message: %s, msg %s", err.Error(), string(frame))
+				continue
+			}
 
 			if config.DebugMode {
 				logger.Println("Received message: ", string(m.RawData))
@@ -285,24 +633,50 @@ func (c *KucoinStreamClient) read() {
 			switch m.Type {
 			case WelcomeMessage:
 			case PongMessage:
-				c.pongs <- m.Id
+				select {
+				case c.pongs <- m.Id:
+				default:
+				}
 			case AckMessage:
-				c.acks <- m.Id
+				select {
+				case c.acks <- m.Id:
+				default:
+					logger.Printf("unexpected ack message: %s", m.Id)
+				}
 			case ErrorMessage:
 				logger.Printf("Error message: %s", string(m.RawData))
 
 			case Message, Notice, Command:
-				outCh, ok := c.clients[m.Topic]
+				consumers, ok := c.consumers(m.Topic)
 				if !ok {
 					logger.Printf("Received message for not subscribed topic: %s, msg %#v", m.Topic, string(m.RawData))
 					continue
 				}
-				outCh <- m.RawData
+				for _, consumer := range consumers {
+					consumer.Send(m.RawData)
+				}
 			}
 		}
 	}
 }
 
+// consumers returns the consumers of the topic. The messages are sent to them without holding the mutex.
+func (c *KucoinStreamClient) consumers(topic string) ([]*domain.StreamConsumer[[]byte], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[topic]
+	if !ok {
+		return nil, false
+	}
+
+	consumers := make([]*domain.StreamConsumer[[]byte], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers, true
+}
+
 func (c *KucoinStreamClient) SendMessage(msg interface{}) error {
 	c.writeMutex.Lock()
 	defer c.writeMutex.Unlock()
@@ -340,9 +714,13 @@ func (c *KucoinStreamClient) keepHeartbeat() {
 			select {
 			case <-time.After(c.pingTimeout):
 				logger.Println("failed to receive pong message. connection will be closed")
-				c.Close()
+				// the reader fails on the closed connection and marks the client closed
+				c.conn.Close()
 				return
-			case <-c.pongs:
+			case _, ok := <-c.pongs:
+				if !ok {
+					return
+				}
 				if config.DebugMode {
 					logger.Println(`received pong message`)
 				}
@@ -0,0 +1,417 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// The dialer of the fake servers.
+var testDialer = &websocket.Dialer{HandshakeTimeout: time.Second}
+
+// fakeServer acks every request and records the requests by type.
+type fakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	refuse   bool
+	silent   bool
+	conns    []*websocket.Conn
+	topics   map[string]*websocket.Conn
+	requests []*WebSocketSubscribeMessage
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{topics: make(map[string]*websocket.Conn)}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		refuse := s.refuse
+		s.mu.Unlock()
+		if refuse {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		conn.WriteJSON(WebSocketMessage{Id: "welcome", Type: WelcomeMessage})
+		s.mu.Unlock()
+
+		for {
+			req := &WebSocketSubscribeMessage{}
+			if err := conn.ReadJSON(req); err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			if req.Type == PingMessage {
+				if !s.silent {
+					conn.WriteJSON(WebSocketMessage{Id: req.Id, Type: PongMessage})
+				}
+				s.mu.Unlock()
+				continue
+			}
+			s.requests = append(s.requests, req)
+			if req.Type == SubscribeMessage {
+				s.topics[req.Topic] = conn
+			}
+			conn.WriteJSON(WebSocketMessage{Id: req.Id, Type: AckMessage})
+			s.mu.Unlock()
+		}
+	}))
+
+	return s
+}
+
+func (s *fakeServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *fakeServer) push(topic, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics[topic].WriteJSON(map[string]interface{}{
+		"type":    Message,
+		"topic":   topic,
+		"subject": "trade.l2update",
+		"data":    json.RawMessage(data),
+	})
+}
+
+// drop closes the server side of the connection of the topic.
+func (s *fakeServer) drop(topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics[topic].Close()
+}
+
+func (s *fakeServer) setRefuse(refuse bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refuse = refuse
+}
+
+// setSilent stops the replies to the pings.
+func (s *fakeServer) setSilent(silent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.silent = silent
+}
+
+func (s *fakeServer) requestTypes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	types := make([]string, len(s.requests))
+	for i, req := range s.requests {
+		types[i] = req.Type
+	}
+	return types
+}
+
+func (s *fakeServer) token() (*WebSocketTokenModel, error) {
+	return &WebSocketTokenModel{
+		Token: "token",
+		Servers: WebSocketServersModel{{
+			Endpoint:     s.url(),
+			PingInterval: 10000,
+			PingTimeout:  10000,
+		}},
+	}, nil
+}
+
+func receive(t *testing.T, stream <-chan []byte) []byte {
+	return receiveWithin(t, stream, time.Second)
+}
+
+func receiveWithin(t *testing.T, stream <-chan []byte, timeout time.Duration) []byte {
+	select {
+	case msg := <-stream:
+		return msg
+	case <-time.After(timeout):
+		t.Fatal("message is not received")
+		return nil
+	}
+}
+
+func TestKucoinStreamClient_SharedSubscription(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	token, _ := server.token()
+	client := NewKucoinStreamClient(token, testDialer)
+	assert.NoError(t, client.Connect())
+
+	topic := "/market/level2:BTC-USDT"
+	first, err := client.Subscribe(NewSubscribeMessage(topic, false))
+	assert.NoError(t, err)
+	second, err := client.Subscribe(NewSubscribeMessage(topic, false))
+	assert.NoError(t, err)
+
+	// the second consumer shares the upstream subscription
+	assert.Equal(t, []string{SubscribeMessage}, server.requestTypes())
+
+	server.push(topic, `{"sequenceStart":1}`)
+	assert.JSONEq(t, `{"sequenceStart":1}`, string(receive(t, first.Stream)))
+	assert.JSONEq(t, `{"sequenceStart":1}`, string(receive(t, second.Stream)))
+
+	// the topic is kept until the last consumer leaves
+	first.Unsubscribe()
+	_, ok := <-first.Stream
+	assert.False(t, ok, "Stream of the unsubscribed consumer should be closed")
+	assert.Equal(t, []string{SubscribeMessage}, server.requestTypes())
+
+	second.Unsubscribe()
+	_, ok = <-second.Stream
+	assert.False(t, ok, "Stream of the unsubscribed consumer should be closed")
+	assert.Equal(t, []string{SubscribeMessage, UnsubscribeMessage}, server.requestTypes())
+
+	// unsubscribing twice is a no-op
+	second.Unsubscribe()
+	assert.Equal(t, []string{SubscribeMessage, UnsubscribeMessage}, server.requestTypes())
+
+	assert.NoError(t, client.Close())
+}
+
+func TestKucoinStreamClient_SlowConsumer(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	token, _ := server.token()
+	client := NewKucoinStreamClient(token, testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	topic := "/market/level2:BTC-USDT"
+	slow, err := client.Subscribe(NewSubscribeMessage(topic, false))
+	assert.NoError(t, err)
+	fast, err := client.Subscribe(NewSubscribeMessage(topic, false))
+	assert.NoError(t, err)
+
+	// the overflowed consumer doesn't block the connection
+	count := cap(slow.Stream) + 1
+	for i := 0; i < count; i++ {
+		server.push(topic, `{"sequenceStart":1}`)
+	}
+	for i := 0; i < count; i++ {
+		receive(t, fast.Stream)
+	}
+
+	for i := 0; i < cap(slow.Stream); i++ {
+		receive(t, slow.Stream)
+	}
+	server.push(topic, `{"sequenceStart":2}`)
+	assert.Empty(t, receive(t, slow.Stream), "Overflowed consumer should be interrupted")
+	assert.JSONEq(t, `{"sequenceStart":2}`, string(receive(t, slow.Stream)))
+}
+
+func TestKucoinStreamClient_ServerFailover(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	unreachable := newFakeServer(t)
+	unreachable.Close()
+
+	token := &WebSocketTokenModel{
+		Token: "token",
+		Servers: WebSocketServersModel{
+			{Endpoint: unreachable.url(), PingInterval: 10000, PingTimeout: 10000},
+			{Endpoint: server.url(), PingInterval: 20000, PingTimeout: 5000},
+		},
+	}
+
+	// the unreachable server is skipped
+	assert.Equal(t, server.url(), token.Servers.ByLatency(testDialer)[0].Endpoint)
+
+	client := NewKucoinStreamClient(token, testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	assert.Equal(t, server.url(), client.Server().Endpoint)
+	assert.Equal(t, 20*time.Second, client.pintInterval)
+	assert.Equal(t, 5*time.Second, client.pingTimeout)
+}
+
+func TestWebSocketServersModel_ByLatencyCached(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer listener.Close()
+
+	var accepted atomic.Int64
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			conn.Close()
+		}
+	}()
+
+	unreachable := newFakeServer(t)
+	unreachable.Close()
+
+	servers := WebSocketServersModel{
+		{Endpoint: unreachable.url()},
+		{Endpoint: "ws://" + listener.Addr().String()},
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, servers[1], servers.ByLatency(testDialer)[0])
+	}
+
+	// the endpoint is probed once
+	assert.Eventually(t, func() bool {
+		return accepted.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(1), accepted.Load())
+}
+
+func TestKucoinStreamClient_Heartbeat(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	token := &WebSocketTokenModel{
+		Token:   "token",
+		Servers: WebSocketServersModel{{Endpoint: server.url(), PingInterval: 50, PingTimeout: 100}},
+	}
+	client := NewKucoinStreamClient(token, testDialer)
+	assert.NoError(t, client.Connect())
+	defer client.Close()
+
+	// the connection is kept while the server replies to the pings at the advertised interval
+	select {
+	case <-client.Done():
+		t.Fatal("connection is closed")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	server.setSilent(true)
+	select {
+	case <-client.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection is not closed after the missed pong")
+	}
+}
+
+func TestKucoinStreamPool_Rebalance(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	pool := NewKucoinStreamPool(ProviderName, server.token, testDialer, 1)
+	assert.NoError(t, pool.Connect())
+	defer pool.Close()
+
+	btc, err := pool.Subscribe(NewSubscribeMessage("/market/level2:BTC-USDT", false))
+	assert.NoError(t, err)
+	eth, err := pool.Subscribe(NewSubscribeMessage("/market/level2:ETH-USDT", false))
+	assert.NoError(t, err)
+
+	// the second topic doesn't fit into the first connection
+	assert.Equal(t, 2, pool.ConnectionCount())
+
+	// the topic of the lost connection is moved to a new one, the consumer keeps the stream
+	server.drop(btc.Topic)
+	assert.Eventually(t, func() bool {
+		return len(server.requestTypes()) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, pool.ConnectionCount())
+	assert.Empty(t, receive(t, btc.Stream), "Empty message should notify about the lost messages")
+
+	server.push(btc.Topic, `{"sequenceStart":2}`)
+	assert.JSONEq(t, `{"sequenceStart":2}`, string(receive(t, btc.Stream)))
+	server.push(eth.Topic, `{"sequenceStart":3}`)
+	assert.JSONEq(t, `{"sequenceStart":3}`, string(receive(t, eth.Stream)))
+}
+
+func TestKucoinStreamPool_Reconnect(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	pool := NewKucoinStreamPool(ProviderName, server.token, testDialer, 10)
+	assert.NoError(t, pool.Connect())
+	defer pool.Close()
+
+	btc, err := pool.Subscribe(NewSubscribeMessage("/market/level2:BTC-USDT", false))
+	assert.NoError(t, err)
+
+	// the server is down for the first reconnection attempt
+	server.setRefuse(true)
+	server.drop(btc.Topic)
+	assert.Eventually(t, func() bool {
+		return pool.ConnectionCount() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// the consumer is notified before the topic is resubscribed
+	assert.Empty(t, receive(t, btc.Stream), "Empty message should notify about the lost messages")
+	server.setRefuse(false)
+
+	assert.Eventually(t, func() bool {
+		return len(server.requestTypes()) == 2
+	}, 3*time.Second, 10*time.Millisecond, "Topic should be resubscribed")
+	assert.Equal(t, 1, pool.ConnectionCount())
+
+	server.push(btc.Topic, `{"sequenceStart":5}`)
+	assert.JSONEq(t, `{"sequenceStart":5}`, string(receive(t, btc.Stream)))
+}
+
+func TestKucoinStreamPool_SubscribeWhileDialing(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	tokens := 0
+	pool := NewKucoinStreamPool(ProviderName, func() (*WebSocketTokenModel, error) {
+		if tokens++; tokens == 2 {
+			close(dialing)
+			<-release
+		}
+		return server.token()
+	}, testDialer, 1)
+	assert.NoError(t, pool.Connect())
+	defer pool.Close()
+
+	btc, err := pool.Subscribe(NewSubscribeMessage("/market/level2:BTC-USDT", false))
+	assert.NoError(t, err)
+
+	// the second topic needs a new connection, its token is fetched without holding the mutex
+	subscribed := make(chan error, 1)
+	go func() {
+		_, err := pool.Subscribe(NewSubscribeMessage("/market/level2:ETH-USDT", false))
+		subscribed <- err
+	}()
+	<-dialing
+
+	second, err := pool.Subscribe(NewSubscribeMessage(btc.Topic, false))
+	assert.NoError(t, err, "Subscription to the open connection should not wait for the dial")
+	second.Unsubscribe()
+	assert.Equal(t, 1, pool.ConnectionCount())
+
+	close(release)
+	assert.NoError(t, <-subscribed)
+	assert.Equal(t, 2, pool.ConnectionCount())
+}
@@ -0,0 +1,332 @@
+package kucoin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/domain"
+	promclient "github.com/spooky-finn/cryptobridge/infrastructure/prometheus"
+)
+
+// kucoin limits the number of the topics subscribed over a single connection.
+const maxTopicsPerConnection = 400
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var ErrPoolClosed = errors.New("stream pool is closed")
+
+// TopicSubscriber subscribes to the topics of the kucoin websocket.
+type TopicSubscriber interface {
+	Subscribe(channel *WebSocketSubscribeMessage) (*domain.Subscription[[]byte], error)
+}
+
+// KucoinStreamPool spreads the topics across several websocket connections.
+// A new connection with a fresh token is opened when all the connections reach the topics limit.
+// The topics of a lost connection are moved to the remaining ones, the consumers keep their channels.
+// If no connection can be opened, the topics are retried with the backoff.
+// The mutex is not held while the token is fetched and the connection is dialed.
+type KucoinStreamPool struct {
+	provider  string
+	tokenFn   func() (*WebSocketTokenModel, error)
+	dialer    *websocket.Dialer
+	maxTopics int
+	recorder  domain.Recorder
+
+	mu         sync.Mutex
+	conns      []*KucoinStreamClient
+	connIds    map[*KucoinStreamClient]string
+	topics     map[string]*KucoinStreamClient
+	nextConnId int
+	// topics of the lost connections waiting for the reconnection
+	pending map[string]*SubscribtionEntry
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewKucoinStreamPool(provider string, tokenFn func() (*WebSocketTokenModel, error), dialer *websocket.Dialer, maxTopics int) *KucoinStreamPool {
+	return &KucoinStreamPool{
+		provider:  provider,
+		tokenFn:   tokenFn,
+		dialer:    dialer,
+		maxTopics: maxTopics,
+		recorder:  domain.NopRecorder{},
+		connIds:   make(map[*KucoinStreamClient]string),
+		topics:    make(map[string]*KucoinStreamClient),
+		pending:   make(map[string]*SubscribtionEntry),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetRecorder makes the connections capture the raw frames. It must be called before Connect.
+func (p *KucoinStreamPool) SetRecorder(recorder domain.Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.recorder = recorder
+}
+
+// Connect opens the first connection of the pool.
+func (p *KucoinStreamPool) Connect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.open()
+	return err
+}
+
+func (p *KucoinStreamPool) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil {
+			logger.Printf("failed to close the connection of the pool: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *KucoinStreamPool) Subscribe(channel *WebSocketSubscribeMessage) (*domain.Subscription[[]byte], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn, ok := p.topics[channel.Topic]
+	if !ok {
+		var err error
+		if conn, err = p.acquire(); err != nil {
+			return nil, err
+		}
+		// the topic may be subscribed while the mutex was released
+		if existing, ok := p.topics[channel.Topic]; ok {
+			conn = existing
+		}
+	}
+
+	consumerId, consumer, err := conn.subscribe(channel)
+	if err != nil {
+		return nil, err
+	}
+	p.topics[channel.Topic] = conn
+	p.updateMetrics(conn)
+
+	return &domain.Subscription[[]byte]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := p.unsubscribe(channel.Topic, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from topic=%s: %s", channel.Topic, err)
+			}
+		},
+		Topic: channel.Topic,
+	}, nil
+}
+
+// ConnectionCount returns the number of the open connections of the pool.
+func (p *KucoinStreamPool) ConnectionCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.conns)
+}
+
+func (p *KucoinStreamPool) unsubscribe(topic string, consumerId int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.pending[topic]; ok {
+		if consumer, ok := entry.consumers[consumerId]; ok {
+			consumer.Close()
+			delete(entry.consumers, consumerId)
+		}
+		if len(entry.consumers) == 0 {
+			delete(p.pending, topic)
+		}
+		return nil
+	}
+
+	conn, ok := p.topics[topic]
+	if !ok {
+		return nil
+	}
+
+	err := conn.unsubscribe(topic, consumerId)
+	if !conn.hasTopic(topic) {
+		delete(p.topics, topic)
+	}
+	p.updateMetrics(conn)
+
+	return err
+}
+
+// acquire returns the least loaded connection below the topics limit or opens a new one.
+// The caller must hold the mutex, it is released while the new connection is opened.
+func (p *KucoinStreamPool) acquire() (*KucoinStreamClient, error) {
+	for {
+		var best *KucoinStreamClient
+		bestCount := p.maxTopics
+
+		for _, conn := range p.conns {
+			if count := conn.TopicCount(); count < bestCount {
+				best, bestCount = conn, count
+			}
+		}
+
+		if best != nil {
+			return best, nil
+		}
+
+		// the new connection may be filled by the concurrent subscriptions, the least loaded one is chosen again
+		if _, err := p.open(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// open dials a new connection with a fresh token, the tokens can't be shared by the connections.
+// The caller must hold the mutex, it is released while the token is fetched and the connection is dialed.
+func (p *KucoinStreamPool) open() (*KucoinStreamClient, error) {
+	recorder := p.recorder
+	p.mu.Unlock()
+	conn, err := p.dial(recorder)
+	p.mu.Lock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-p.done:
+		conn.Close()
+		return nil, ErrPoolClosed
+	default:
+	}
+
+	p.conns = append(p.conns, conn)
+	p.connIds[conn] = strconv.Itoa(p.nextConnId)
+	p.nextConnId++
+	p.updateMetrics(conn)
+
+	go p.watch(conn)
+	return conn, nil
+}
+
+func (p *KucoinStreamPool) dial(recorder domain.Recorder) (*KucoinStreamClient, error) {
+	token, err := p.tokenFn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ws connection options: %w", err)
+	}
+
+	conn := NewKucoinStreamClient(token, p.dialer)
+	conn.recorder = recorder
+	if err := conn.Connect(); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (p *KucoinStreamPool) watch(conn *KucoinStreamClient) {
+	select {
+	case <-p.done:
+	case <-conn.Done():
+		p.rebalance(conn)
+	}
+}
+
+// rebalance moves the topics of the lost connection to the remaining connections.
+// The consumers are interrupted before their topic is resubscribed, so the order books are rebuilt from a fresh snapshot.
+func (p *KucoinStreamPool) rebalance(lost *KucoinStreamClient) {
+	p.mu.Lock()
+	for i, conn := range p.conns {
+		if conn == lost {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			break
+		}
+	}
+	promclient.StreamConnectionTopicsGauge.DeleteLabelValues(p.provider, p.connIds[lost])
+	delete(p.connIds, lost)
+
+	entries := lost.detach()
+	for topic, entry := range entries {
+		delete(p.topics, topic)
+		p.pending[topic] = entry
+
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	p.mu.Unlock()
+
+	logger.Printf("connection is lost, moving %d topics to the other connections", len(entries))
+
+	delay := minReconnectDelay
+	for !p.resubscribePending() {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// resubscribePending subscribes the pending topics. It returns false if some of them have failed.
+func (p *KucoinStreamPool) resubscribePending() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return true
+	default:
+	}
+
+	topics := make([]string, 0, len(p.pending))
+	for topic := range p.pending {
+		topics = append(topics, topic)
+	}
+
+	for _, topic := range topics {
+		conn, err := p.acquire()
+		if err != nil {
+			logger.Printf("failed to resubscribe to topic=%s: %s", topic, err)
+			return false
+		}
+
+		// the consumers may unsubscribe while the mutex was released
+		entry, ok := p.pending[topic]
+		if !ok {
+			continue
+		}
+		if err := conn.attach(topic, entry); err != nil {
+			logger.Printf("failed to resubscribe to topic=%s: %s", topic, err)
+			return false
+		}
+
+		delete(p.pending, topic)
+		p.topics[topic] = conn
+		p.updateMetrics(conn)
+	}
+
+	return true
+}
+
+func (p *KucoinStreamPool) updateMetrics(conn *KucoinStreamClient) {
+	if id, ok := p.connIds[conn]; ok {
+		promclient.StreamConnectionTopicsGauge.WithLabelValues(p.provider, id).Set(float64(conn.TopicCount()))
+	}
+}
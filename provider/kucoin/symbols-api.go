@@ -0,0 +1,81 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Kucoin/kucoin-go-sdk"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type SymbolModel struct {
+	Symbol        string `json:"symbol"`
+	BaseCurrency  string `json:"baseCurrency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	EnableTrading bool   `json:"enableTrading"`
+}
+
+type ContractModel struct {
+	Symbol        string `json:"symbol"`
+	BaseCurrency  string `json:"baseCurrency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Status        string `json:"status"`
+}
+
+func (api *KucoinSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	data := []SymbolModel{}
+	if err := call(api.apiService, "/api/v2/symbols", &data); err != nil {
+		return nil, fmt.Errorf("failed to get symbols: %w", err)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data))
+	for _, s := range data {
+		if !s.EnableTrading {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(s.BaseCurrency, s.QuoteCurrency); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
+
+// Symbols returns the markets of the active perpetual contracts.
+func (api *KucoinFuturesSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	data := []ContractModel{}
+	if err := call(api.apiService, "/api/v1/contracts/active", &data); err != nil {
+		return nil, fmt.Errorf("failed to get contracts: %w", err)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data))
+	for _, c := range data {
+		if c.Status != "Open" || !strings.HasSuffix(c.Symbol, "M") {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(c.BaseCurrency, c.QuoteCurrency); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
+
+func call(apiService *kucoin.ApiService, path string, v interface{}) error {
+	resp, err := apiService.Call(kucoin.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		return err
+	}
+
+	if !resp.HttpSuccessful() || !resp.ApiSuccessful() {
+		return fmt.Errorf("code=%s, msg=%s", resp.Code, resp.Message)
+	}
+
+	if err := json.Unmarshal(resp.RawData, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, resp.RawData)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package kucoin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKucoinFuturesSyncAPI_Symbols(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/contracts/active", r.URL.Path)
+
+		w.Write([]byte(`{"code":"200000","data":[
+			{"symbol":"XBTUSDTM","baseCurrency":"XBT","quoteCurrency":"USDT","status":"Open"},
+			{"symbol":"ETHUSDTM","baseCurrency":"ETH","quoteCurrency":"USDT","status":"Paused"},
+			{"symbol":"XBTMZ24","baseCurrency":"XBT","quoteCurrency":"USD","status":"Open"}
+		]}`))
+	}))
+	defer server.Close()
+
+	api := NewKucoinFuturesSyncAPI(server.URL, server.Client())
+	symbols, err := api.Symbols()
+
+	assert.NoError(t, err, "Unexpected error")
+	if assert.Len(t, symbols, 1, "Only the open perpetual contracts should be listed") {
+		assert.Equal(t, "btc_usdt", symbols[0].String(), "The legacy asset code should be translated")
+	}
+}
@@ -2,11 +2,13 @@ package kucoin
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/Kucoin/kucoin-go-sdk"
 	"github.com/spooky-finn/cryptobridge/domain"
@@ -14,19 +16,38 @@ import (
 
 var logger = log.New(log.Writer(), "[kucoin] ", log.LstdFlags)
 
+// The depths of the public part order books.
+var partOrderBookDepths = []int{20, 100}
+
+const kucoinDefaultBaseURL = "https://api.kucoin.com"
+
+// ErrDepthNeedsCredentials is returned for the depth beyond the public books, the full book needs the credentials.
+var ErrDepthNeedsCredentials = errors.New("the depth exceeds the public order book, set the kucoin api key")
+
 type KucoinSyncAPI struct {
-	endpoint   string
 	apiService *kucoin.ApiService
+	symbols    *domain.SymbolMapper
+	// The full book is requested only with the credentials.
+	hasCredentials bool
 }
 
-func NewKucoinSyncAPI() *KucoinSyncAPI {
+// NewKucoinSyncAPI creates the api of the baseURL. The credentials are read from the environment.
+func NewKucoinSyncAPI(baseURL string, httpClient *http.Client) *KucoinSyncAPI {
+	apiKey := os.Getenv("KUCOIN_API_KEY")
+	if apiKey == "" {
+		logger.Println("kucoin api key is not set, the local order books are built from the top 100 levels")
+	}
+
 	return &KucoinSyncAPI{
-		endpoint: os.Getenv("KUCOIN_BASE_URL"),
 		apiService: kucoin.NewApiService(
-			kucoin.ApiKeyOption(os.Getenv("KUCOIN_API_KEY")),
+			kucoin.ApiBaseURIOption(baseURL),
+			kucoin.ApiRequesterOption(&httpRequester{client: httpClient}),
+			kucoin.ApiKeyOption(apiKey),
 			kucoin.ApiSecretOption(os.Getenv("KUCOIN_SECRET_KEY")),
 			kucoin.ApiPassPhraseOption(os.Getenv("KUCOIN_PASSPHRASE")),
 		),
+		symbols:        newSymbolMapper(),
+		hasCredentials: apiKey != "",
 	}
 }
 
@@ -49,7 +70,12 @@ type OrderBookSnapshot struct {
 }
 
 func (api *KucoinSyncAPI) WsConnOpts() (*WebSocketTokenModel, error) {
-	resp, err := api.apiService.WebSocketPublicToken()
+	return wsConnOpts(api.apiService)
+}
+
+// wsConnOpts requests the token of the public channels. The spot and the futures apis share the bullet endpoint.
+func wsConnOpts(apiService *kucoin.ApiService) (*WebSocketTokenModel, error) {
+	resp, err := apiService.WebSocketPublicToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ws connection options: %w", err)
 	}
@@ -80,15 +106,51 @@ func (api *KucoinSyncAPI) WsConnOpts() (*WebSocketTokenModel, error) {
 	return token, nil
 }
 
+// OrderBookSnapshot returns the top levels of the book, the whole book if the limit is not positive. The public
+// level2_20 and level2_100 books are requested if the limit allows, they need no credentials and are less
+// rate-limited than the full book.
 func (api *KucoinSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
-	s := strings.ToUpper(symbol.Join("-"))
-	resp, err := api.apiService.AggregatedFullOrderBookV3(s)
+	for _, depth := range partOrderBookDepths {
+		if limit > 0 && limit <= depth {
+			resp, err := api.apiService.AggregatedPartOrderBook(api.symbols.Native(symbol), int64(depth))
+			return orderBookSnapshot(resp, err, limit)
+		}
+	}
+
+	return api.FullOrderBookSnapshot(symbol, limit)
+}
+
+// FullOrderBookSnapshot returns the full book, the local order books are built from it.
+// The full book needs the credentials. Without them the deepest public book of MaxDepth levels is returned
+// and the deeper limit is rejected with ErrDepthNeedsCredentials.
+func (api *KucoinSyncAPI) FullOrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if !api.hasCredentials {
+		if limit > api.MaxDepth() {
+			return nil, fmt.Errorf("%w: depth=%d, public depth=%d", ErrDepthNeedsCredentials, limit, api.MaxDepth())
+		}
+		resp, err := api.apiService.AggregatedPartOrderBook(api.symbols.Native(symbol), int64(api.MaxDepth()))
+		return orderBookSnapshot(resp, err, limit)
+	}
+
+	resp, err := api.apiService.AggregatedFullOrderBookV3(api.symbols.Native(symbol))
+	return orderBookSnapshot(resp, err, limit)
+}
+
+// MaxDepth returns the depth of the book returned by FullOrderBookSnapshot, zero if the whole book is returned.
+func (api *KucoinSyncAPI) MaxDepth() int {
+	if api.hasCredentials {
+		return 0
+	}
+	return partOrderBookDepths[len(partOrderBookDepths)-1]
+}
+
+func orderBookSnapshot(resp *kucoin.ApiResponse, err error, limit int) (*domain.OrderBookSnapshot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
 	}
 
-	if !resp.HttpSuccessful() {
-		return nil, fmt.Errorf("failed to get order book snapshot: %s", resp.Message)
+	if !resp.HttpSuccessful() || !resp.ApiSuccessful() {
+		return nil, fmt.Errorf("failed to get order book snapshot: code=%s, msg=%s", resp.Code, resp.Message)
 	}
 	data := &OrderBookSnapshot{}
 	if err = json.Unmarshal(resp.RawData, data); err != nil {
@@ -100,11 +162,11 @@ func (api *KucoinSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit i
 		return nil, fmt.Errorf("failed to convert sequence to int: %w, response: %s", err, resp.RawData)
 	}
 
-	if len(data.Asks) > limit {
+	if limit > 0 && len(data.Asks) > limit {
 		data.Asks = data.Asks[:limit]
 	}
 
-	if len(data.Bids) > limit {
+	if limit > 0 && len(data.Bids) > limit {
 		data.Bids = data.Bids[:limit]
 	}
 
@@ -116,5 +178,38 @@ func (api *KucoinSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit i
 	}
 
 	return obSnapshot, nil
+}
+
+// fullOrderBookAPI serves the full book to the maintainer of the local order book.
+// Without the credentials the limit is clamped to the public book, the order book keeps only its depth
+// and the deeper snapshots of it are rejected with domain.ErrDepthExceedsOrderBook.
+type fullOrderBookAPI struct {
+	*KucoinSyncAPI
+}
+
+func (api fullOrderBookAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if maxDepth := api.MaxDepth(); maxDepth > 0 && (limit <= 0 || limit > maxDepth) {
+		limit = maxDepth
+	}
+	return api.FullOrderBookSnapshot(symbol, limit)
+}
+
+// httpRequester sends the requests of the sdk with the client of the provider options,
+// the basic requester of the sdk reconfigures the default client.
+type httpRequester struct {
+	client *http.Client
+}
+
+func (r *httpRequester) Request(request *kucoin.Request, timeout time.Duration) (*kucoin.Response, error) {
+	req, err := request.HttpRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
+	return kucoin.NewResponse(request, resp, nil), nil
 }
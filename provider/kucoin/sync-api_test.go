@@ -1,7 +1,10 @@
 package kucoin
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spooky-finn/cryptobridge/domain"
@@ -9,7 +12,7 @@ import (
 )
 
 func TestWSConnOpts(t *testing.T) {
-	api := NewKucoinSyncAPI()
+	api := newTestSyncAPI(kucoinDefaultBaseURL)
 
 	opts, err := api.WsConnOpts()
 	if err != nil {
@@ -27,7 +30,7 @@ func TestGetOrderBookSnapshot(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	api := NewKucoinSyncAPI()
+	api := newTestSyncAPI(kucoinDefaultBaseURL)
 
 	symbol, _ := domain.NewMarketSymbol("BTC", "USDT")
 
@@ -45,3 +48,127 @@ func TestGetOrderBookSnapshot(t *testing.T) {
 	assert.Equal(t, 5, len(snapshot.Asks))
 
 }
+
+func TestKucoinSyncAPI_OrderBookSnapshotDepth(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		limit  int
+		path   string
+	}{
+		{"Top20", "", 5, "/api/v1/market/orderbook/level2_20"},
+		{"Top100", "", 50, "/api/v1/market/orderbook/level2_100"},
+		{"FullWithCredentials", "key", 500, "/api/v3/market/orderbook/level2"},
+		{"UnlimitedWithoutCredentials", "", 0, "/api/v1/market/orderbook/level2_100"},
+		{"UnlimitedWithCredentials", "key", 0, "/api/v3/market/orderbook/level2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.path, r.URL.Path)
+				assert.Equal(t, "BTC-USDT", r.URL.Query().Get("symbol"))
+				assert.Equal(t, tt.apiKey, r.Header.Get("KC-API-KEY"))
+
+				w.Write([]byte(`{"code":"200000","data":{"sequence":"3262786978","time":1550653727731,"bids":[["6500.12","0.45054140"],["6500.11","0.45054140"]],"asks":[["6500.16","0.57753524"],["6500.15","0.57753524"]]}}`))
+			}))
+			defer server.Close()
+			t.Setenv("KUCOIN_API_KEY", tt.apiKey)
+
+			api := newTestSyncAPI(server.URL)
+			symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+			snapshot, err := api.OrderBookSnapshot(symbol, tt.limit)
+
+			assert.NoError(t, err, "Unexpected error")
+			assert.Equal(t, int64(3262786978), snapshot.LastUpdateId, "LastUpdateId should match")
+			assert.Len(t, snapshot.Bids, 2, "Bids should match")
+		})
+	}
+}
+
+func TestKucoinSyncAPI_DepthNeedsCredentials(t *testing.T) {
+	t.Setenv("KUCOIN_API_KEY", "")
+	api := newTestSyncAPI(kucoinDefaultBaseURL)
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	// the depth beyond the public book is rejected without a request, the local order books clamp it
+	_, err := api.OrderBookSnapshot(symbol, 500)
+	assert.ErrorIs(t, err, ErrDepthNeedsCredentials)
+
+}
+
+func TestFullOrderBookAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/market/orderbook/level2", r.URL.Path, "The local order book should be built from the full book")
+
+		w.Write([]byte(`{"code":"200000","data":{"sequence":"1","time":1550653727731,"bids":[],"asks":[]}}`))
+	}))
+	defer server.Close()
+	t.Setenv("KUCOIN_API_KEY", "key")
+
+	api := fullOrderBookAPI{newTestSyncAPI(server.URL)}
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	_, err := api.OrderBookSnapshot(symbol, 10)
+
+	assert.NoError(t, err, "Unexpected error")
+}
+
+func TestFullOrderBookAPI_WithoutCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/market/orderbook/level2_100", r.URL.Path, "The local order book should be built from the public book")
+
+		w.Write([]byte(`{"code":"200000","data":{"sequence":"1","time":1550653727731,"bids":[],"asks":[]}}`))
+	}))
+	defer server.Close()
+	t.Setenv("KUCOIN_API_KEY", "")
+
+	api := fullOrderBookAPI{newTestSyncAPI(server.URL)}
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	_, err := api.OrderBookSnapshot(symbol, 1000)
+
+	assert.NoError(t, err, "Limit should be clamped to the public book")
+}
+
+func TestKucoinStreamAPI_MaxDepth(t *testing.T) {
+	// the order books built of the public book keep its depth
+	t.Setenv("KUCOIN_API_KEY", "")
+	api := NewKucoinStreamAPI(nil, newTestSyncAPI(kucoinDefaultBaseURL), &KucoinDepthUpdateValidator{})
+	assert.Equal(t, 100, api.maxDepth)
+
+	t.Setenv("KUCOIN_API_KEY", "key")
+	api = NewKucoinStreamAPI(nil, newTestSyncAPI(kucoinDefaultBaseURL), &KucoinDepthUpdateValidator{})
+	assert.Equal(t, 0, api.maxDepth, "Order books built of the full book should not be limited")
+}
+
+func TestPublicBookValidator(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	ob := domain.NewOrderBook(ProviderName, symbol, &domain.OrderBookSnapshot{
+		LastUpdateId: 1,
+		Bids:         [][]string{{"100", "1"}, {"99", "1"}, {"98", "1"}},
+		Asks:         [][]string{{"101", "1"}, {"102", "1"}},
+	})
+	v := &publicBookValidator{IDepthUpdateValidator: &KucoinDepthUpdateValidator{}, depth: 3}
+
+	// the asks have never been full, it is the whole side of the market
+	assert.NoError(t, v.IsValidState(nil, ob))
+
+	// the cancelled bid leaves the gap below the depth
+	ob.ApplyUpdate(domain.NewOrderBookUpdate([][]string{{"99", "0"}}, nil, 2, 2, symbol))
+	assert.Error(t, v.IsValidState(nil, ob), "Shrunken side should rebuild the order book")
+
+	// the side is full again after the rebuild
+	ob.ApplyUpdate(domain.NewOrderBookUpdate([][]string{{"99", "1"}}, nil, 3, 3, symbol))
+	assert.NoError(t, v.IsValidState(nil, ob))
+
+	// the rebuilds are throttled
+	ob.ApplyUpdate(domain.NewOrderBookUpdate([][]string{{"99", "0"}}, nil, 4, 4, symbol))
+	assert.NoError(t, v.IsValidState(nil, ob), "Rebuild should not be repeated within the interval")
+
+	v.lastRebuild = time.Now().Add(-publicBookRebuildInterval)
+	assert.Error(t, v.IsValidState(nil, ob))
+}
+
+// newTestSyncAPI creates the api of the baseURL with the default transports.
+func newTestSyncAPI(baseURL string) *KucoinSyncAPI {
+	return NewKucoinSyncAPI(baseURL, domain.ProviderOptions{}.NewHTTPClient())
+}
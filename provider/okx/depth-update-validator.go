@@ -0,0 +1,59 @@
+package okx
+
+import (
+	"hash/crc32"
+	"strings"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// Number of the levels of each side included into the checksum.
+const checksumDepth = 25
+
+type OkxDepthUpdateValidator struct{}
+
+func (v *OkxDepthUpdateValidator) IsValidUpd(update *domain.OrderBookUpdate, orderBookLastUpdId int64) error {
+	// seqId is equal to prevSeqId when there is no change in the order book
+	if update.SequenceEnd <= orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutdated
+	}
+
+	if update.PrevSequenceEnd != orderBookLastUpdId {
+		return domain.ErrOrderBookUpdateIsOutOfSequece
+	}
+
+	return nil
+}
+
+// IsValidState compares the checksum of the update with the checksum of the top 25 levels of the local order book.
+func (v *OkxDepthUpdateValidator) IsValidState(update *domain.OrderBookUpdate, orderBook *domain.OrderBook) error {
+	bids, asks := orderBook.RawDepth(checksumDepth)
+	if checksum(bids, asks) != update.Checksum {
+		return domain.ErrOrderBookChecksumMismatch
+	}
+
+	return nil
+}
+
+func (v *OkxDepthUpdateValidator) IsErrOutOfSequece(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutOfSequece
+}
+
+func (v *OkxDepthUpdateValidator) IsErrOutdated(err error) bool {
+	return err == domain.ErrOrderBookUpdateIsOutdated
+}
+
+// checksum is crc32 of the string "bid1Px:bid1Sz:ask1Px:ask1Sz:bid2Px:..." built of the original strings sent by okx.
+func checksum(bids, asks [][]string) uint32 {
+	parts := make([]string, 0, 4*checksumDepth)
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i][0], bids[i][1])
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i][0], asks[i][1])
+		}
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
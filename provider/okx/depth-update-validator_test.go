@@ -0,0 +1,81 @@
+package okx
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthUpdateValidator(t *testing.T) {
+	v := &OkxDepthUpdateValidator{}
+
+	upd := &domain.OrderBookUpdate{
+		SequenceEnd:     123,
+		PrevSequenceEnd: 120,
+	}
+
+	err := v.IsValidUpd(upd, 123)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutdated, err, "Error should match")
+
+	err = v.IsValidUpd(upd, 120)
+	assert.Nil(t, err, "Error should be nil")
+
+	err = v.IsValidUpd(upd, 119)
+	assert.Equal(t, domain.ErrOrderBookUpdateIsOutOfSequece, err, "Error should match")
+}
+
+func TestDepthUpdateValidator_IsValidState(t *testing.T) {
+	v := &OkxDepthUpdateValidator{}
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+
+	ob := domain.NewOrderBook(ProviderName, symbol, &domain.OrderBookSnapshot{
+		LastUpdateId: 123,
+		Bids:         [][]string{{"3366.1", "7"}, {"3366", "6"}},
+		Asks:         [][]string{{"3366.8", "9"}, {"3368", "8"}, {"3372", "8"}},
+	})
+
+	expected := crc32.ChecksumIEEE([]byte("3366.1:7:3366.8:9:3366:6:3368:8:3372:8"))
+
+	err := v.IsValidState(&domain.OrderBookUpdate{Checksum: expected}, ob)
+	assert.Nil(t, err, "Error should be nil")
+
+	err = v.IsValidState(&domain.OrderBookUpdate{Checksum: expected + 1}, ob)
+	assert.Equal(t, domain.ErrOrderBookChecksumMismatch, err, "Error should match")
+}
+
+func TestDepthUpdateValidator_IsValidState_OriginalStrings(t *testing.T) {
+	v := &OkxDepthUpdateValidator{}
+	symbol, _ := domain.NewMarketSymbol("shib", "usdt")
+
+	ob := domain.NewOrderBook(ProviderName, symbol, &domain.OrderBookSnapshot{
+		LastUpdateId: 123,
+		Bids:         [][]string{{"0.10", "1e-8"}},
+		Asks:         [][]string{{"0.110", "2.50"}},
+	})
+
+	// the trailing zeros and the exponent are kept as sent
+	expected := crc32.ChecksumIEEE([]byte("0.10:1e-8:0.110:2.50"))
+
+	err := v.IsValidState(&domain.OrderBookUpdate{Checksum: expected}, ob)
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestParseBookUpdates(t *testing.T) {
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	msg := &PushMessage{
+		Action: "snapshot",
+		Data:   []byte(`[{"asks":[["8476.98","415","0","13"]],"bids":[["8476.97","256","0","12"]],"ts":"1597026383085","checksum":-855196043,"prevSeqId":-1,"seqId":123456}]`),
+	}
+
+	updates, err := parseBookUpdates(msg, symbol)
+
+	assert.NoError(t, err, "Unexpected error")
+	assert.Len(t, updates, 1)
+	assert.True(t, updates[0].IsSnapshot, "Update should be a snapshot")
+	assert.Equal(t, int64(123456), updates[0].SequenceEnd, "SequenceEnd should match")
+	assert.Equal(t, int64(-1), updates[0].PrevSequenceEnd, "PrevSequenceEnd should match")
+	assert.Equal(t, uint32(0xcd06be75), updates[0].Checksum, "Checksum should keep the bits of the signed value")
+	assert.Equal(t, [][]string{{"8476.98", "415"}}, updates[0].Asks, "Asks should match")
+}
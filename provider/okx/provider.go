@@ -0,0 +1,26 @@
+package okx
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+const ProviderName = "okx"
+
+func NewProvider(opts domain.ProviderOptions) (*domain.Provider, error) {
+	streamClient := NewOkxStreamClient(opts.StreamURLOr(okxDefaultWebsocketEndpoint), opts.NewDialer())
+	syncAPI := NewOkxSyncAPI(opts.RestURLOr(okxDefaultBaseURL), opts.NewHTTPClient())
+	validator := &OkxDepthUpdateValidator{}
+
+	return &domain.Provider{
+		Name:                 ProviderName,
+		StreamClient:         streamClient,
+		StreamAPI:            NewOkxStreamAPI(streamClient, validator),
+		SyncAPI:              syncAPI,
+		DepthUpdateValidator: validator,
+		SymbolsAPI:           syncAPI,
+		SymbolMapper:         newSymbolMapper(),
+	}, nil
+}
+
+// newSymbolMapper creates the mapper of the instruments, e.g. BTC-USDT.
+func newSymbolMapper() *domain.SymbolMapper {
+	return domain.NewProviderSymbolMapper(ProviderName, domain.SymbolFormat{Separator: "-"})
+}
@@ -0,0 +1,119 @@
+package okx
+
+import (
+	"encoding/json"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// The books channel sends the snapshot of 400 levels and then the incremental updates.
+const booksChannel = "books"
+
+type OkxStreamAPI struct {
+	streamClient *OkxStreamClient
+	validator    domain.IDepthUpdateValidator
+	symbols      *domain.SymbolMapper
+}
+
+type BookData struct {
+	Asks      [][]string `json:"asks"`
+	Bids      [][]string `json:"bids"`
+	Ts        string     `json:"ts"`
+	Checksum  int32      `json:"checksum"`
+	PrevSeqId int64      `json:"prevSeqId"`
+	SeqId     int64      `json:"seqId"`
+}
+
+func NewOkxStreamAPI(client *OkxStreamClient, validator domain.IDepthUpdateValidator) *OkxStreamAPI {
+	return &OkxStreamAPI{
+		streamClient: client,
+		validator:    validator,
+		symbols:      newSymbolMapper(),
+	}
+}
+
+func (s *OkxStreamAPI) DepthDiffStream(symbol *domain.MarketSymbol) (*domain.Subscription[*domain.OrderBookUpdate], error) {
+	subscribtion, err := s.streamClient.Subscribe(booksArg(s.symbols.Native(symbol)))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *domain.OrderBookUpdate)
+
+	go func() {
+		defer close(out)
+
+		for msg := range subscribtion.Stream {
+			if msg.Action == DisconnectMessage {
+				// the updates are lost until the channel is subscribed again
+				out <- domain.NewInterruptedUpdate(symbol)
+				continue
+			}
+
+			updates, err := parseBookUpdates(msg, symbol)
+			if err != nil {
+				logger.Printf("Error unmarshaling message: %s", err)
+				continue
+			}
+
+			for _, update := range updates {
+				out <- update
+			}
+		}
+	}()
+
+	return &domain.Subscription[*domain.OrderBookUpdate]{
+		Stream:      out,
+		Unsubscribe: subscribtion.Unsubscribe,
+		Topic:       subscribtion.Topic,
+	}, nil
+}
+
+// RequestSnapshot resubscribes to the books channel, okx sends the snapshot on every subscription.
+func (s *OkxStreamAPI) RequestSnapshot(symbol *domain.MarketSymbol) error {
+	return s.streamClient.Resubscribe(booksArg(s.symbols.Native(symbol)))
+}
+
+// GetOrderBook builds the order book of the books channel snapshot, the REST snapshot has no seqId to sync the updates with.
+// The order book is rebuilt by RequestSnapshot, so the sync API is not involved.
+func (s *OkxStreamAPI) GetOrderBook(symbol *domain.MarketSymbol) *domain.CreareOrderBookResult {
+	maintainer := domain.NewOrderBookMaintainer(s, nil, s.validator)
+	return maintainer.CreateOrderBookFromStream(ProviderName, symbol)
+}
+
+func parseBookUpdates(msg *PushMessage, symbol *domain.MarketSymbol) ([]*domain.OrderBookUpdate, error) {
+	var data []BookData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return nil, err
+	}
+
+	updates := make([]*domain.OrderBookUpdate, 0, len(data))
+	for _, d := range data {
+		update := domain.NewOrderBookUpdate(
+			trimLevels(d.Bids), trimLevels(d.Asks),
+			d.PrevSeqId+1, d.SeqId,
+			symbol,
+		)
+		update.PrevSequenceEnd = d.PrevSeqId
+		update.IsSnapshot = msg.Action == "snapshot"
+		update.Checksum = uint32(d.Checksum)
+
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
+
+func booksArg(instId string) Arg {
+	return Arg{Channel: booksChannel, InstId: instId}
+}
+
+// trimLevels drops the deprecated and the number of orders fields of the level.
+func trimLevels(levels [][]string) [][]string {
+	for i, level := range levels {
+		if len(level) > 2 {
+			levels[i] = level[:2]
+		}
+	}
+
+	return levels
+}
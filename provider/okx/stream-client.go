@@ -0,0 +1,377 @@
+package okx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/config"
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/redial"
+)
+
+var logger = log.New(log.Writer(), "[okx] ", log.LstdFlags)
+
+const (
+	okxDefaultWebsocketEndpoint = "wss://ws.okx.com:8443/ws/v5/public"
+	okxDefaultTimeout           = 10 * time.Second
+	// The connection is closed by okx if there is no message in 30 seconds.
+	pingInterval = 20 * time.Second
+)
+
+// Action of the message emitted to the subscribers when the connection is lost.
+const DisconnectMessage = "disconnect"
+
+var ErrNotConnected = errors.New("connection is not established")
+
+var consumerIdCounter atomic.Int64
+
+type Arg struct {
+	Channel string `json:"channel"`
+	InstId  string `json:"instId"`
+}
+
+type WebSocketRequestModel struct {
+	Id   string `json:"id"`
+	Op   string `json:"op"`
+	Args []Arg  `json:"args"`
+}
+
+type WebSocketResponseModel struct {
+	Id     string          `json:"id"`
+	Event  string          `json:"event"`
+	Code   string          `json:"code"`
+	Msg    string          `json:"msg"`
+	Arg    *Arg            `json:"arg"`
+	Action string          `json:"action"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// A PushMessage is the data pushed by the channel with its action.
+type PushMessage struct {
+	Action string
+	Data   json.RawMessage
+}
+
+// A SubscribtionEntry is the upstream subscription to the channel shared by the consumers.
+// Each consumer has its own channel, the disconnect message is sent to it when the connection is lost.
+type SubscribtionEntry struct {
+	consumers map[int64]*domain.StreamConsumer[*PushMessage]
+}
+
+// OkxStreamClient is the connection to the public websocket. The lost connection is redialed with the backoff
+// and the channels are subscribed again, so okx sends the new snapshots.
+type OkxStreamClient struct {
+	endpoint string
+	dialer   *websocket.Dialer
+	conn     *websocket.Conn
+	reqId    atomic.Int64
+
+	writeMutex    sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[Arg]*SubscribtionEntry
+	pending       map[string]chan *WebSocketResponseModel
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewOkxStreamClient(endpoint string, dialer *websocket.Dialer) *OkxStreamClient {
+	return &OkxStreamClient{
+		endpoint:      endpoint,
+		dialer:        dialer,
+		subscriptions: make(map[Arg]*SubscribtionEntry),
+		pending:       make(map[string]chan *WebSocketResponseModel),
+		done:          make(chan struct{}),
+	}
+}
+
+func (c *OkxStreamClient) Connect() error {
+	conn, _, err := c.dialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial to the okx websocket: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		// restored by the reconnect in the meantime
+		c.mu.Unlock()
+		return conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	go c.read(conn)
+	go c.keepHeartbeat(conn)
+	return nil
+}
+
+func (c *OkxStreamClient) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Subscribe subscribes to the channel. The upstream subscription is shared by the consumers of the same channel,
+// it is made only for the first consumer and released when the last one unsubscribes.
+func (c *OkxStreamClient) Subscribe(arg Arg) (*domain.Subscription[*PushMessage], error) {
+	consumerId := consumerIdCounter.Add(1)
+	consumer := domain.NewStreamConsumer(2048, &PushMessage{Action: DisconnectMessage})
+
+	c.mu.Lock()
+	entry, ok := c.subscriptions[arg]
+	if ok {
+		entry.consumers[consumerId] = consumer
+		c.mu.Unlock()
+		return c.newSubscription(arg, consumerId, consumer), nil
+	}
+
+	c.subscriptions[arg] = &SubscribtionEntry{
+		consumers: map[int64]*domain.StreamConsumer[*PushMessage]{consumerId: consumer},
+	}
+	c.mu.Unlock()
+
+	if config.DebugMode {
+		logger.Println("subscribing to the", arg)
+	}
+
+	if err := c.send("subscribe", arg); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, arg)
+		c.mu.Unlock()
+		consumer.Close()
+		return nil, fmt.Errorf("failed to subscribe to channel=%s, instId=%s: %w", arg.Channel, arg.InstId, err)
+	}
+
+	return c.newSubscription(arg, consumerId, consumer), nil
+}
+
+// Resubscribe renews the subscription on the server side. The subscribers keep their channels.
+func (c *OkxStreamClient) Resubscribe(arg Arg) error {
+	if err := c.send("unsubscribe", arg); err != nil {
+		return err
+	}
+
+	return c.send("subscribe", arg)
+}
+
+func (c *OkxStreamClient) newSubscription(arg Arg, consumerId int64, consumer *domain.StreamConsumer[*PushMessage]) *domain.Subscription[*PushMessage] {
+	return &domain.Subscription[*PushMessage]{
+		Stream: consumer.Stream(),
+		Unsubscribe: func() {
+			if err := c.unsubscribe(arg, consumerId); err != nil {
+				logger.Printf("failed to unsubscribe from channel=%s, instId=%s: %s", arg.Channel, arg.InstId, err)
+			}
+		},
+		Topic: fmt.Sprintf("%s:%s", arg.Channel, arg.InstId),
+	}
+}
+
+// unsubscribe closes the channel of the consumer. The upstream subscription is released when no consumers are left.
+func (c *OkxStreamClient) unsubscribe(arg Arg, consumerId int64) error {
+	c.mu.Lock()
+	entry, ok := c.subscriptions[arg]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+
+	consumer, ok := entry.consumers[consumerId]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	consumer.Close()
+	delete(entry.consumers, consumerId)
+
+	if len(entry.consumers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, arg)
+	c.mu.Unlock()
+
+	return c.send("unsubscribe", arg)
+}
+
+// send writes the operation and waits for the event with the same request id.
+func (c *OkxStreamClient) send(op string, arg Arg) error {
+	id := strconv.FormatInt(c.reqId.Add(1), 10)
+	respCh := make(chan *WebSocketResponseModel, 1)
+
+	c.mu.Lock()
+	conn := c.conn
+	c.pending[id] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	c.writeMutex.Lock()
+	err := conn.WriteJSON(WebSocketRequestModel{Id: id, Op: op, Args: []Arg{arg}})
+	c.writeMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.Event == "error" {
+			return fmt.Errorf("okx error: code=%s, msg=%s", resp.Code, resp.Msg)
+		}
+		return nil
+	case <-time.After(okxDefaultTimeout):
+		return fmt.Errorf("triggered wait response timeout in %v", okxDefaultTimeout)
+	}
+}
+
+func (c *OkxStreamClient) read(conn *websocket.Conn) {
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			logger.Printf("error while reading from connection: %s", err)
+			c.onDisconnect(conn)
+			return
+		}
+
+		if string(msg) == "pong" {
+			continue
+		}
+
+		resp := &WebSocketResponseModel{}
+		if err := json.Unmarshal(msg, resp); err != nil {
+			logger.Printf("failed to unmarshal message: %s, msg: %s", err, string(msg))
+			continue
+		}
+
+		c.handle(resp)
+	}
+}
+
+func (c *OkxStreamClient) handle(resp *WebSocketResponseModel) {
+	switch resp.Event {
+	case "":
+		if resp.Arg == nil {
+			return
+		}
+		for _, consumer := range c.consumers(*resp.Arg) {
+			consumer.Send(&PushMessage{Action: resp.Action, Data: resp.Data})
+		}
+
+	default:
+		if resp.Event == "error" {
+			logger.Printf("okx error: id=%s, code=%s, msg=%s", resp.Id, resp.Code, resp.Msg)
+		}
+
+		c.mu.Lock()
+		respCh, ok := c.pending[resp.Id]
+		c.mu.Unlock()
+		if ok {
+			select {
+			case respCh <- resp:
+			default:
+			}
+		}
+	}
+}
+
+// consumers returns the consumers of the channel. The messages are sent to them without holding the mutex.
+func (c *OkxStreamClient) consumers(arg Arg) []*domain.StreamConsumer[*PushMessage] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.subscriptions[arg]
+	if !ok {
+		return nil
+	}
+
+	consumers := make([]*domain.StreamConsumer[*PushMessage], 0, len(entry.consumers))
+	for _, consumer := range entry.consumers {
+		consumers = append(consumers, consumer)
+	}
+	return consumers
+}
+
+// onDisconnect redials with the backoff unless the connection was closed by the client.
+// The consumers are interrupted before the channels are subscribed again, the updates in between are lost.
+func (c *OkxStreamClient) onDisconnect(conn *websocket.Conn) {
+	c.mu.Lock()
+	if c.conn != conn {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+
+	args := make([]Arg, 0, len(c.subscriptions))
+	for arg, entry := range c.subscriptions {
+		args = append(args, arg)
+		for _, consumer := range entry.consumers {
+			consumer.Interrupt()
+		}
+	}
+	c.mu.Unlock()
+
+	resubscribe := func() error { return c.resubscribe(args) }
+	if redial.Redial(logger, c.done, c.Connect, resubscribe, c.closeConn) {
+		logger.Printf("reconnected to the okx websocket, %d channels are subscribed", len(args))
+	}
+}
+
+func (c *OkxStreamClient) closeConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *OkxStreamClient) resubscribe(args []Arg) error {
+	for _, arg := range args {
+		if err := c.send("subscribe", arg); err != nil {
+			return fmt.Errorf("channel=%s, instId=%s: %w", arg.Channel, arg.InstId, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *OkxStreamClient) keepHeartbeat(conn *websocket.Conn) {
+	pt := time.NewTicker(pingInterval)
+	defer pt.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-pt.C:
+			c.writeMutex.Lock()
+			err := conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			c.writeMutex.Unlock()
+			if err != nil {
+				logger.Printf("err while writing ping message to the conn: %s", err.Error())
+				return
+			}
+		}
+	}
+}
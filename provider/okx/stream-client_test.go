@@ -0,0 +1,104 @@
+package okx
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/provider/wstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeStreamServer answers the operations with their ids, the subscriptions are kept by the instrument.
+func newFakeStreamServer(t *testing.T) *wstest.Server {
+	return wstest.NewServer(t, func(msg []byte) ([]interface{}, []string) {
+		req := WebSocketRequestModel{}
+		json.Unmarshal(msg, &req)
+
+		var subscribed []string
+		if req.Op == "subscribe" {
+			subscribed = append(subscribed, req.Args[0].InstId)
+		}
+		return []interface{}{WebSocketResponseModel{Id: req.Id, Event: req.Op, Arg: &req.Args[0]}}, subscribed
+	})
+}
+
+func push(server *wstest.Server, arg Arg, data string) {
+	server.Push(arg.InstId, WebSocketResponseModel{Arg: &arg, Action: "update", Data: json.RawMessage(data)})
+}
+
+func newTestClient(t *testing.T, server *wstest.Server) *OkxStreamClient {
+	client := NewOkxStreamClient(server.Endpoint(), websocket.DefaultDialer)
+	if !assert.NoError(t, client.Connect()) {
+		t.FailNow()
+	}
+	return client
+}
+
+func TestOkxStreamClient_Reconnect(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	defer client.Close()
+
+	arg := booksArg("BTC-USDT")
+	first, err := client.Subscribe(arg)
+	assert.NoError(t, err)
+	second, err := client.Subscribe(arg)
+	assert.NoError(t, err)
+
+	// each consumer receives all the messages
+	push(server, arg, `[{"seqId":1}]`)
+	assert.Equal(t, `[{"seqId":1}]`, string(wstest.Receive(t, first.Stream).Data))
+	assert.Equal(t, `[{"seqId":1}]`, string(wstest.Receive(t, second.Stream).Data))
+
+	server.DropConnections()
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, first.Stream).Action, "the consumer should be interrupted")
+	assert.Equal(t, DisconnectMessage, wstest.Receive(t, second.Stream).Action, "the consumer should be interrupted")
+
+	assert.Eventually(t, func() bool {
+		return server.Subscribed(arg.InstId)
+	}, 5*time.Second, 10*time.Millisecond, "the channel should be subscribed again")
+
+	push(server, arg, `[{"seqId":5}]`)
+	assert.Equal(t, `[{"seqId":5}]`, string(wstest.Receive(t, first.Stream).Data))
+	assert.Equal(t, `[{"seqId":5}]`, string(wstest.Receive(t, second.Stream).Data))
+}
+
+func TestOkxStreamClient_ConcurrentOperations(t *testing.T) {
+	server := newFakeStreamServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	defer client.Close()
+
+	arg := booksArg("BTC-USDT")
+	_, err := client.Subscribe(arg)
+	assert.NoError(t, err)
+
+	// the responses of the operations with the same arg are correlated by the request ids
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- client.Resubscribe(arg)
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("the operation is not answered")
+		}
+	}
+}
+
+func TestOkxStreamClient_CloseTwice(t *testing.T) {
+	client := NewOkxStreamClient("ws://127.0.0.1:0", websocket.DefaultDialer)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+}
@@ -0,0 +1,54 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+type InstrumentsResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		InstId   string `json:"instId"`
+		BaseCcy  string `json:"baseCcy"`
+		QuoteCcy string `json:"quoteCcy"`
+		State    string `json:"state"`
+	} `json:"data"`
+}
+
+func (api *OkxSyncAPI) Symbols() ([]*domain.MarketSymbol, error) {
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/public/instruments?instType=SPOT", api.baseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instruments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &InstrumentsResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if data.Code != "0" {
+		return nil, fmt.Errorf("failed to get instruments: code=%s, msg=%s", data.Code, data.Msg)
+	}
+
+	result := make([]*domain.MarketSymbol, 0, len(data.Data))
+	for _, inst := range data.Data {
+		if inst.State != "live" {
+			continue
+		}
+		if symbol, err := api.symbols.Canonical(inst.BaseCcy, inst.QuoteCcy); err == nil {
+			result = append(result, symbol)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	okxDefaultBaseURL   = "https://www.okx.com/api/v5"
+	okxMaxSnapshotDepth = 400
+)
+
+// OkxSyncAPI requests the order book over REST. The REST snapshot has no seqId,
+// so the local order book is initialized by the snapshot of the books channel.
+type OkxSyncAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	symbols    *domain.SymbolMapper
+}
+
+type BooksResponse struct {
+	Code string     `json:"code"`
+	Msg  string     `json:"msg"`
+	Data []BookData `json:"data"`
+}
+
+func NewOkxSyncAPI(baseURL string, httpClient *http.Client) *OkxSyncAPI {
+	return &OkxSyncAPI{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		symbols:    newSymbolMapper(),
+	}
+}
+
+func (api *OkxSyncAPI) OrderBookSnapshot(symbol *domain.MarketSymbol, limit int) (*domain.OrderBookSnapshot, error) {
+	if limit > okxMaxSnapshotDepth {
+		limit = okxMaxSnapshotDepth
+	}
+
+	query := url.Values{}
+	query.Set("instId", api.symbols.Native(symbol))
+	query.Set("sz", strconv.Itoa(limit))
+
+	resp, err := api.httpClient.Get(fmt.Sprintf("%s/market/books?%s", api.baseURL, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data := &BooksResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w, response: %s", err, body)
+	}
+
+	if data.Code != "0" || len(data.Data) == 0 {
+		return nil, fmt.Errorf("failed to get order book snapshot: code=%s, msg=%s", data.Code, data.Msg)
+	}
+
+	book := data.Data[0]
+	ts, _ := strconv.ParseInt(book.Ts, 10, 64)
+
+	return &domain.OrderBookSnapshot{
+		Source:         domain.OrderBookSource_Provider,
+		LastUpdateTime: ts,
+		Bids:           trimLevels(book.Bids),
+		Asks:           trimLevels(book.Asks),
+	}, nil
+}
@@ -0,0 +1,42 @@
+// Package redial restores the lost websocket connections of the stream clients.
+package redial
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	initialDelay = time.Second
+	maxDelay     = 30 * time.Second
+)
+
+// Redial connects with the backoff until it succeeds or done is closed, then subscribes the topics of the lost connection again.
+// When the resubscription fails the restored connection is closed by closeConn, the reader fails on it and redials again.
+// It returns true when the connection is restored and the topics are subscribed.
+func Redial(logger *log.Logger, done <-chan struct{}, connect, resubscribe func() error, closeConn func()) bool {
+	delay := initialDelay
+	for {
+		select {
+		case <-done:
+			return false
+		case <-time.After(delay):
+		}
+
+		if err := connect(); err != nil {
+			logger.Printf("failed to reconnect to the websocket: %s", err)
+			if delay *= 2; delay > maxDelay {
+				delay = maxDelay
+			}
+			continue
+		}
+
+		if err := resubscribe(); err != nil {
+			logger.Printf("failed to resubscribe after the reconnection: %s", err)
+			closeConn()
+			return false
+		}
+
+		return true
+	}
+}
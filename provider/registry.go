@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider/binance"
+	"github.com/spooky-finn/cryptobridge/provider/bybit"
+	"github.com/spooky-finn/cryptobridge/provider/coinbase"
+	"github.com/spooky-finn/cryptobridge/provider/gateio"
+	"github.com/spooky-finn/cryptobridge/provider/huobi"
+	"github.com/spooky-finn/cryptobridge/provider/kraken"
+	"github.com/spooky-finn/cryptobridge/provider/kucoin"
+	"github.com/spooky-finn/cryptobridge/provider/okx"
+)
+
+var registry = struct {
+	mu        sync.RWMutex
+	factories map[string]domain.ProviderFactory
+}{
+	factories: make(map[string]domain.ProviderFactory),
+}
+
+// The providers that record their traffic to the recorder of the options.
+var capturing = map[string]bool{
+	binance.ProviderName:        true,
+	binance.FuturesProviderName: true,
+	kucoin.ProviderName:         true,
+	kucoin.FuturesProviderName:  true,
+}
+
+func init() {
+	Register(binance.ProviderName, binance.NewProvider)
+	Register(binance.FuturesProviderName, binance.NewFuturesProvider)
+	Register(kucoin.ProviderName, kucoin.NewProvider)
+	Register(kucoin.FuturesProviderName, kucoin.NewFuturesProvider)
+	Register(huobi.ProviderName, huobi.NewProvider)
+	Register(gateio.ProviderName, gateio.NewProvider)
+	Register(okx.ProviderName, okx.NewProvider)
+	Register(bybit.ProviderName, bybit.NewProvider)
+	Register(bybit.LinearProviderName, bybit.NewLinearProvider)
+	Register(kraken.ProviderName, kraken.NewProvider)
+	Register(coinbase.ProviderName, coinbase.NewProvider)
+}
+
+// Register makes a provider available by the name. Registering the same name twice is a programming error.
+func Register(name string, factory domain.ProviderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if factory == nil {
+		panic("provider: register factory is nil for " + name)
+	}
+	if _, ok := registry.factories[name]; ok {
+		panic("provider: register called twice for " + name)
+	}
+
+	registry.factories[name] = factory
+}
+
+// Registered returns the sorted list of the registered provider names.
+func Registered() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SupportsCapture reports whether the provider records its traffic, see domain.ProviderOptions.Recorder.
+func SupportsCapture(name string) bool {
+	return capturing[name]
+}
+
+func newProvider(name string, opts domain.ProviderOptions) (*domain.Provider, error) {
+	registry.mu.RLock()
+	factory, ok := registry.factories[name]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownProvider, name)
+	}
+
+	p, err := factory(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate provider %s: %w", name, err)
+	}
+
+	return p, nil
+}
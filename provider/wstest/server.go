@@ -0,0 +1,159 @@
+// Package wstest provides the fake websocket feed for the tests of the stream clients.
+package wstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// A Handler answers the request read from the connection.
+// It returns the replies written back to the connection and the topics subscribed by the request.
+type Handler func(req []byte) (replies []interface{}, subscribed []string)
+
+// A Server is the fake feed. It keeps the topics subscribed by each connection and pushes the messages to them.
+// The handler is called under the mutex of the server, it may keep its state without the locking.
+type Server struct {
+	*httptest.Server
+
+	// Encode makes the websocket message of the reply, the json text message by default.
+	Encode func(v interface{}) (messageType int, data []byte)
+
+	mu     sync.Mutex
+	conns  map[*websocket.Conn][]string
+	handle Handler
+}
+
+func NewServer(t *testing.T, handle Handler) *Server {
+	s := &Server{
+		Encode: encodeJSON,
+		conns:  make(map[*websocket.Conn][]string),
+		handle: handle,
+	}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = nil
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+
+		for {
+			_, req, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			replies, subscribed := s.handle(req)
+			if _, ok := s.conns[conn]; ok {
+				s.conns[conn] = append(s.conns[conn], subscribed...)
+			}
+			for _, reply := range replies {
+				s.write(conn, reply)
+			}
+			s.mu.Unlock()
+		}
+	}))
+
+	return s
+}
+
+// Endpoint is the websocket url of the server.
+func (s *Server) Endpoint() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Push sends the message to the connections subscribed to the topic.
+func (s *Server) Push(topic string, msg interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn, topics := range s.conns {
+		if contains(topics, topic) {
+			s.write(conn, msg)
+		}
+	}
+}
+
+func (s *Server) Subscribed(topic string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, topics := range s.conns {
+		if contains(topics, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// DropConnections closes the connections, the clients see the connection loss.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// write sends the message to the connection. The caller must hold the mutex.
+func (s *Server) write(conn *websocket.Conn, v interface{}) {
+	messageType, data := s.Encode(v)
+	conn.WriteMessage(messageType, data)
+}
+
+// Receive returns the next message of the stream or fails the test when nothing is received in time.
+func Receive[T any](t *testing.T, stream chan T) T {
+	t.Helper()
+
+	select {
+	case msg := <-stream:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message is received")
+		var zero T
+		return zero
+	}
+}
+
+func encodeJSON(v interface{}) (int, []byte) {
+	data, _ := json.Marshal(v)
+	return websocket.TextMessage, data
+}
+
+func contains(topics []string, topic string) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
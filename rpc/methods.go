@@ -51,13 +51,68 @@ func (s *server) GetOrderBookSnapshot(ctx context.Context, in *gen.GetOrderBookS
 	}
 
 	return &gen.GetOrderBookSnapshotResponse{
-		LastUpdTs: snapshot.LastUpdateId,
-		Source:    selectOrderBookSource(snapshot.Source),
-		Bids:      bids,
-		Asks:      asks,
+		LastUpdTs:    snapshot.LastUpdateId,
+		Source:       selectOrderBookSource(snapshot.Source),
+		Bids:         bids,
+		Asks:         asks,
+		NativeSymbol: snapshot.NativeSymbol,
 	}, nil
 }
 
+func (s *server) GetMarkPrice(ctx context.Context, in *gen.GetMarkPriceRequest) (*gen.GetMarkPriceResponse, error) {
+	marketSymbol, err := s.parseFuturesRequest(in.Provider, in.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	markPrice, err := s.futuresMarketDataUseCase.GetMarkPrice(in.Provider, marketSymbol)
+	if err != nil {
+		logger.Printf("error getting mark price: %s", err)
+		return nil, err
+	}
+
+	return &gen.GetMarkPriceResponse{
+		MarkPrice:    markPrice.MarkPrice,
+		IndexPrice:   markPrice.IndexPrice,
+		Ts:           markPrice.Time,
+		NativeSymbol: markPrice.NativeSymbol,
+	}, nil
+}
+
+func (s *server) GetFundingRate(ctx context.Context, in *gen.GetFundingRateRequest) (*gen.GetFundingRateResponse, error) {
+	marketSymbol, err := s.parseFuturesRequest(in.Provider, in.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	fundingRate, err := s.futuresMarketDataUseCase.GetFundingRate(in.Provider, marketSymbol)
+	if err != nil {
+		logger.Printf("error getting funding rate: %s", err)
+		return nil, err
+	}
+
+	return &gen.GetFundingRateResponse{
+		FundingRate:   fundingRate.FundingRate,
+		NextFundingTs: fundingRate.NextFundingTime,
+		Ts:            fundingRate.Time,
+		NativeSymbol:  fundingRate.NativeSymbol,
+	}, nil
+}
+
+func (s *server) parseFuturesRequest(provider, market string) (*domain.MarketSymbol, error) {
+	if !s.validationService.IsSupportedProvider(provider) {
+		return nil, fmt.Errorf("provider %s is not supported", provider)
+	}
+
+	marketSymbol, err := domain.NewMarketSymbolFromString(market)
+	if err != nil {
+		logger.Printf("error parsing market symbol: %s", err)
+		return nil, fmt.Errorf("invalid market symbol %s. Correct market symbol should use _ as a separator", market)
+	}
+
+	return marketSymbol, nil
+}
+
 func selectOrderBookSource(source domain.OrderBookSource) gen.OrderBookSource {
 	switch source {
 	case domain.OrderBookSource_LocalOrderBook:
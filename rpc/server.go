@@ -1,23 +1,95 @@
 package rpc
 
 import (
+	"fmt"
+	"io"
+
+	"github.com/spooky-finn/cryptobridge/domain"
 	gen "github.com/spooky-finn/cryptobridge/gen"
+	"github.com/spooky-finn/cryptobridge/infrastructure/capture"
 	"github.com/spooky-finn/cryptobridge/provider"
 	"github.com/spooky-finn/cryptobridge/usecase"
 )
 
 type server struct {
 	orderbookSnapshotUseCase *usecase.OrderBookSnapshotUseCase
+	futuresMarketDataUseCase *usecase.FuturesMarketDataUseCase
 	gen.UnimplementedMarketDataServiceServer
 	validationService *ValidationService
+	connManager       *provider.ConnectionManager
 }
 
-func NewServer(conf *ValidationServiceConfig) *server {
-	connManager := provider.NewConnectionManager()
+func NewServer(conf *ValidationServiceConfig) (*server, error) {
+	opts, err := providerOptions(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	connManager, err := provider.NewConnectionManager(conf.AvailableProviders, opts)
+	if err != nil {
+		closeRecorders(opts)
+		return nil, err
+	}
 	connManager.Init()
 
 	return &server{
 		orderbookSnapshotUseCase: usecase.NewOrderBookSnapshotUseCase(connManager),
+		futuresMarketDataUseCase: usecase.NewFuturesMarketDataUseCase(connManager),
 		validationService:        NewValidationService(conf),
+		connManager:              connManager,
+	}, nil
+}
+
+// Close closes the connections of the providers and flushes the captures.
+func (s *server) Close() {
+	s.connManager.Close()
+}
+
+// providerOptions configures the capture and the replay of the providers.
+// The capture file is created only for the providers that record their traffic.
+func providerOptions(conf *ValidationServiceConfig) (map[string]domain.ProviderOptions, error) {
+	opts := make(map[string]domain.ProviderOptions, len(conf.AvailableProviders))
+
+	for _, name := range conf.AvailableProviders {
+		if _, ok := opts[name]; ok {
+			continue
+		}
+		var o domain.ProviderOptions
+
+		if conf.ReplayDir != "" {
+			files, err := capture.Files(conf.ReplayDir, name)
+			if err != nil {
+				closeRecorders(opts)
+				return nil, err
+			}
+			if len(files) == 0 {
+				closeRecorders(opts)
+				return nil, fmt.Errorf("no capture files of %s in %s", name, conf.ReplayDir)
+			}
+			o.ReplayFiles = files
+			o.ReplaySpeed = conf.ReplaySpeed
+		}
+
+		if conf.CaptureDir != "" && provider.SupportsCapture(name) {
+			recorder, err := capture.NewRecorder(conf.CaptureDir, name)
+			if err != nil {
+				closeRecorders(opts)
+				return nil, err
+			}
+			o.Recorder = recorder
+		}
+
+		opts[name] = o
+	}
+
+	return opts, nil
+}
+
+// closeRecorders closes the recorders of the options that are not handed over to the connection manager.
+func closeRecorders(opts map[string]domain.ProviderOptions) {
+	for _, o := range opts {
+		if closer, ok := o.Recorder.(io.Closer); ok {
+			closer.Close()
+		}
 	}
 }
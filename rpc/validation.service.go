@@ -2,6 +2,11 @@ package rpc
 
 type ValidationServiceConfig struct {
 	AvailableProviders []string
+	// CaptureDir is the dir the raw traffic of the providers is captured to. Nothing is captured if it is empty.
+	CaptureDir string
+	// ReplayDir is the dir of the capture files the providers replay instead of connecting to the venues.
+	ReplayDir   string
+	ReplaySpeed float64
 }
 
 type ValidationService struct {
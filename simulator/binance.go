@@ -0,0 +1,245 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+// The binance error of the unknown symbols.
+const binanceInvalidSymbol = -1121
+
+// BinanceServer serves the binance combined-stream websocket at /stream, the websocket api at /ws-api/v3
+// and the depth and the exchangeInfo endpoints of the rest api at /api/v3.
+type BinanceServer struct {
+	*Exchange
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+}
+
+// NewBinanceServer starts the server of the markets, the symbols are written without a separator, e.g. BTCUSDT.
+func NewBinanceServer(conf Config) *BinanceServer {
+	s := &BinanceServer{
+		Exchange: newExchange(conf, binanceSymbol),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", s.serveStream)
+	mux.HandleFunc("/ws-api/v3", s.serveWSAPI)
+	mux.HandleFunc("/api/v3/depth", s.serveDepth)
+	mux.HandleFunc("/api/v3/exchangeInfo", s.serveExchangeInfo)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// StreamURL is the combined-stream endpoint.
+func (s *BinanceServer) StreamURL() string {
+	return wsURL(s.server.URL) + "/stream"
+}
+
+// WSAPIURL is the endpoint of the websocket api.
+func (s *BinanceServer) WSAPIURL() string {
+	return wsURL(s.server.URL) + "/ws-api/v3"
+}
+
+// RestURL is the base url of the rest api.
+func (s *BinanceServer) RestURL() string {
+	return s.server.URL + "/api/v3"
+}
+
+// ProviderOptions points the binance provider to the server.
+func (s *BinanceServer) ProviderOptions() domain.ProviderOptions {
+	return domain.ProviderOptions{
+		RestURL:    s.RestURL(),
+		StreamURLs: []string{s.StreamURL()},
+		SyncURL:    s.WSAPIURL(),
+	}
+}
+
+func (s *BinanceServer) Close() {
+	s.Exchange.Close()
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+type binanceStreamRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	Id     int      `json:"id"`
+}
+
+// serveStream acks the SUBSCRIBE and UNSUBSCRIBE requests and sends the diffs of the <symbol>@depth streams.
+func (s *BinanceServer) serveStream(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn, untrack := s.track(ws, true)
+	defer untrack()
+
+	unsubscribe := make(map[string]func())
+	defer func() {
+		for _, fn := range unsubscribe {
+			fn()
+		}
+	}()
+
+	for {
+		req := &binanceStreamRequest{}
+		if err := conn.ReadJSON(req); err != nil {
+			return
+		}
+
+		for _, topic := range req.Params {
+			switch req.Method {
+			case "SUBSCRIBE":
+				if _, ok := unsubscribe[topic]; ok {
+					continue
+				}
+				if fn, ok := s.subscribe(depthStreamSymbol(topic), s.depthSender(conn, topic)); ok {
+					unsubscribe[topic] = fn
+				}
+			case "UNSUBSCRIBE":
+				if fn, ok := unsubscribe[topic]; ok {
+					fn()
+					delete(unsubscribe, topic)
+				}
+			}
+		}
+
+		conn.WriteJSON(map[string]interface{}{"result": nil, "id": req.Id})
+	}
+}
+
+func (s *BinanceServer) depthSender(conn *wsConn, topic string) subscriber {
+	return func(symbol string, diff *Diff) {
+		err := conn.WriteJSON(map[string]interface{}{
+			"stream": topic,
+			"data": map[string]interface{}{
+				"e": "depthUpdate",
+				"E": diff.Time.UnixMilli(),
+				"s": symbol,
+				"U": diff.FirstSequence,
+				"u": diff.LastSequence,
+				"b": priceLevels(diff.Bids),
+				"a": priceLevels(diff.Asks),
+			},
+		})
+		if err != nil {
+			logger.Printf("failed to send the diff of %s: %s", topic, err)
+		}
+	}
+}
+
+type binanceWSAPIRequest struct {
+	Id     int                    `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// serveWSAPI answers the depth requests of the websocket api.
+func (s *BinanceServer) serveWSAPI(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn, untrack := s.track(ws, false)
+	defer untrack()
+
+	for {
+		req := &binanceWSAPIRequest{}
+		if err := conn.ReadJSON(req); err != nil {
+			return
+		}
+
+		if req.Method != "depth" {
+			conn.WriteJSON(binanceWSAPIError(req.Id, -1100, fmt.Sprintf("Unknown method %s.", req.Method)))
+			continue
+		}
+
+		symbol := fmt.Sprint(req.Params["symbol"])
+		limit, _ := strconv.Atoi(fmt.Sprint(req.Params["limit"]))
+		sequence, bids, asks, ok := s.Snapshot(symbol, limit)
+		if !ok {
+			conn.WriteJSON(binanceWSAPIError(req.Id, binanceInvalidSymbol, "Invalid symbol."))
+			continue
+		}
+
+		conn.WriteJSON(map[string]interface{}{
+			"id":     req.Id,
+			"status": http.StatusOK,
+			"result": map[string]interface{}{"lastUpdateId": sequence, "bids": bids, "asks": asks},
+		})
+	}
+}
+
+func (s *BinanceServer) serveDepth(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	sequence, bids, asks, ok := s.Snapshot(r.URL.Query().Get("symbol"), limit)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": binanceInvalidSymbol, "msg": "Invalid symbol."})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"lastUpdateId": sequence, "bids": bids, "asks": asks})
+}
+
+func (s *BinanceServer) serveExchangeInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	symbols := make([]map[string]string, 0, len(s.symbols))
+	for _, symbol := range s.symbols {
+		m := s.markets[symbol]
+		symbols = append(symbols, map[string]string{
+			"symbol":     symbol,
+			"status":     "TRADING",
+			"baseAsset":  strings.ToUpper(m.base),
+			"quoteAsset": strings.ToUpper(m.quote),
+		})
+	}
+	s.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"symbols": symbols})
+}
+
+func binanceSymbol(base, quote string) string {
+	return strings.ToUpper(base + quote)
+}
+
+// depthStreamSymbol returns the symbol of the diff depth stream, e.g. BTCUSDT of btcusdt@depth@100ms.
+// The other streams have no symbol.
+func depthStreamSymbol(topic string) string {
+	parts := strings.Split(topic, "@")
+	if len(parts) < 2 || parts[1] != "depth" {
+		return ""
+	}
+	return strings.ToUpper(parts[0])
+}
+
+func binanceWSAPIError(id, code int, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     id,
+		"status": http.StatusBadRequest,
+		"error":  map[string]interface{}{"code": code, "msg": msg},
+	}
+}
+
+// priceLevels strips the sequences of the changes, binance sends the levels in the form of [price, size].
+func priceLevels(changes [][]string) [][]string {
+	result := make([][]string, len(changes))
+	for i, change := range changes {
+		result[i] = change[:2]
+	}
+	return result
+}
+
+func wsURL(httpURL string) string {
+	return "ws" + strings.TrimPrefix(httpURL, "http")
+}
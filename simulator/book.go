@@ -0,0 +1,197 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BookConfig is the initial state of a simulated book.
+type BookConfig struct {
+	// Seed of the random walk, the books of the same config step identically.
+	Seed int64
+	// MidPrice is the price the walk starts from.
+	MidPrice float64
+	// Precision is the number of the decimals of the prices, the tick size is 10^-Precision.
+	Precision int
+	// Depth is the number of the levels of each side.
+	Depth int
+	// Sequence is the sequence number of the initial book.
+	Sequence int64
+}
+
+// A Diff is the changes of a step. Each change has its own sequence number, the diff covers
+// the sequences from FirstSequence to LastSequence. The levels are in the form of [price, size, sequence],
+// the removed levels have the zero size.
+type Diff struct {
+	FirstSequence int64
+	LastSequence  int64
+	Bids          [][]string
+	Asks          [][]string
+	Time          time.Time
+}
+
+// Book is a random-walk order book. The bids and the asks occupy the ticks right below and above the mid price,
+// so the book never crosses. On every step the mid price moves by a tick at most and a few sizes change.
+type Book struct {
+	mu        sync.Mutex
+	rand      *rand.Rand
+	precision int
+	tick      float64
+	depth     int
+	// The mid price in ticks, it is never quoted.
+	mid      int64
+	bids     map[int64]int64
+	asks     map[int64]int64
+	sequence int64
+}
+
+func NewBook(conf BookConfig) *Book {
+	if conf.Depth <= 0 {
+		conf.Depth = 50
+	}
+	if conf.MidPrice <= 0 {
+		conf.MidPrice = 100
+	}
+
+	b := &Book{
+		rand:      rand.New(rand.NewSource(conf.Seed)),
+		precision: conf.Precision,
+		tick:      math.Pow10(-conf.Precision),
+		depth:     conf.Depth,
+		bids:      make(map[int64]int64, conf.Depth),
+		asks:      make(map[int64]int64, conf.Depth),
+		sequence:  conf.Sequence,
+	}
+	b.mid = int64(math.Round(conf.MidPrice / b.tick))
+	if b.mid <= int64(conf.Depth) {
+		// the walk stays above the zero price
+		b.mid = int64(conf.Depth) + 1
+	}
+
+	for i := int64(1); i <= int64(conf.Depth); i++ {
+		b.bids[b.mid-i] = b.randomSize()
+		b.asks[b.mid+i] = b.randomSize()
+	}
+
+	return b
+}
+
+// Sequence returns the sequence number of the last change.
+func (b *Book) Sequence() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.sequence
+}
+
+// Step moves the mid price and changes the sizes of the random levels.
+func (b *Book) Step() *Diff {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	diff := &Diff{FirstSequence: b.sequence + 1, Time: time.Now()}
+
+	switch b.rand.Intn(3) {
+	case 0:
+		b.moveUp(diff)
+	case 1:
+		b.moveDown(diff)
+	}
+
+	for i := b.rand.Intn(3) + 1; i > 0; i-- {
+		offset := int64(b.rand.Intn(b.depth)) + 1
+		size := b.randomSize()
+		if b.rand.Intn(2) == 0 {
+			b.setBid(diff, b.mid-offset, size)
+		} else {
+			b.setAsk(diff, b.mid+offset, size)
+		}
+	}
+
+	diff.LastSequence = b.sequence
+	return diff
+}
+
+// Snapshot returns the best levels of each side and the sequence number of the book.
+func (b *Book) Snapshot(limit int) (sequence int64, bids, asks [][]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.sequence, b.levels(b.bids, limit, true), b.levels(b.asks, limit, false)
+}
+
+// moveUp makes the best ask the mid price, the bid side follows it.
+func (b *Book) moveUp(diff *Diff) {
+	b.setAsk(diff, b.mid+1, 0)
+	b.setAsk(diff, b.mid+1+int64(b.depth), b.randomSize())
+	b.setBid(diff, b.mid-int64(b.depth), 0)
+	b.setBid(diff, b.mid, b.randomSize())
+	b.mid++
+}
+
+// moveDown makes the best bid the mid price, the ask side follows it.
+func (b *Book) moveDown(diff *Diff) {
+	b.setBid(diff, b.mid-1, 0)
+	b.setBid(diff, b.mid-1-int64(b.depth), b.randomSize())
+	b.setAsk(diff, b.mid+int64(b.depth), 0)
+	b.setAsk(diff, b.mid, b.randomSize())
+	b.mid--
+}
+
+func (b *Book) setBid(diff *Diff, price, size int64) {
+	b.set(b.bids, price, size)
+	diff.Bids = append(diff.Bids, append(b.level(price, size), strconv.FormatInt(b.sequence, 10)))
+}
+
+func (b *Book) setAsk(diff *Diff, price, size int64) {
+	b.set(b.asks, price, size)
+	diff.Asks = append(diff.Asks, append(b.level(price, size), strconv.FormatInt(b.sequence, 10)))
+}
+
+func (b *Book) set(side map[int64]int64, price, size int64) {
+	b.sequence++
+	if size == 0 {
+		delete(side, price)
+		return
+	}
+	side[price] = size
+}
+
+func (b *Book) levels(side map[int64]int64, limit int, desc bool) [][]string {
+	prices := make([]int64, 0, len(side))
+	for price := range side {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		if desc {
+			return prices[i] > prices[j]
+		}
+		return prices[i] < prices[j]
+	})
+	if limit > 0 && len(prices) > limit {
+		prices = prices[:limit]
+	}
+
+	result := make([][]string, len(prices))
+	for i, price := range prices {
+		result[i] = b.level(price, side[price])
+	}
+	return result
+}
+
+func (b *Book) level(price, size int64) []string {
+	return []string{
+		strconv.FormatFloat(float64(price)*b.tick, 'f', b.precision, 64),
+		fmt.Sprintf("%d.%03d", size/1000, size%1000),
+	}
+}
+
+// randomSize returns the size in thousandths, it is never zero.
+func (b *Book) randomSize() int64 {
+	return b.rand.Int63n(10000) + 1
+}
@@ -0,0 +1,72 @@
+package simulator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBook_DiffsReproduceSnapshot(t *testing.T) {
+	book := NewBook(BookConfig{Seed: 1, MidPrice: 30000, Precision: 2, Depth: 20, Sequence: 1000})
+
+	sequence, bids, asks := book.Snapshot(0)
+	assert.Equal(t, int64(1000), sequence)
+	assert.Len(t, bids, 20)
+	assert.Len(t, asks, 20)
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	local := domain.NewOrderBook("simulator", symbol, &domain.OrderBookSnapshot{LastUpdateId: sequence, Bids: bids, Asks: asks})
+
+	for i := 0; i < 500; i++ {
+		diff := book.Step()
+		assert.Equal(t, sequence+1, diff.FirstSequence, "the diffs should be contiguous")
+		assert.Equal(t, diff.LastSequence-diff.FirstSequence+1, int64(len(diff.Bids)+len(diff.Asks)))
+		for _, change := range append(diff.Bids, diff.Asks...) {
+			seq, _ := strconv.ParseInt(change[2], 10, 64)
+			assert.True(t, seq >= diff.FirstSequence && seq <= diff.LastSequence)
+		}
+		sequence = diff.LastSequence
+
+		local.ApplyUpdate(domain.NewOrderBookUpdate(priceLevels(diff.Bids), priceLevels(diff.Asks), diff.FirstSequence, diff.LastSequence, symbol))
+	}
+
+	expected, bids, asks := book.Snapshot(0)
+	actual := local.TakeSnapshot(0)
+	assert.Equal(t, expected, actual.LastUpdateId)
+	assert.Equal(t, bids, actual.Bids)
+	assert.Equal(t, asks, actual.Asks)
+}
+
+func TestBook_NeverCrosses(t *testing.T) {
+	book := NewBook(BookConfig{Seed: 2, MidPrice: 0.5, Precision: 4, Depth: 5})
+
+	for i := 0; i < 1000; i++ {
+		book.Step()
+
+		_, bids, asks := book.Snapshot(1)
+		bid, _ := strconv.ParseFloat(bids[0][0], 64)
+		ask, _ := strconv.ParseFloat(asks[0][0], 64)
+		assert.Less(t, bid, ask)
+	}
+}
+
+func TestBook_SameSeedSameWalk(t *testing.T) {
+	conf := BookConfig{Seed: 3, MidPrice: 100, Precision: 1}
+	a, b := NewBook(conf), NewBook(conf)
+
+	for i := 0; i < 100; i++ {
+		da, db := a.Step(), b.Step()
+		assert.Equal(t, da.Bids, db.Bids)
+		assert.Equal(t, da.Asks, db.Asks)
+	}
+}
+
+func TestBook_SnapshotLimit(t *testing.T) {
+	book := NewBook(BookConfig{Depth: 50})
+
+	_, bids, asks := book.Snapshot(20)
+	assert.Len(t, bids, 20)
+	assert.Len(t, asks, 20)
+}
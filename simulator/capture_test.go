@@ -0,0 +1,86 @@
+package simulator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/infrastructure/capture"
+	"github.com/spooky-finn/cryptobridge/provider"
+	"github.com/spooky-finn/cryptobridge/provider/binance"
+	"github.com/spooky-finn/cryptobridge/provider/kucoin"
+	"github.com/spooky-finn/cryptobridge/simulator"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCaptureReplay captures the live order book through the faults and replays the capture,
+// the replayed order book ends up identical to the live one.
+func testCaptureReplay(t *testing.T, name string, v venue, opts domain.ProviderOptions) {
+	recorder, err := capture.NewRecorder(t.TempDir(), name)
+	if !assert.NoError(t, err) {
+		return
+	}
+	opts.Recorder = recorder
+
+	live, err := provider.NewConnectionManager([]string{name}, map[string]domain.ProviderOptions{name: opts})
+	assert.NoError(t, err)
+	live.Init()
+
+	ob := getOrderBook(t, live, name, v)
+	tick(v, 10)
+	v.Inject(simulator.FaultDuplicate)
+	tick(v, 5)
+
+	connects := v.Connects()
+	v.Inject(simulator.FaultDisconnect)
+	tick(v, 1)
+	assert.Eventually(t, func() bool {
+		return v.Connects() > connects && v.Subscribers(v.symbol) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the feed should be resubscribed")
+
+	tick(v, 10)
+	assertInSync(t, v, ob)
+	expected := ob.TakeSnapshot(0)
+
+	live.Close()
+	assert.NoError(t, recorder.Close())
+
+	replay, err := provider.NewConnectionManager([]string{name}, map[string]domain.ProviderOptions{
+		name: {ReplayFiles: []string{recorder.Path()}},
+	})
+	assert.NoError(t, err)
+	replay.Init()
+	defer replay.Close()
+
+	streamAPI, _ := replay.StreamAPI(name)
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	result := streamAPI.GetOrderBook(symbol)
+	if !assert.NoError(t, result.Err) {
+		return
+	}
+
+	p, _ := replay.Provider(name)
+	p.StreamClient.(*capture.Player).Wait()
+
+	assert.Eventually(t, func() bool {
+		return result.OrderBook.TakeSnapshot(0).LastUpdateId == expected.LastUpdateId
+	}, 3*time.Second, 10*time.Millisecond, "the replayed order book should reach the sequence %d", expected.LastUpdateId)
+
+	actual := result.OrderBook.TakeSnapshot(0)
+	assert.Equal(t, expected.Bids, actual.Bids)
+	assert.Equal(t, expected.Asks, actual.Asks)
+}
+
+func TestBinanceServer_CaptureReplay(t *testing.T) {
+	server := simulator.NewBinanceServer(simulator.Config{Markets: []simulator.Market{btcusdt}})
+	defer server.Close()
+
+	testCaptureReplay(t, binance.ProviderName, venue{server.Exchange, "BTCUSDT"}, server.ProviderOptions())
+}
+
+func TestKucoinServer_CaptureReplay(t *testing.T) {
+	server := simulator.NewKucoinServer(simulator.Config{Markets: []simulator.Market{btcusdt}})
+	defer server.Close()
+
+	testCaptureReplay(t, kucoin.ProviderName, venue{server.Exchange, "BTC-USDT"}, server.ProviderOptions())
+}
@@ -0,0 +1,330 @@
+package simulator
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var logger = log.New(log.Writer(), "[simulator] ", log.LstdFlags)
+
+// A Fault is a scripted deviation of the feed from the protocol. The faults are applied to the ticks
+// in the order of the injection, one fault per tick.
+type Fault int
+
+const (
+	// FaultGap drops the diffs of the tick, the next diffs are out of sequence.
+	FaultGap Fault = iota + 1
+	// FaultDuplicate sends the diffs of the tick twice.
+	FaultDuplicate
+	// FaultDisconnect closes the feed connections instead of sending the diffs of the tick.
+	FaultDisconnect
+	// FaultSlowPong delays the next pong by the SlowPongDelay. The diffs of the tick are sent as is.
+	FaultSlowPong
+)
+
+// A Market is a simulated market, the venues build the native symbols of the assets.
+type Market struct {
+	Base  string
+	Quote string
+	Book  BookConfig
+}
+
+// Config configures a simulated exchange.
+type Config struct {
+	Markets []Market
+	// Interval of the ticks. The books step only on Tick if it is not set.
+	Interval time.Duration
+	// PingInterval and PingTimeout are the heartbeat settings advertised to the clients.
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+	// SlowPongDelay is the delay of the pong of FaultSlowPong.
+	SlowPongDelay time.Duration
+}
+
+type subscriber func(symbol string, diff *Diff)
+
+type market struct {
+	base        string
+	quote       string
+	book        *Book
+	subscribers map[int64]subscriber
+}
+
+// Exchange steps the books of the markets on every tick and sends the diffs to the subscribers.
+type Exchange struct {
+	conf Config
+
+	mu      sync.Mutex
+	markets map[string]*market
+	// The native symbols in the order of the ticks.
+	symbols   []string
+	nextSubId int64
+	faults    []Fault
+	slowPongs int
+	// The websocket connections, the value is set for the feed ones.
+	conns      map[*wsConn]bool
+	connects   int
+	tickCount  int64
+	done       chan struct{}
+	closeOnce  sync.Once
+	tickerDone sync.WaitGroup
+}
+
+// newExchange creates the books of the markets keyed by the native symbols of the venue.
+func newExchange(conf Config, nativeSymbol func(base, quote string) string) *Exchange {
+	e := &Exchange{
+		conf:    conf,
+		markets: make(map[string]*market, len(conf.Markets)),
+		conns:   make(map[*wsConn]bool),
+		done:    make(chan struct{}),
+	}
+
+	for _, m := range conf.Markets {
+		symbol := nativeSymbol(m.Base, m.Quote)
+		e.markets[symbol] = &market{
+			base:        m.Base,
+			quote:       m.Quote,
+			book:        NewBook(m.Book),
+			subscribers: make(map[int64]subscriber),
+		}
+		e.symbols = append(e.symbols, symbol)
+	}
+	sort.Strings(e.symbols)
+
+	if conf.Interval > 0 {
+		e.tickerDone.Add(1)
+		go e.run()
+	}
+
+	return e
+}
+
+// Tick steps the books and sends the diffs to the subscribers, the next fault is applied to the tick.
+func (e *Exchange) Tick() {
+	e.mu.Lock()
+	e.tickCount++
+
+	var fault Fault
+	if len(e.faults) > 0 {
+		fault = e.faults[0]
+		e.faults = e.faults[1:]
+	}
+
+	type delivery struct {
+		symbol      string
+		diff        *Diff
+		subscribers []subscriber
+	}
+	deliveries := make([]delivery, 0, len(e.symbols))
+	for _, symbol := range e.symbols {
+		m := e.markets[symbol]
+		d := delivery{symbol: symbol, diff: m.book.Step()}
+
+		ids := make([]int64, 0, len(m.subscribers))
+		for id := range m.subscribers {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for _, id := range ids {
+			d.subscribers = append(d.subscribers, m.subscribers[id])
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	var conns []*wsConn
+	switch fault {
+	case FaultDisconnect:
+		for conn, feed := range e.conns {
+			if feed {
+				conns = append(conns, conn)
+			}
+		}
+	case FaultSlowPong:
+		e.slowPongs++
+	}
+	e.mu.Unlock()
+
+	switch fault {
+	case FaultGap:
+		return
+	case FaultDisconnect:
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return
+	}
+
+	sends := 1
+	if fault == FaultDuplicate {
+		sends = 2
+	}
+	for _, d := range deliveries {
+		for _, fn := range d.subscribers {
+			for i := 0; i < sends; i++ {
+				fn(d.symbol, d.diff)
+			}
+		}
+	}
+}
+
+// Inject queues the faults for the next ticks.
+func (e *Exchange) Inject(faults ...Fault) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.faults = append(e.faults, faults...)
+}
+
+// Snapshot returns the best levels of the market by the native symbol.
+func (e *Exchange) Snapshot(symbol string, limit int) (sequence int64, bids, asks [][]string, ok bool) {
+	e.mu.Lock()
+	m, ok := e.markets[symbol]
+	e.mu.Unlock()
+
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	sequence, bids, asks = m.book.Snapshot(limit)
+	return sequence, bids, asks, true
+}
+
+// Ticks returns the number of the ticks since the start.
+func (e *Exchange) Ticks() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.tickCount
+}
+
+// Connects returns the number of the feed connections accepted since the start.
+func (e *Exchange) Connects() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.connects
+}
+
+// Subscribers returns the number of the feed subscriptions of the market.
+func (e *Exchange) Subscribers(symbol string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if m, ok := e.markets[symbol]; ok {
+		return len(m.subscribers)
+	}
+	return 0
+}
+
+// Close stops the ticks and closes the websocket connections.
+func (e *Exchange) Close() {
+	e.closeOnce.Do(func() { close(e.done) })
+	e.tickerDone.Wait()
+
+	e.mu.Lock()
+	conns := make([]*wsConn, 0, len(e.conns))
+	for conn := range e.conns {
+		conns = append(conns, conn)
+	}
+	e.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+func (e *Exchange) run() {
+	defer e.tickerDone.Done()
+
+	ticker := time.NewTicker(e.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.Tick()
+		}
+	}
+}
+
+// subscribe adds the subscriber of the diffs of the market. The returned function removes it.
+func (e *Exchange) subscribe(symbol string, fn subscriber) (func(), bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.markets[symbol]
+	if !ok {
+		return nil, false
+	}
+
+	e.nextSubId++
+	id := e.nextSubId
+	m.subscribers[id] = fn
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		delete(m.subscribers, id)
+	}, true
+}
+
+// takeSlowPong reports whether the pong is delayed by FaultSlowPong.
+func (e *Exchange) takeSlowPong() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.slowPongs == 0 {
+		return false
+	}
+	e.slowPongs--
+	return true
+}
+
+// track registers the connection until the returned function is called. The feed connections
+// are closed by FaultDisconnect, all the connections are closed with the exchange.
+func (e *Exchange) track(ws *websocket.Conn, feed bool) (*wsConn, func()) {
+	conn := &wsConn{conn: ws}
+
+	e.mu.Lock()
+	e.conns[conn] = feed
+	if feed {
+		e.connects++
+	}
+	e.mu.Unlock()
+
+	return conn, func() {
+		e.mu.Lock()
+		delete(e.conns, conn)
+		e.mu.Unlock()
+
+		conn.Close()
+	}
+}
+
+// wsConn serializes the writes of the ticks and the request handlers.
+type wsConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *wsConn) ReadJSON(v interface{}) error {
+	return c.conn.ReadJSON(v)
+}
+
+func (c *wsConn) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn.WriteJSON(v)
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
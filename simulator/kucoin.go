@@ -0,0 +1,279 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spooky-finn/cryptobridge/domain"
+)
+
+const (
+	kucoinToken = "simulator-token"
+	// The code of the successful responses of the rest api.
+	kucoinSuccess       = "200000"
+	kucoinLevel2Topic   = "/market/level2:"
+	kucoinLevel2Subject = "trade.l2update"
+)
+
+// The depths of the part order books.
+var kucoinPartBooks = map[string]int{"level2_20": 20, "level2_100": 100}
+
+// KucoinServer serves the kucoin bullet-public, the level2 snapshots and the symbols of the rest api,
+// and the websocket of the instance server at /endpoint.
+type KucoinServer struct {
+	*Exchange
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+}
+
+// NewKucoinServer starts the server of the markets, the symbols are separated by a dash, e.g. BTC-USDT.
+func NewKucoinServer(conf Config) *KucoinServer {
+	if conf.PingInterval <= 0 {
+		conf.PingInterval = 18 * time.Second
+	}
+	if conf.PingTimeout <= 0 {
+		conf.PingTimeout = 10 * time.Second
+	}
+
+	s := &KucoinServer{
+		Exchange: newExchange(conf, kucoinSymbol),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/bullet-public", s.serveBullet)
+	mux.HandleFunc("/api/v1/market/orderbook/", s.servePartBook)
+	mux.HandleFunc("/api/v3/market/orderbook/level2", s.serveFullBook)
+	mux.HandleFunc("/api/v2/symbols", s.serveSymbols)
+	mux.HandleFunc("/endpoint", s.serveWebsocket)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// RestURL is the base url of the rest api.
+func (s *KucoinServer) RestURL() string {
+	return s.server.URL
+}
+
+// WebsocketURL is the endpoint of the instance server.
+func (s *KucoinServer) WebsocketURL() string {
+	return wsURL(s.server.URL) + "/endpoint"
+}
+
+// ProviderOptions points the kucoin provider to the server, the instance server is advertised by the bullet.
+func (s *KucoinServer) ProviderOptions() domain.ProviderOptions {
+	return domain.ProviderOptions{RestURL: s.RestURL()}
+}
+
+func (s *KucoinServer) Close() {
+	s.Exchange.Close()
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+func (s *KucoinServer) serveBullet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeKucoinData(w, map[string]interface{}{
+		"token": kucoinToken,
+		"instanceServers": []map[string]interface{}{{
+			"endpoint":     s.WebsocketURL(),
+			"encrypt":      false,
+			"protocol":     "websocket",
+			"pingInterval": s.conf.PingInterval.Milliseconds(),
+			"pingTimeout":  s.conf.PingTimeout.Milliseconds(),
+		}},
+	})
+}
+
+func (s *KucoinServer) servePartBook(w http.ResponseWriter, r *http.Request) {
+	depth, ok := kucoinPartBooks[strings.TrimPrefix(r.URL.Path, "/api/v1/market/orderbook/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.writeBook(w, r.URL.Query().Get("symbol"), depth)
+}
+
+func (s *KucoinServer) serveFullBook(w http.ResponseWriter, r *http.Request) {
+	s.writeBook(w, r.URL.Query().Get("symbol"), 0)
+}
+
+func (s *KucoinServer) writeBook(w http.ResponseWriter, symbol string, limit int) {
+	sequence, bids, asks, ok := s.Snapshot(symbol, limit)
+	if !ok {
+		writeKucoinError(w, "400100", "symbol is not found")
+		return
+	}
+
+	writeKucoinData(w, map[string]interface{}{
+		"sequence": strconv.FormatInt(sequence, 10),
+		"time":     time.Now().UnixMilli(),
+		"bids":     bids,
+		"asks":     asks,
+	})
+}
+
+func (s *KucoinServer) serveSymbols(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	symbols := make([]map[string]interface{}, 0, len(s.symbols))
+	for _, symbol := range s.symbols {
+		m := s.markets[symbol]
+		symbols = append(symbols, map[string]interface{}{
+			"symbol":        symbol,
+			"baseCurrency":  strings.ToUpper(m.base),
+			"quoteCurrency": strings.ToUpper(m.quote),
+			"enableTrading": true,
+		})
+	}
+	s.mu.Unlock()
+
+	writeKucoinData(w, symbols)
+}
+
+type kucoinRequest struct {
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	Topic    string `json:"topic"`
+	Response bool   `json:"response"`
+}
+
+// serveWebsocket sends the welcome message, answers the pings and acks the subscriptions.
+// The level2 topics carry the diffs of the comma separated symbols.
+func (s *KucoinServer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("token") != kucoinToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn, untrack := s.track(ws, true)
+	defer untrack()
+
+	unsubscribe := make(map[string]func())
+	defer func() {
+		for _, fn := range unsubscribe {
+			fn()
+		}
+	}()
+
+	connectId := strconv.FormatInt(time.Now().UnixNano(), 10)
+	conn.WriteJSON(map[string]string{"id": connectId, "type": "welcome"})
+
+	for {
+		req := &kucoinRequest{}
+		if err := conn.ReadJSON(req); err != nil {
+			return
+		}
+
+		switch req.Type {
+		case "ping":
+			s.pong(conn, req.Id)
+			continue
+		case "subscribe", "unsubscribe":
+		default:
+			conn.WriteJSON(kucoinWebsocketError(req.Id, 400, fmt.Sprintf("type %s is not supported", req.Type)))
+			continue
+		}
+
+		symbols, ok := kucoinLevel2Symbols(req.Topic)
+		if !ok {
+			conn.WriteJSON(kucoinWebsocketError(req.Id, 404, fmt.Sprintf("topic %s is not found", req.Topic)))
+			continue
+		}
+
+		for _, symbol := range symbols {
+			topic := kucoinLevel2Topic + symbol
+			fn, subscribed := unsubscribe[topic]
+
+			if req.Type == "unsubscribe" {
+				if subscribed {
+					fn()
+					delete(unsubscribe, topic)
+				}
+				continue
+			}
+
+			if subscribed {
+				continue
+			}
+			if fn, ok := s.subscribe(symbol, s.level2Sender(conn, topic)); ok {
+				unsubscribe[topic] = fn
+			}
+		}
+
+		if req.Response {
+			conn.WriteJSON(map[string]string{"id": req.Id, "type": "ack"})
+		}
+	}
+}
+
+// pong answers the ping, the pong is delayed by FaultSlowPong.
+func (s *KucoinServer) pong(conn *wsConn, id string) {
+	msg := map[string]string{"id": id, "type": "pong"}
+	if !s.takeSlowPong() {
+		conn.WriteJSON(msg)
+		return
+	}
+
+	time.AfterFunc(s.conf.SlowPongDelay, func() {
+		conn.WriteJSON(msg)
+	})
+}
+
+func (s *KucoinServer) level2Sender(conn *wsConn, topic string) subscriber {
+	return func(symbol string, diff *Diff) {
+		err := conn.WriteJSON(map[string]interface{}{
+			"type":    "message",
+			"topic":   topic,
+			"subject": kucoinLevel2Subject,
+			"data": map[string]interface{}{
+				"changes":       map[string][][]string{"asks": diff.Asks, "bids": diff.Bids},
+				"sequenceStart": diff.FirstSequence,
+				"sequenceEnd":   diff.LastSequence,
+				"symbol":        symbol,
+				"time":          diff.Time.UnixMilli(),
+			},
+		})
+		if err != nil {
+			logger.Printf("failed to send the diff of %s: %s", topic, err)
+		}
+	}
+}
+
+func kucoinSymbol(base, quote string) string {
+	return strings.ToUpper(base) + "-" + strings.ToUpper(quote)
+}
+
+// kucoinLevel2Symbols returns the symbols of the level2 topic, e.g. BTC-USDT and ETH-USDT of /market/level2:BTC-USDT,ETH-USDT.
+func kucoinLevel2Symbols(topic string) ([]string, bool) {
+	if !strings.HasPrefix(topic, kucoinLevel2Topic) {
+		return nil, false
+	}
+	return strings.Split(strings.TrimPrefix(topic, kucoinLevel2Topic), ","), true
+}
+
+func kucoinWebsocketError(id string, code int, data string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": "error", "code": code, "data": data}
+}
+
+func writeKucoinData(w http.ResponseWriter, data interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": kucoinSuccess, "data": data})
+}
+
+func writeKucoinError(w http.ResponseWriter, code, msg string) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "msg": msg})
+}
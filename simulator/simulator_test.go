@@ -0,0 +1,164 @@
+package simulator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spooky-finn/cryptobridge/domain"
+	"github.com/spooky-finn/cryptobridge/provider"
+	"github.com/spooky-finn/cryptobridge/provider/binance"
+	"github.com/spooky-finn/cryptobridge/provider/kucoin"
+	"github.com/spooky-finn/cryptobridge/simulator"
+	"github.com/stretchr/testify/assert"
+)
+
+var btcusdt = simulator.Market{Base: "btc", Quote: "usdt", Book: simulator.BookConfig{Seed: 1, MidPrice: 30000, Precision: 2, Sequence: 1000}}
+
+// venue is a simulated exchange with the native symbol of btc_usdt.
+type venue struct {
+	*simulator.Exchange
+	symbol string
+}
+
+func newConnectionManager(t *testing.T, name string, opts domain.ProviderOptions) *provider.ConnectionManager {
+	cm, err := provider.NewConnectionManager([]string{name}, map[string]domain.ProviderOptions{name: opts})
+	assert.NoError(t, err)
+	cm.Init()
+	t.Cleanup(cm.Close)
+
+	return cm
+}
+
+// getOrderBook builds the local order book of btc_usdt. The maintainer waits for the first update,
+// so the book ticks until the order book is created.
+func getOrderBook(t *testing.T, cm *provider.ConnectionManager, name string, v venue) *domain.OrderBook {
+	streamAPI, err := cm.StreamAPI(name)
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	ticked := make(chan struct{})
+	go func() {
+		defer close(ticked)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+				v.Tick()
+			}
+		}
+	}()
+
+	symbol, _ := domain.NewMarketSymbol("btc", "usdt")
+	result := streamAPI.GetOrderBook(symbol)
+	close(done)
+	<-ticked
+	if !assert.NoError(t, result.Err) {
+		t.FailNow()
+	}
+
+	return result.OrderBook
+}
+
+// assertInSync waits for the local order book to catch up with the simulated one and compares the levels.
+func assertInSync(t *testing.T, v venue, ob *domain.OrderBook) {
+	sequence, bids, asks, _ := v.Snapshot(v.symbol, 0)
+
+	assert.Eventually(t, func() bool {
+		return ob.TakeSnapshot(0).LastUpdateId == sequence
+	}, 3*time.Second, 10*time.Millisecond, "the local order book should reach the sequence %d", sequence)
+
+	snapshot := ob.TakeSnapshot(0)
+	assert.Equal(t, bids, snapshot.Bids)
+	assert.Equal(t, asks, snapshot.Asks)
+}
+
+func tick(v venue, n int) {
+	for i := 0; i < n; i++ {
+		v.Tick()
+	}
+}
+
+func testVenue(t *testing.T, name string, v venue, opts domain.ProviderOptions) {
+	cm := newConnectionManager(t, name, opts)
+
+	btc, _ := domain.NewMarketSymbol("btc", "usdt")
+	eth, _ := domain.NewMarketSymbol("eth", "usdt")
+	assert.NoError(t, cm.ValidateSymbol(name, btc))
+	assert.ErrorIs(t, cm.ValidateSymbol(name, eth), domain.ErrUnknownMarket)
+
+	ob := getOrderBook(t, cm, name, v)
+	assertInSync(t, v, ob)
+
+	tick(v, 20)
+	assertInSync(t, v, ob)
+
+	t.Run("duplicate", func(t *testing.T) {
+		v.Inject(simulator.FaultDuplicate)
+		tick(v, 5)
+		assertInSync(t, v, ob)
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		connects := v.Connects()
+		v.Inject(simulator.FaultDisconnect)
+		tick(v, 1)
+
+		assert.Eventually(t, func() bool {
+			return v.Connects() > connects && v.Subscribers(v.symbol) == 1
+		}, 5*time.Second, 10*time.Millisecond, "the feed should be resubscribed")
+
+		tick(v, 5)
+		assertInSync(t, v, ob)
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		tick(v, 1)
+		assertInSync(t, v, ob)
+
+		// the updates after the gap are out of sequence, the order book is rebuilt from a new snapshot
+		v.Inject(simulator.FaultGap)
+		tick(v, 3)
+		assertInSync(t, v, ob)
+		assert.False(t, ob.IsOutdated())
+	})
+}
+
+func TestBinanceServer(t *testing.T) {
+	server := simulator.NewBinanceServer(simulator.Config{Markets: []simulator.Market{btcusdt}})
+	defer server.Close()
+
+	testVenue(t, binance.ProviderName, venue{server.Exchange, "BTCUSDT"}, server.ProviderOptions())
+}
+
+func TestKucoinServer(t *testing.T) {
+	server := simulator.NewKucoinServer(simulator.Config{Markets: []simulator.Market{btcusdt}})
+	defer server.Close()
+
+	testVenue(t, kucoin.ProviderName, venue{server.Exchange, "BTC-USDT"}, server.ProviderOptions())
+}
+
+func TestKucoinServer_SlowPong(t *testing.T) {
+	server := simulator.NewKucoinServer(simulator.Config{
+		Markets:       []simulator.Market{btcusdt},
+		PingInterval:  50 * time.Millisecond,
+		PingTimeout:   100 * time.Millisecond,
+		SlowPongDelay: time.Second,
+	})
+	defer server.Close()
+	v := venue{server.Exchange, "BTC-USDT"}
+
+	cm := newConnectionManager(t, kucoin.ProviderName, server.ProviderOptions())
+	ob := getOrderBook(t, cm, kucoin.ProviderName, v)
+
+	connects := v.Connects()
+	v.Inject(simulator.FaultSlowPong)
+	tick(v, 1)
+
+	assert.Eventually(t, func() bool {
+		return v.Connects() > connects && v.Subscribers(v.symbol) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the client should reconnect after the pong timeout")
+
+	tick(v, 5)
+	assertInSync(t, v, ob)
+}
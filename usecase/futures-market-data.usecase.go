@@ -0,0 +1,52 @@
+package usecase
+
+import "github.com/spooky-finn/cryptobridge/domain"
+
+// FuturesMarketDataUseCase serves the market data of the perpetual futures: the mark price and the funding rate.
+type FuturesMarketDataUseCase struct {
+	connManager domain.ConnManager
+}
+
+func NewFuturesMarketDataUseCase(connManager domain.ConnManager) *FuturesMarketDataUseCase {
+	return &FuturesMarketDataUseCase{
+		connManager: connManager,
+	}
+}
+
+func (f *FuturesMarketDataUseCase) GetMarkPrice(provider string, symbol *domain.MarketSymbol) (*domain.MarkPrice, error) {
+	futuresAPI, err := f.connManager.FuturesAPI(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.connManager.ValidateSymbol(provider, symbol); err != nil {
+		return nil, err
+	}
+
+	markPrice, err := futuresAPI.MarkPrice(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	markPrice.NativeSymbol = nativeSymbol(f.connManager, provider, symbol)
+	return markPrice, nil
+}
+
+func (f *FuturesMarketDataUseCase) GetFundingRate(provider string, symbol *domain.MarketSymbol) (*domain.FundingRate, error) {
+	futuresAPI, err := f.connManager.FuturesAPI(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.connManager.ValidateSymbol(provider, symbol); err != nil {
+		return nil, err
+	}
+
+	fundingRate, err := futuresAPI.FundingRate(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	fundingRate.NativeSymbol = nativeSymbol(f.connManager, provider, symbol)
+	return fundingRate, nil
+}
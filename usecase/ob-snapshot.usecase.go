@@ -34,37 +34,80 @@ func NewOrderBookSnapshotUseCase(
 // GetOrderBookSnapshot returns the orderbook snapshot from the runtime storage or from provider api.
 func (o *OrderBookSnapshotUseCase) GetOrderBookSnapshot(
 	provider string, symbol *domain.MarketSymbol, limit int,
+) (*domain.OrderBookSnapshot, error) {
+	syncAPI, err := o.connManager.SyncAPI(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.connManager.ValidateSymbol(provider, symbol); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := o.orderBookSnapshot(syncAPI, provider, symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.NativeSymbol = nativeSymbol(o.connManager, provider, symbol)
+	return snapshot, nil
+}
+
+func (o *OrderBookSnapshotUseCase) orderBookSnapshot(
+	syncAPI domain.ProviderSyncAPI, provider string, symbol *domain.MarketSymbol, limit int,
 ) (*domain.OrderBookSnapshot, error) {
 	// If local orderbook in the initialization process, return the snapshot from the provider api.
 	waitingRoomKey := o.getWaitingRoomKey(provider, symbol)
 	if _, ok := o.waitingRoom.Load(waitingRoomKey); ok {
 		logger.Printf("orderbook is initing. provider`s snapshot returns: Provider=%s, Symbol=%s", provider, symbol.String())
-		return o.connManager.SyncAPI(provider).OrderBookSnapshot(symbol, limit)
+		return syncAPI.OrderBookSnapshot(symbol, limit)
 	}
 
 	orderbook, err := o.storage.Get(provider, symbol)
+	if err == nil && orderbook.IsOutdated() {
+		// the maintainer is stopped, the order book is created again
+		logger.Printf("orderbook is outdated. Provider=%s, Symbol=%s", provider, symbol.String())
+		o.storage.Remove(provider, symbol)
+		err = domain.ErrOrderBookNotFound
+	}
 	if err != nil {
-		go o.createOrderBook(provider, symbol)
-		return o.connManager.SyncAPI(provider).OrderBookSnapshot(symbol, limit)
+		// the key is stored before the goroutine is started, so the order book is created once
+		if _, loaded := o.waitingRoom.LoadOrStore(waitingRoomKey, STARTING); !loaded {
+			go o.createOrderBook(provider, symbol)
+		}
+		return syncAPI.OrderBookSnapshot(symbol, limit)
+	}
+
+	// the levels below the max depth are not maintained, the shallower snapshot is not served as the requested one
+	if maxDepth := orderbook.MaxDepth(); maxDepth > 0 && limit > maxDepth {
+		return nil, fmt.Errorf("%w: depth=%d, max depth=%d", domain.ErrDepthExceedsOrderBook, limit, maxDepth)
 	}
 
 	snapshot := orderbook.TakeSnapshot(limit)
 	return snapshot, nil
 }
 
+// createOrderBook initializes the local order book. The key of the waiting room is released even if it fails,
+// so the next request retries.
 func (o *OrderBookSnapshotUseCase) createOrderBook(
 	provider string, symbol *domain.MarketSymbol,
 ) {
 	waitingRoomKey := o.getWaitingRoomKey(provider, symbol)
-	o.waitingRoom.Store(waitingRoomKey, STARTING)
+	defer o.waitingRoom.Delete(waitingRoomKey)
 
-	result := o.connManager.StreamAPI(provider).GetOrderBook(symbol)
+	streamAPI, err := o.connManager.StreamAPI(provider)
+	if err != nil {
+		logger.Printf("failed to create orderbook: %s", err)
+		return
+	}
+
+	result := streamAPI.GetOrderBook(symbol)
 	if result.Err != nil {
+		logger.Printf("failed to create orderbook: %s. Provider=%s, Symbol=%s", result.Err, provider, symbol.String())
 		return
 	}
 
 	o.storage.Add(provider, symbol, result.OrderBook)
-	o.waitingRoom.Delete(waitingRoomKey)
 
 	logger.Printf("orderbook snapshot for %s is added for to the runtime storage. Provider=%s", symbol.String(), provider)
 }
@@ -72,3 +115,13 @@ func (o *OrderBookSnapshotUseCase) createOrderBook(
 func (o *OrderBookSnapshotUseCase) getWaitingRoomKey(provider string, symbol *domain.MarketSymbol) string {
 	return fmt.Sprintf("%s-%s", provider, symbol.String())
 }
+
+// nativeSymbol returns the symbol of the market on the provider, it is empty if the provider has no mapper.
+func nativeSymbol(connManager domain.ConnManager, provider string, symbol *domain.MarketSymbol) string {
+	mapper, err := connManager.SymbolMapper(provider)
+	if err != nil || mapper == nil {
+		return ""
+	}
+
+	return mapper.Native(symbol)
+}